@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
 	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +16,8 @@ var (
 	badgeOutput string
 	badgeLabel  string
 	badgeStyle  string
+	badgeMetric string
+	badgeFormat string
 )
 
 var badgeCmd = &cobra.Command{
@@ -21,16 +26,21 @@ var badgeCmd = &cobra.Command{
 	Long: `Automatically detect coverage files or use specified file, 
 calculate total coverage, and generate an SVG badge similar to Shields.io.`,
 	Example: `  covpeek badge --file coverage.lcov --output mybadge.svg
+  cargo llvm-cov --lcov | covpeek badge --file -
   covpeek badge --label "test coverage" --style plastic`,
 	RunE: runBadge,
 }
 
 func init() {
-	badgeCmd.Flags().StringVar(&badgeFile, "file", "", "Path to the coverage report file (optional, auto-detect if not provided)")
+	badgeCmd.Flags().StringVar(&badgeFile, "file", "", "Path to the coverage report file"+fileFlagStdin+" (optional, auto-detect if not provided)")
+	badgeCmd.Flags().StringVar(&badgeFormat, "format", "", "Override format detection ("+strings.Join(parser.Names(), ", ")+")")
 	badgeCmd.Flags().StringVar(&badgeOutput, "output", "coverage-badge.svg", "Path to save the generated SVG file")
 	badgeCmd.Flags().StringVar(&badgeLabel, "label", "coverage", "Custom text label for the badge")
 	badgeCmd.Flags().StringVar(&badgeStyle, "style", "flat", "Badge style: flat, plastic, flat-square")
 
+	badgeCmd.Flags().StringVar(&badgeMetric, "metric", "", metricFlagUsage)
+
+	registerPathFlags(badgeCmd)
 	rootCmd.AddCommand(badgeCmd)
 }
 
@@ -40,35 +50,39 @@ func runBadge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--style must be one of: flat, plastic, flat-square")
 	}
 
+	if err := validateMetric(badgeMetric); err != nil {
+		return err
+	}
+	if badgeFormat != "" && parser.Lookup(badgeFormat) == nil {
+		return fmt.Errorf("invalid format '%s': must be one of: %s", badgeFormat, strings.Join(parser.Names(), ", "))
+	}
+
 	// Detect or parse coverage file
-	var reports []*models.CoverageReport
+	loader, err := newLoader(covpeek.Loader{Format: badgeFormat, Metric: models.Metric(badgeMetric)})
+	if err != nil {
+		return err
+	}
+	var mergedReport *models.CoverageReport
 	if badgeFile != "" {
-		report, err := parseCoverageFile(badgeFile)
+		result, err := loadCoverage(cmd, loader, badgeFile)
 		if err != nil {
 			return fmt.Errorf("failed to parse coverage file %s: %v", badgeFile, err)
 		}
-		reports = append(reports, report)
+		printWarnings(cmd, result.Warnings)
+		mergedReport = result.Report
 	} else {
 		existingFiles := detectExistingCoverageFiles()
 		if len(existingFiles) == 0 {
 			return fmt.Errorf("no coverage files detected in standard locations. Please specify --file")
 		}
-		for _, file := range existingFiles {
-			report, err := parseCoverageFile(file)
-			if err != nil {
-				cmd.PrintErrf("Warning: failed to parse %s: %v\n", file, err)
-				continue
-			}
-			reports = append(reports, report)
-		}
-		if len(reports) == 0 {
+		result, err := loader.LoadAll(existingFiles)
+		printWarnings(cmd, result.Warnings)
+		if err != nil {
 			return fmt.Errorf("no valid coverage files found")
 		}
+		mergedReport = result.Report
 	}
 
-	// Merge reports
-	mergedReport := mergeReports(reports)
-
 	// Calculate overall coverage
 	_, _, overallPct := mergedReport.CalculateOverallCoverage()
 
@@ -77,7 +91,7 @@ func runBadge(cmd *cobra.Command, args []string) error {
 	svg := generateBadgeSVG(badgeLabel, fmt.Sprintf("%.1f%%", overallPct), color, badgeStyle)
 
 	// Write to file
-	err := os.WriteFile(badgeOutput, []byte(svg), 0644)
+	err = os.WriteFile(badgeOutput, []byte(svg), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write SVG file: %v", err)
 	}
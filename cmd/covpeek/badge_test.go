@@ -2,8 +2,11 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
 )
 
 func TestGetColorForCoverage(t *testing.T) {
@@ -103,3 +106,34 @@ func TestBadgeCommandAutoDetect(t *testing.T) {
 		t.Error("Badge file was not created")
 	}
 }
+
+func TestRunBadgeStdin(t *testing.T) {
+	defer func() {
+		badgeFile = ""
+		badgeFormat = ""
+	}()
+
+	badgeFile = "-"
+	badgeOutput = filepath.Join(t.TempDir(), "badge.svg")
+	badgeLabel = "coverage"
+	badgeStyle = "flat"
+
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader("mode: set\ngithub.com/example/main.go:10.1,12.1 1 1\ngithub.com/example/main.go:15.1,17.1 1 0\n"))
+	if err := runBadge(cmd, []string{}); err != nil {
+		t.Fatalf("runBadge from stdin failed: %v", err)
+	}
+
+	svg, err := os.ReadFile(badgeOutput)
+	if err != nil {
+		t.Fatalf("Badge file was not created: %v", err)
+	}
+	if !strings.Contains(string(svg), "50.0%") {
+		t.Errorf("Expected 50.0%% coverage in badge, got: %s", svg)
+	}
+
+	badgeFormat = "nope"
+	if err := runBadge(cmd, []string{}); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected error for invalid format, got: %v", err)
+	}
+}
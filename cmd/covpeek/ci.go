@@ -1,26 +1,30 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"io"
-	"os"
+	"strings"
 
-	"github.com/Chapati-Systems/covpeek/internal/detector"
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
 	"github.com/Chapati-Systems/covpeek/pkg/models"
 	"github.com/Chapati-Systems/covpeek/pkg/parser"
 	"github.com/spf13/cobra"
 )
 
-var minCoverage float64
+var (
+	minCoverage float64
+	ciMetric    string
+	ciFile      string
+	ciFormat    string
+)
 
 var ciCmd = &cobra.Command{
 	Use:   "ci --min <percentage>",
 	Short: "Check total coverage against minimum threshold for CI",
 	Long: `Automatically detect coverage files in standard locations, 
 calculate total coverage, and fail if below the minimum threshold.`,
-	Example: `  covpeek ci --min 80`,
-	RunE:    runCI,
+	Example: `  covpeek ci --min 80
+  go test -coverprofile=/dev/stdout ./... | covpeek ci --min 80 --file -`,
+	RunE: runCI,
 }
 
 func init() {
@@ -28,35 +32,54 @@ func init() {
 	if err := ciCmd.MarkFlagRequired("min"); err != nil {
 		panic(err)
 	}
+	ciCmd.Flags().StringVar(&ciMetric, "metric", "", metricFlagUsage)
+	ciCmd.Flags().StringVar(&ciFile, "file", "", "Path to the coverage report file"+fileFlagStdin+" (optional, auto-detect if not provided)")
+	ciCmd.Flags().StringVar(&ciFormat, "format", "", "Override format detection ("+strings.Join(parser.Names(), ", ")+")")
+	ciWarnings.register(ciCmd)
+	registerPathFlags(ciCmd)
 }
 
 func runCI(cmd *cobra.Command, args []string) error {
 	if minCoverage < 0 || minCoverage > 100 {
 		return fmt.Errorf("--min must be between 0 and 100, got: %.2f", minCoverage)
 	}
+	if err := validateMetric(ciMetric); err != nil {
+		return err
+	}
+	if err := ciWarnings.validate(); err != nil {
+		return err
+	}
+	if ciFormat != "" && parser.Lookup(ciFormat) == nil {
+		return fmt.Errorf("invalid format '%s': must be one of: %s", ciFormat, strings.Join(parser.Names(), ", "))
+	}
 
-	existingFiles := detectExistingCoverageFiles()
-
-	var reports []*models.CoverageReport
-
-	for _, file := range existingFiles {
-		// File exists, try to parse
-		report, err := parseCoverageFile(file)
+	loader, err := newLoader(covpeek.Loader{Format: ciFormat, Metric: models.Metric(ciMetric)})
+	if err != nil {
+		return err
+	}
+	var mergedReport *models.CoverageReport
+	if ciFile != "" {
+		result, err := loadCoverage(cmd, loader, ciFile)
 		if err != nil {
-			cmd.PrintErrf("Warning: failed to parse %s: %v\n", file, err)
-			continue
+			return fmt.Errorf("failed to parse coverage file %s: %w", ciFile, err)
 		}
-		reports = append(reports, report)
-	}
-
-	if len(reports) == 0 {
-		fmt.Println("No coverage files detected in standard locations. Please specify manually.")
-		return fmt.Errorf("no coverage files found")
+		if err := ciWarnings.check(cmd, result.Warnings); err != nil {
+			return err
+		}
+		mergedReport = result.Report
+	} else {
+		result, err := loader.LoadAll(detectExistingCoverageFiles())
+		warningsErr := ciWarnings.check(cmd, result.Warnings)
+		if err != nil {
+			fmt.Println("No coverage files detected in standard locations. Please specify manually.")
+			return fmt.Errorf("no coverage files found")
+		}
+		if warningsErr != nil {
+			return warningsErr
+		}
+		mergedReport = result.Report
 	}
 
-	// Merge reports
-	mergedReport := mergeReports(reports)
-
 	// Calculate overall coverage
 	_, _, overallPct := mergedReport.CalculateOverallCoverage()
 
@@ -71,91 +94,5 @@ func runCI(cmd *cobra.Command, args []string) error {
 }
 
 func detectExistingCoverageFiles() []string {
-	possibleFiles := getPossibleCoverageFiles()
-	var existingFiles []string
-	for _, file := range possibleFiles {
-		if _, err := os.Stat(file); err == nil {
-			existingFiles = append(existingFiles, file)
-		}
-	}
-	return existingFiles
-}
-
-func getPossibleCoverageFiles() []string {
-	return []string{
-		"coverage.out",                 // Go
-		"test/coverage.out",            // Go
-		"lcov.info",                    // Rust/TS
-		"target/coverage/lcov.info",    // Rust
-		"coverage/lcov.info",           // TS
-		"coverage/coverage-final.json", // TS
-		"coverage.xml",                 // Python
-		"coverage.json",                // Python
-	}
-}
-
-func parseCoverageFile(filePath string) (*models.CoverageReport, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = file.Close() }()
-
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-
-	// Detect format
-	format := detector.DetectFormatByExtension(filePath)
-	if format == detector.UnknownFormat {
-		var err error
-		format, err = detector.DetectFormat(bytes.NewReader(content))
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if format == detector.UnknownFormat {
-		return nil, fmt.Errorf("unable to detect format")
-	}
-
-	// Parse
-	var report *models.CoverageReport
-	switch format {
-	case detector.LCOVFormat:
-		p := parser.NewLCOVParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.GoCoverFormat:
-		p := parser.NewGoCoverParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.PyCoverXMLFormat:
-		p := parser.NewPyCoverXMLParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.PyCoverJSONFormat:
-		p := parser.NewPyCoverJSONParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
-	}
-
-	return report, err
-}
-
-func mergeReports(reports []*models.CoverageReport) *models.CoverageReport {
-	merged := models.NewCoverageReport()
-	for _, report := range reports {
-		for _, file := range report.Files {
-			// If file already exists, combine
-			if existing := merged.GetFile(file.FileName); existing != nil {
-				existing.TotalLines += file.TotalLines
-				existing.CoveredLines += file.CoveredLines
-				existing.CalculateCoverage()
-				// Combine functions and lines if needed, but for simplicity, skip
-			} else {
-				merged.AddFile(file)
-			}
-		}
-	}
-	return merged
+	return covpeek.Discover(".")
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
 	"github.com/Chapati-Systems/covpeek/pkg/models"
 	"github.com/spf13/cobra"
 )
@@ -29,7 +31,7 @@ func TestMergeReports(t *testing.T) {
 	report2.AddFile(file2)
 
 	reports := []*models.CoverageReport{report1, report2}
-	merged := mergeReports(reports)
+	merged := covpeek.Merge(reports...)
 
 	// Check merged
 	if len(merged.Files) != 2 {
@@ -64,30 +66,32 @@ func TestMergeReportsOverlappingFiles(t *testing.T) {
 	report2.AddFile(file2)
 
 	reports := []*models.CoverageReport{report1, report2}
-	merged := mergeReports(reports)
+	merged := covpeek.Merge(reports...)
 
 	// Check merged
 	if len(merged.Files) != 1 {
 		t.Errorf("Expected 1 file, got %d", len(merged.Files))
 	}
 
+	// Without line data the same file is not counted twice, the larger totals are kept
 	fc := merged.GetFile("file.go")
-	if fc.TotalLines != 150 || fc.CoveredLines != 120 {
-		t.Errorf("Expected TotalLines 150, CoveredLines 120, got %d, %d", fc.TotalLines, fc.CoveredLines)
+	if fc.TotalLines != 100 || fc.CoveredLines != 80 {
+		t.Errorf("Expected TotalLines 100, CoveredLines 80, got %d, %d", fc.TotalLines, fc.CoveredLines)
 	}
 
-	expectedPct := 120.0 / 150.0 * 100.0
+	expectedPct := 80.0 / 100.0 * 100.0
 	if fc.CoveragePct != expectedPct {
 		t.Errorf("Expected %.2f%%, got %.2f%%", expectedPct, fc.CoveragePct)
 	}
 }
 
-func TestParseCoverageFile(t *testing.T) {
+func TestLoadCoverageFile(t *testing.T) {
 	// Test with existing testdata
-	report, err := parseCoverageFile("../../testdata/sample.lcov")
+	result, err := covpeek.Load("../../testdata/sample.lcov")
 	if err != nil {
-		t.Errorf("Failed to parse sample.lcov: %v", err)
+		t.Fatalf("Failed to parse sample.lcov: %v", err)
 	}
+	report := result.Report
 
 	// Check has files
 	if len(report.Files) == 0 {
@@ -95,8 +99,8 @@ func TestParseCoverageFile(t *testing.T) {
 	}
 }
 
-func TestParseCoverageFileNonExistent(t *testing.T) {
-	_, err := parseCoverageFile("nonexistent")
+func TestLoadCoverageFileNonExistent(t *testing.T) {
+	_, err := covpeek.Load("nonexistent")
 	if err == nil {
 		t.Error("Expected error for non-existent file")
 	}
@@ -104,7 +108,7 @@ func TestParseCoverageFileNonExistent(t *testing.T) {
 
 func TestMergeReportsEmpty(t *testing.T) {
 	reports := []*models.CoverageReport{}
-	merged := mergeReports(reports)
+	merged := covpeek.Merge(reports...)
 
 	if len(merged.Files) != 0 {
 		t.Error("Expected 0 files")
@@ -122,7 +126,7 @@ func TestMergeReportsSingle(t *testing.T) {
 	report.AddFile(file)
 
 	reports := []*models.CoverageReport{report}
-	merged := mergeReports(reports)
+	merged := covpeek.Merge(reports...)
 
 	if len(merged.Files) != 1 {
 		t.Errorf("Expected 1 file, got %d", len(merged.Files))
@@ -134,8 +138,8 @@ func TestMergeReportsSingle(t *testing.T) {
 	}
 }
 
-func TestGetPossibleCoverageFiles(t *testing.T) {
-	files := getPossibleCoverageFiles()
+func TestStandardLocations(t *testing.T) {
+	files := covpeek.StandardLocations()
 	expected := []string{
 		"coverage.out",
 		"test/coverage.out",
@@ -145,6 +149,9 @@ func TestGetPossibleCoverageFiles(t *testing.T) {
 		"coverage/coverage-final.json",
 		"coverage.xml",
 		"coverage.json",
+		"target/site/jacoco/jacoco.xml",
+		"build/reports/jacoco/test/jacocoTestReport.xml",
+		"coverage/.resultset.json",
 	}
 
 	if len(files) != len(expected) {
@@ -214,29 +221,31 @@ github.com/example/main.go:15.1,17.1 1 0
 	}
 }
 
-func TestParseCoverageFileGo(t *testing.T) {
-	report, err := parseCoverageFile("../../testdata/sample.out")
+func TestLoadCoverageFileGo(t *testing.T) {
+	result, err := covpeek.Load("../../testdata/sample.out")
 	if err != nil {
-		t.Errorf("Failed to parse sample.out: %v", err)
+		t.Fatalf("Failed to parse sample.out: %v", err)
 	}
+	report := result.Report
 
 	if len(report.Files) == 0 {
 		t.Error("No files in report")
 	}
 }
 
-func TestParseCoverageFilePyJSON(t *testing.T) {
-	report, err := parseCoverageFile("../../testdata/coverage.json")
+func TestLoadCoverageFilePyJSON(t *testing.T) {
+	result, err := covpeek.Load("../../testdata/coverage.json")
 	if err != nil {
-		t.Errorf("Failed to parse coverage.json: %v", err)
+		t.Fatalf("Failed to parse coverage.json: %v", err)
 	}
+	report := result.Report
 
 	if len(report.Files) == 0 {
 		t.Error("No files in report")
 	}
 }
 
-func TestParseCoverageFileEmpty(t *testing.T) {
+func TestLoadCoverageFileEmpty(t *testing.T) {
 	// Create a temp empty file
 	tmpFile := "empty.lcov"
 	err := os.WriteFile(tmpFile, []byte(""), 0644)
@@ -245,12 +254,64 @@ func TestParseCoverageFileEmpty(t *testing.T) {
 	}
 	defer func() { _ = os.Remove(tmpFile) }()
 
-	report, err := parseCoverageFile(tmpFile)
+	result, err := covpeek.Load(tmpFile)
 	if err != nil {
-		t.Errorf("Failed to parse empty file: %v", err)
+		t.Fatalf("Failed to parse empty file: %v", err)
 	}
+	report := result.Report
 
 	if len(report.Files) != 0 {
 		t.Error("Expected no files")
 	}
 }
+
+func TestLoadCoverageFileIstanbulJSON(t *testing.T) {
+	result, err := covpeek.Load("../../testdata/coverage-final.json")
+	if err != nil {
+		t.Fatalf("Failed to parse coverage-final.json: %v", err)
+	}
+	report := result.Report
+
+	if len(report.Files) != 2 {
+		t.Errorf("Expected 2 files in report, got %d", len(report.Files))
+	}
+}
+
+func TestLoadCoverageFileJaCoCo(t *testing.T) {
+	result, err := covpeek.Load("../../testdata/jacoco.xml")
+	if err != nil {
+		t.Fatalf("Failed to parse jacoco.xml: %v", err)
+	}
+	report := result.Report
+
+	if len(report.Files) != 2 {
+		t.Errorf("Expected 2 files in report, got %d", len(report.Files))
+	}
+}
+
+func TestRunCIStdin(t *testing.T) {
+	defer func() {
+		ciFile = ""
+		ciFormat = ""
+	}()
+
+	ciFile = "-"
+	minCoverage = 50.0
+
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader("mode: set\ngithub.com/example/main.go:10.1,12.1 1 1\ngithub.com/example/main.go:15.1,17.1 1 0\n"))
+	if err := runCI(cmd, []string{}); err != nil {
+		t.Errorf("Expected no error for passing coverage, got %v", err)
+	}
+
+	// Ambiguous content is only loaded with --format
+	cmd.SetIn(strings.NewReader("<report>\nSF:foo\nDA:1,1\nLF:1\nLH:1\nend_of_record\n</report>\n"))
+	if err := runCI(cmd, []string{}); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous format error, got %v", err)
+	}
+	ciFormat = "lcov"
+	cmd.SetIn(strings.NewReader("<report>\nSF:foo\nDA:1,1\nLF:1\nLH:1\nend_of_record\n</report>\n"))
+	if err := runCI(cmd, []string{}); err != nil {
+		t.Errorf("Expected forced format to pass, got %v", err)
+	}
+}
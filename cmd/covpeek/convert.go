@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertFile   string
+	convertFrom   string
+	convertTo     string
+	convertOutput string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert --to <format> [flags]",
+	Short: "Convert a coverage report to another format",
+	Long: `Convert a coverage report of any supported format to LCOV, Cobertura XML,
+a Go cover profile or coverage.py JSON. Data the target format cannot hold,
+such as branches in a Go profile, is dropped.`,
+	Example: `  covpeek convert --file coverage.out --to lcov --output coverage.lcov
+  covpeek convert --file coverage.json --from pyjson --to cobertura > coverage.xml
+  go test -coverprofile=/dev/stdout ./... | covpeek convert --file - --to lcov`,
+	RunE: runConvert,
+}
+
+func init() {
+	convertCmd.Flags().StringVarP(&convertFile, "file", "f", "", "Path to the coverage file to convert"+fileFlagStdin+" (auto-detect if not provided)")
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "Input format, detected if not provided ("+strings.Join(parser.Names(), ", ")+")")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Output format ("+strings.Join(parser.WriterNames(), ", ")+")")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "Path to write the converted report to (default stdout)")
+	if err := convertCmd.MarkFlagRequired("to"); err != nil {
+		panic(err)
+	}
+
+	registerPathFlags(convertCmd)
+	rootCmd.AddCommand(convertCmd)
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	writer := parser.LookupWriter(convertTo)
+	if writer == nil {
+		return fmt.Errorf("invalid output format '%s': must be one of: %s", convertTo, strings.Join(parser.WriterNames(), ", "))
+	}
+	if convertFrom != "" && parser.Lookup(convertFrom) == nil {
+		return fmt.Errorf("invalid format '%s': must be one of: %s", convertFrom, strings.Join(parser.Names(), ", "))
+	}
+
+	// If no file specified, auto-detect
+	if convertFile == "" {
+		existingFiles := detectExistingCoverageFiles()
+		if len(existingFiles) == 0 {
+			return fmt.Errorf("no coverage files detected in standard locations. Please specify --file")
+		}
+		if len(existingFiles) > 1 {
+			return fmt.Errorf("multiple coverage files detected: %v. Please specify --file", existingFiles)
+		}
+		convertFile = existingFiles[0]
+		cmd.PrintErrf("Auto-detected coverage file: %s\n", convertFile)
+	}
+
+	loader, err := newLoader(covpeek.Loader{Format: convertFrom})
+	if err != nil {
+		return err
+	}
+	result, err := loadCoverage(cmd, loader, convertFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage file %s: %w", convertFile, err)
+	}
+	printWarnings(cmd, result.Warnings)
+
+	var out io.Writer = cmd.OutOrStdout()
+	if convertOutput != "" {
+		file, err := os.Create(convertOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+
+	if err := writer.Write(out, result.Report); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", writer.Name(), err)
+	}
+
+	if convertOutput != "" {
+		cmd.PrintErrf("Converted %s (%s) to %s (%s)\n", convertFile, result.Format, convertOutput, writer.Name())
+	}
+	return nil
+}
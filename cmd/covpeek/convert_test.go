@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+func resetConvertFlags() {
+	convertFile = ""
+	convertFrom = ""
+	convertTo = ""
+	convertOutput = ""
+}
+
+func TestRunConvert(t *testing.T) {
+	defer resetConvertFlags()
+
+	output := filepath.Join(t.TempDir(), "coverage.lcov")
+	convertFile = "../../testdata/sample.out"
+	convertTo = "lcov"
+	convertOutput = output
+
+	cmd := &cobra.Command{}
+	cmd.SetErr(&strings.Builder{})
+	if err := runConvert(cmd, []string{}); err != nil {
+		t.Fatalf("runConvert failed: %v", err)
+	}
+
+	originalResult, err := covpeek.Load("../../testdata/sample.out")
+	if err != nil {
+		t.Fatalf("Failed to parse original: %v", err)
+	}
+	original := originalResult.Report
+	convertedResult, err := covpeek.Load(output)
+	if err != nil {
+		t.Fatalf("Failed to parse converted report: %v", err)
+	}
+	converted := convertedResult.Report
+
+	// LCOV has no statements, compare the line counts of the Go profile
+	original.SetMetric(models.MetricLine)
+	totalA, coveredA, _ := original.CalculateOverallCoverage()
+	totalB, coveredB, _ := converted.CalculateOverallCoverage()
+	if totalA != totalB || coveredA != coveredB {
+		t.Errorf("Expected %d/%d lines after conversion, got %d/%d", coveredA, totalA, coveredB, totalB)
+	}
+}
+
+func TestRunConvertStdout(t *testing.T) {
+	defer resetConvertFlags()
+
+	convertFile = "../../testdata/sample.lcov"
+	convertFrom = "rust"
+	convertTo = "pyxml"
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runConvert(cmd, []string{}); err != nil {
+		t.Fatalf("runConvert failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), `<class name="lib.rs" filename="src/lib.rs"`) {
+		t.Errorf("Expected Cobertura XML on stdout, got: %s", out.String())
+	}
+}
+
+func TestRunConvertInvalidFormats(t *testing.T) {
+	defer resetConvertFlags()
+
+	convertFile = "../../testdata/sample.lcov"
+	convertTo = "jacoco"
+	err := runConvert(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid output format") {
+		t.Errorf("Expected error for unwritable format, got: %v", err)
+	}
+
+	convertTo = "lcov"
+	convertFrom = "java"
+	err = runConvert(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected error for unknown input format, got: %v", err)
+	}
+}
+
+func TestRunConvertParseError(t *testing.T) {
+	defer resetConvertFlags()
+
+	input := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(input, []byte("hello world\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	convertFile = input
+	convertTo = "lcov"
+
+	err := runConvert(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "unable to detect coverage format") {
+		t.Errorf("Expected detection error, got: %v", err)
+	}
+}
+
+func TestRunConvertStdin(t *testing.T) {
+	defer resetConvertFlags()
+
+	profile, err := os.ReadFile("../../testdata/sample.out")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	convertFile = "-"
+	convertTo = "lcov"
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(string(profile)))
+	cmd.SetOut(&out)
+	if err := runConvert(cmd, []string{}); err != nil {
+		t.Fatalf("runConvert from stdin failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "SF:git.kernel.fun/myproject/pkg/calculator/calculator.go") {
+		t.Errorf("Expected LCOV on stdout, got: %s", out.String())
+	}
+}
@@ -6,9 +6,8 @@ import (
 	"fmt"
 	"os/exec"
 
-	"github.com/Chapati-Systems/covpeek/internal/detector"
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
 	"github.com/Chapati-Systems/covpeek/pkg/models"
-	"github.com/Chapati-Systems/covpeek/pkg/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +16,7 @@ var (
 	commitA          string
 	commitB          string
 	diffOutputFormat string
+	diffMetric       string
 )
 
 var diffCmd = &cobra.Command{
@@ -32,6 +32,9 @@ func init() {
 	diffCmd.Flags().StringVar(&commitA, "commit-a", "HEAD~1", "Git commit hash or ref for the base coverage report")
 	diffCmd.Flags().StringVar(&commitB, "commit-b", "HEAD", "Git commit hash or ref for the target coverage report")
 	diffCmd.Flags().StringVar(&diffOutputFormat, "output", "detailed", "Output format: summary, detailed, json")
+	diffCmd.Flags().StringVar(&diffMetric, "metric", "", metricFlagUsage)
+	diffWarnings.register(diffCmd)
+	registerPathFlags(diffCmd)
 	rootCmd.AddCommand(diffCmd)
 }
 
@@ -40,6 +43,12 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	if diffOutputFormat != "summary" && diffOutputFormat != "detailed" && diffOutputFormat != "json" {
 		return fmt.Errorf("invalid output format: %s. Must be summary, detailed, or json", diffOutputFormat)
 	}
+	if err := validateMetric(diffMetric); err != nil {
+		return err
+	}
+	if err := diffWarnings.validate(); err != nil {
+		return err
+	}
 
 	// If no file specified, auto-detect
 	if diffFile == "" {
@@ -67,18 +76,23 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse reports
-	reportA, err := parseCoverageContent(contentA, diffFile)
+	resultA, err := loadCoverageContent(contentA, diffFile, commitA)
 	if err != nil {
 		return fmt.Errorf("failed to parse coverage from commit %s: %v", commitA, err)
 	}
 
-	reportB, err := parseCoverageContent(contentB, diffFile)
+	resultB, err := loadCoverageContent(contentB, diffFile, commitB)
 	if err != nil {
 		return fmt.Errorf("failed to parse coverage from commit %s: %v", commitB, err)
 	}
 
+	warnings := append(resultA.Warnings, resultB.Warnings...)
+	if err := diffWarnings.check(cmd, warnings); err != nil {
+		return err
+	}
+
 	// Compute diff
-	diff := computeDiff(reportA, reportB)
+	diff := computeDiff(resultA.Report, resultB.Report)
 
 	// Output
 	outputDiff(diff, diffOutputFormat)
@@ -117,42 +131,21 @@ func getFileFromCommit(commit, file string) ([]byte, error) {
 	return cmd.Output()
 }
 
-func parseCoverageContent(content []byte, filePath string) (*models.CoverageReport, error) {
-	// Detect format
-	format := detector.DetectFormatByExtension(filePath)
-	if format == detector.UnknownFormat {
-		var err error
-		format, err = detector.DetectFormat(bytes.NewReader(content))
-		if err != nil {
-			return nil, err
-		}
+// loadCoverageContent parses a coverage file read from a commit. Warnings
+// name the file the way git show does, e.g. HEAD~1:coverage.out.
+func loadCoverageContent(content []byte, filePath, commit string) (*covpeek.Result, error) {
+	loader, err := newLoader(covpeek.Loader{Metric: models.Metric(diffMetric)})
+	if err != nil {
+		return nil, err
 	}
-
-	if format == detector.UnknownFormat {
-		return nil, fmt.Errorf("unable to detect format")
+	result, err := loader.LoadReader(bytes.NewReader(content), filePath)
+	if err != nil {
+		return nil, err
 	}
-
-	// Parse
-	var report *models.CoverageReport
-	var err error
-	switch format {
-	case detector.LCOVFormat:
-		p := parser.NewLCOVParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.GoCoverFormat:
-		p := parser.NewGoCoverParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.PyCoverXMLFormat:
-		p := parser.NewPyCoverXMLParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	case detector.PyCoverJSONFormat:
-		p := parser.NewPyCoverJSONParser()
-		report, err = p.Parse(bytes.NewReader(content))
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
-	}
-
-	return report, err
+	for i := range result.Warnings {
+		result.Warnings[i].Path = fmt.Sprintf("%s:%s", commit, filePath)
+	}
+	return result, nil
 }
 
 // CoverageDiff represents the diff between two coverage reports
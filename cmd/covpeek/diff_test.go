@@ -207,7 +207,7 @@ func TestRunDiffIntegrationMissingFile(t *testing.T) {
 	}
 }
 
-func TestParseCoverageContentLCOV(t *testing.T) {
+func TestLoadCoverageContentLCOV(t *testing.T) {
 	content := `TN:test
 SF:file.go
 DA:1,1
@@ -216,10 +216,11 @@ LH:1
 LF:2
 end_of_record
 `
-	report, err := parseCoverageContent([]byte(content), "test.lcov")
+	result, err := loadCoverageContent([]byte(content), "test.lcov", "HEAD")
 	if err != nil {
 		t.Fatalf("Failed to parse LCOV: %v", err)
 	}
+	report := result.Report
 	if report == nil {
 		t.Fatal("Report is nil")
 	}
@@ -235,38 +236,40 @@ end_of_record
 	}
 }
 
-func TestParseCoverageContentGo(t *testing.T) {
+func TestLoadCoverageContentGo(t *testing.T) {
 	content := `mode: set
 file.go:1.10,2.20 1 1
 file.go:3.30,4.40 0 1
 `
-	report, err := parseCoverageContent([]byte(content), "coverage.out")
+	result, err := loadCoverageContent([]byte(content), "coverage.out", "HEAD")
 	if err != nil {
 		t.Fatalf("Failed to parse Go: %v", err)
 	}
+	report := result.Report
 	if report == nil {
 		t.Fatal("Report is nil")
 	}
 	// Just check that parsing succeeded
 }
 
-func TestParseCoverageContentUnknownFormat(t *testing.T) {
+func TestLoadCoverageContentUnknownFormat(t *testing.T) {
 	content := `invalid content`
-	_, err := parseCoverageContent([]byte(content), "unknown.txt")
+	_, err := loadCoverageContent([]byte(content), "unknown.txt", "HEAD")
 	if err == nil {
 		t.Error("Expected error for unknown format")
 	}
 }
 
-func TestParseCoverageContentPyJSON(t *testing.T) {
+func TestLoadCoverageContentPyJSON(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/coverage.json")
 	if err != nil {
 		t.Fatalf("Failed to read testdata: %v", err)
 	}
-	report, err := parseCoverageContent(content, "coverage.json")
+	result, err := loadCoverageContent(content, "coverage.json", "HEAD")
 	if err != nil {
 		t.Fatalf("Failed to parse coverage.json: %v", err)
 	}
+	report := result.Report
 	if report == nil {
 		t.Fatal("Report is nil")
 	}
@@ -275,15 +278,16 @@ func TestParseCoverageContentPyJSON(t *testing.T) {
 	}
 }
 
-func TestParseCoverageContentPyXML(t *testing.T) {
+func TestLoadCoverageContentPyXML(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/coverage.xml")
 	if err != nil {
 		t.Fatalf("Failed to read testdata: %v", err)
 	}
-	report, err := parseCoverageContent(content, "coverage.xml")
+	result, err := loadCoverageContent(content, "coverage.xml", "HEAD")
 	if err != nil {
 		t.Fatalf("Failed to parse coverage.xml: %v", err)
 	}
+	report := result.Report
 	if report == nil {
 		t.Fatal("Report is nil")
 	}
@@ -326,9 +330,9 @@ func TestOutputDiffJSON(t *testing.T) {
 	outputDiff(diff, "json")
 }
 
-func TestParseCoverageContentMalformed(t *testing.T) {
+func TestLoadCoverageContentMalformed(t *testing.T) {
 	content := `invalid content`
-	_, err := parseCoverageContent([]byte(content), "unknown.txt")
+	_, err := loadCoverageContent([]byte(content), "unknown.txt", "HEAD")
 	if err == nil {
 		t.Error("Expected error for malformed content")
 	}
@@ -412,3 +416,18 @@ func TestRunDiffAutoDetectMultipleFiles(t *testing.T) {
 		t.Errorf("Expected 'multiple coverage files detected' error, got: %v", err)
 	}
 }
+
+func TestLoadCoverageContentIstanbulJSON(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/coverage-final.json")
+	if err != nil {
+		t.Fatalf("Failed to read testdata: %v", err)
+	}
+	result, err := loadCoverageContent(content, "coverage/coverage-final.json", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to parse coverage-final.json: %v", err)
+	}
+	report := result.Report
+	if len(report.Files) != 2 {
+		t.Errorf("Expected 2 files in report, got %d", len(report.Files))
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// groupByFlagUsage is the help text of the --group-by flag
+const groupByFlagUsage = "Roll files up by dir, package or depth=N (the first N directories)"
+
+// groupByDepthPrefix starts a --group-by value keeping the first N directories
+const groupByDepthPrefix = "depth="
+
+// parseGroupBy parses a --group-by value, empty lists files individually
+func parseGroupBy(value string) (models.GroupBy, int, error) {
+	switch value {
+	case "":
+		return "", 0, nil
+	case string(models.GroupByDir), string(models.GroupByPackage):
+		return models.GroupBy(value), 0, nil
+	}
+	if depth, ok := strings.CutPrefix(value, groupByDepthPrefix); ok {
+		n, err := strconv.Atoi(depth)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("invalid --group-by depth '%s': must be a positive number", depth)
+		}
+		return models.GroupByDepth, n, nil
+	}
+	return "", 0, fmt.Errorf("invalid --group-by '%s': must be one of: dir, package, depth=N", value)
+}
+
+// groupTitle names the groups in column headers
+func groupTitle(by models.GroupBy) string {
+	if by == models.GroupByPackage {
+		return "Package"
+	}
+	return "Directory"
+}
+
+// filterGroupsBelowThreshold keeps the groups with coverage below the
+// threshold, and below them the subgroups and files below it
+func filterGroupsBelowThreshold(groups []*models.CoverageNode, threshold float64) []*models.CoverageNode {
+	filtered := make([]*models.CoverageNode, 0, len(groups))
+	for _, group := range groups {
+		if group.CoveragePct >= threshold {
+			continue
+		}
+		if len(group.Children) > 0 {
+			// Copy the group, the tree it belongs to stays complete
+			copied := *group
+			copied.Children = filterGroupsBelowThreshold(group.Children, threshold)
+			group = &copied
+		}
+		filtered = append(filtered, group)
+	}
+	return filtered
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// createGroupTestReport returns a report of two packages, one of them with a
+// file in a subdirectory
+func createGroupTestReport() *models.CoverageReport {
+	report := models.NewCoverageReport()
+	files := []struct {
+		name           string
+		total, covered int
+	}{
+		{"pkg/parser/lcov.go", 10, 9},
+		{"pkg/parser/xml/jacoco.go", 10, 1},
+		{"pkg/models/model.go", 4, 4},
+	}
+	for _, f := range files {
+		fc := &models.FileCoverage{FileName: f.name, TotalLines: f.total, CoveredLines: f.covered}
+		fc.CalculateCoverage()
+		report.AddFile(fc)
+	}
+	report.Files["pkg/models/model.go"].Functions = []models.FunctionCoverage{{Name: "New", ExecutionCount: 1}}
+	return report
+}
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := fn()
+	_ = w.Close()
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("Output failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		value string
+		by    models.GroupBy
+		depth int
+		valid bool
+	}{
+		{"", "", 0, true},
+		{"dir", models.GroupByDir, 0, true},
+		{"package", models.GroupByPackage, 0, true},
+		{"depth=2", models.GroupByDepth, 2, true},
+		{"depth=0", "", 0, false},
+		{"depth=x", "", 0, false},
+		{"module", "", 0, false},
+	}
+	for _, tt := range tests {
+		by, depth, err := parseGroupBy(tt.value)
+		if (err == nil) != tt.valid || by != tt.by || depth != tt.depth {
+			t.Errorf("parseGroupBy(%q) = %q, %d, %v", tt.value, by, depth, err)
+		}
+	}
+}
+
+func TestOutputGroupTable(t *testing.T) {
+	report := createGroupTestReport()
+	groups := report.Group(models.GroupByDepth, 2)
+
+	output := captureStdout(t, func() error {
+		return outputGroupTable(report, groups, models.GroupByDepth)
+	})
+
+	for _, expected := range []string{"Directory", "Files", "Total Functions", "pkg/parser", "pkg/models", "Overall"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Table should contain %q, got:\n%s", expected, output)
+		}
+	}
+	// The subdirectory is rolled up into pkg/parser
+	if strings.Contains(output, "xml") {
+		t.Errorf("Table should not list pkg/parser/xml, got:\n%s", output)
+	}
+	// Groups are sorted by coverage, highest first
+	if strings.Index(output, "pkg/models") > strings.Index(output, "pkg/parser") {
+		t.Errorf("Expected pkg/models before pkg/parser, got:\n%s", output)
+	}
+}
+
+func TestOutputGroupCSV(t *testing.T) {
+	report := createGroupTestReport()
+	groups := report.Group(models.GroupByDir, 0)
+
+	output := captureStdout(t, func() error {
+		return outputGroupCSV(report, groups, models.GroupByDir)
+	})
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV output is not valid: %v", err)
+	}
+	if len(records) != 4 || records[0][0] != "Directory" || records[0][1] != "Files" {
+		t.Fatalf("Expected a header and 3 directories, got %v", records)
+	}
+	if records[2][0] != "pkg/parser" || records[2][2] != "90.00" || records[2][4] != "10" {
+		t.Errorf("Expected pkg/parser at 90%%, got %v", records[2])
+	}
+}
+
+func TestOutputGroupJSON(t *testing.T) {
+	report := createGroupTestReport()
+	groups := report.Group(models.GroupByPackage, 0)
+
+	output := captureStdout(t, func() error {
+		return outputGroupJSON(report, groups, models.GroupByPackage)
+	})
+
+	var result struct {
+		GroupBy string
+		Groups  []map[string]interface{}
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("JSON output is not valid: %v", err)
+	}
+	if result.GroupBy != "package" || len(result.Groups) != 3 {
+		t.Fatalf("Expected 3 packages, got %s", output)
+	}
+	if _, ok := result.Groups[0]["Children"]; ok {
+		t.Errorf("Expected groups without their files, got %v", result.Groups[0])
+	}
+	if result.Groups[0]["Name"] != "pkg/models" || result.Groups[0]["CoveredFunctions"] != 1.0 {
+		t.Errorf("Expected pkg/models with 1 covered function, got %v", result.Groups[0])
+	}
+}
+
+func TestFilterGroupsBelowThreshold(t *testing.T) {
+	report := createGroupTestReport()
+
+	groups := filterGroupsBelowThreshold(report.Tree().Children, 80)
+	if len(groups) != 1 || groups[0].Name != "pkg" {
+		t.Fatalf("Expected pkg below 80%%, got %+v", groups)
+	}
+	// pkg/models is fully covered, pkg/parser is not
+	pkg := groups[0]
+	if len(pkg.Children) != 1 || pkg.Children[0].Name != "parser" {
+		t.Errorf("Expected only parser below pkg, got %+v", pkg.Children)
+	}
+	if len(report.Tree().Children[0].Children) != 2 {
+		t.Error("Expected the tree to be left unchanged")
+	}
+}
+
+func TestTreeModel(t *testing.T) {
+	report := createGroupTestReport()
+	model := newTreeModel(report, report.Tree().Children)
+
+	// The single top level directory starts expanded
+	rows := model.table.Rows()
+	if len(rows) != 3 || rows[0][0] != treeExpanded+"pkg" {
+		t.Fatalf("Expected pkg and its two directories, got %v", rows)
+	}
+	// Sorted by coverage, highest first
+	if rows[1][0] != "  "+treeCollapsed+"models" || rows[2][0] != "  "+treeCollapsed+"parser" {
+		t.Errorf("Expected models before parser, got %v", rows)
+	}
+
+	// Expand parser
+	model.table.SetCursor(2)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	model = updated.(tableModel)
+	rows = model.table.Rows()
+	if len(rows) != 5 || rows[3][0] != "    "+treeLeaf+"lcov.go" || rows[4][0] != "    "+treeCollapsed+"xml" {
+		t.Fatalf("Expected the files and directories of parser, got %v", rows)
+	}
+
+	// Left on a file moves to its directory, left again collapses it
+	model.table.SetCursor(3)
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	model = updated.(tableModel)
+	if model.table.Cursor() != 2 {
+		t.Errorf("Expected the cursor on parser, got row %d", model.table.Cursor())
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	model = updated.(tableModel)
+	if len(model.table.Rows()) != 3 {
+		t.Errorf("Expected parser to be collapsed, got %v", model.table.Rows())
+	}
+
+	// Space toggles, sorting keeps the tree
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	model = updated.(tableModel)
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	model = updated.(tableModel)
+	rows = model.table.Rows()
+	if len(rows) != 5 || rows[1][0] != "  "+treeExpanded+"parser" {
+		t.Errorf("Expected parser first by name descending and expanded, got %v", rows)
+	}
+	if !strings.Contains(model.View(), "collapse/expand") {
+		t.Error("Expected the tree keys in the help line")
+	}
+}
+
+func TestRunParseGroupBy(t *testing.T) {
+	defer func() {
+		coverageFile = ""
+		outputFormat = "table"
+		groupBy = ""
+		belowPct = 0
+	}()
+	coverageFile = "../../testdata/sample.out"
+	outputFormat = "csv"
+	groupBy = "package"
+	belowPct = 100
+
+	cmd := &cobra.Command{}
+	cmd.SetErr(&strings.Builder{})
+	output := captureStdout(t, func() error {
+		return runParse(cmd, []string{})
+	})
+
+	// Go files are grouped by import path, fully covered packages are left out
+	if !strings.Contains(output, "git.kernel.fun/myproject/pkg/calculator,1,") {
+		t.Errorf("Expected the calculator package, got:\n%s", output)
+	}
+	if strings.Contains(output, "git.kernel.fun/myproject,") {
+		t.Errorf("Expected the fully covered main package to be filtered, got:\n%s", output)
+	}
+
+	groupBy = "files"
+	if err := runParse(cmd, []string{}); err == nil || !strings.Contains(err.Error(), "--group-by") {
+		t.Errorf("Expected an error for an invalid --group-by, got %v", err)
+	}
+}
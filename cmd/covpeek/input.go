@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+// stdinPath is the --file value that reads coverage from standard input
+const stdinPath = "-"
+
+// fileFlagStdin is appended to the help text of the --file flags accepting stdinPath
+const fileFlagStdin = ", - for stdin"
+
+// loadCoverage loads the coverage file given with --file. Coverage piped to
+// standard input has no name, its format is detected by content unless the
+// loader forces one.
+func loadCoverage(cmd *cobra.Command, loader *covpeek.Loader, path string) (*covpeek.Result, error) {
+	if path == stdinPath {
+		return loader.LoadReader(cmd.InOrStdin(), "")
+	}
+	return loader.Load(path)
+}
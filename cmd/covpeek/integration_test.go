@@ -233,3 +233,19 @@ func TestBadgeGeneration(t *testing.T) {
 		t.Error("SVG does not contain label")
 	}
 }
+
+// TestRunParseWithGoCoverDir tests parsing a GOCOVERDIR passed to --file
+func TestRunParseWithGoCoverDir(t *testing.T) {
+	// Reset flags
+	coverageFile = ""
+	forceFormat = ""
+	belowPct = 0
+	outputFormat = "table"
+
+	rootCmd.SetArgs([]string{"--file", "../../testdata/gocoverdir"})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
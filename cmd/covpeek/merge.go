@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var mergeOutput string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [files...] [flags]",
+	Short: "Merge several coverage reports into one LCOV report",
+	Long: `Merge coverage reports line by line, for example from unit and integration
+test runs. Hit counts of lines, functions and branches found in several reports
+are added up, so no line is counted twice. The reports may use different formats.
+Without arguments, the coverage files in standard locations are merged.`,
+	Example: `  covpeek merge unit.lcov integration.lcov --output merged.lcov
+  covpeek merge coverage.out coverage/lcov.info > merged.lcov`,
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Path to write the merged LCOV report to (default stdout)")
+	registerPathFlags(mergeCmd)
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	loader, err := newLoader(covpeek.Loader{})
+	if err != nil {
+		return err
+	}
+
+	var merged *models.CoverageReport
+	if len(args) > 0 {
+		// Explicitly named files must all load
+		reports := make([]*models.CoverageReport, 0, len(args))
+		for _, file := range args {
+			result, err := loader.Load(file)
+			if err != nil {
+				return fmt.Errorf("failed to parse coverage file %s: %v", file, err)
+			}
+			printWarnings(cmd, result.Warnings)
+			reports = append(reports, result.Report)
+		}
+		merged = covpeek.Merge(reports...)
+	} else {
+		existingFiles := detectExistingCoverageFiles()
+		if len(existingFiles) == 0 {
+			return fmt.Errorf("no coverage files detected in standard locations. Please specify the files to merge")
+		}
+		result, err := loader.LoadAll(existingFiles)
+		printWarnings(cmd, result.Warnings)
+		if err != nil {
+			return fmt.Errorf("no valid coverage files found")
+		}
+		merged = result.Report
+		args = result.Paths
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if mergeOutput != "" {
+		file, err := os.Create(mergeOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+
+	if err := parser.NewLCOVWriter().Write(out, merged); err != nil {
+		return fmt.Errorf("failed to write merged report: %w", err)
+	}
+
+	if mergeOutput != "" {
+		_, _, overallPct := merged.CalculateOverallCoverage()
+		cmd.PrintErrf("Merged %d reports into %s (%.2f%% coverage)\n", len(args), mergeOutput, overallPct)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+func TestRunMerge(t *testing.T) {
+	dir := t.TempDir()
+	unit := filepath.Join(dir, "unit.lcov")
+	integration := filepath.Join(dir, "integration.lcov")
+	output := filepath.Join(dir, "merged.lcov")
+
+	unitContent := `SF:src/lib.rs
+DA:1,1
+DA:2,0
+DA:3,0
+LF:3
+LH:1
+end_of_record
+`
+	integrationContent := `SF:src/lib.rs
+DA:1,2
+DA:2,1
+DA:3,0
+LF:3
+LH:2
+end_of_record
+`
+	if err := os.WriteFile(unit, []byte(unitContent), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(integration, []byte(integrationContent), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	mergeOutput = output
+	defer func() { mergeOutput = "" }()
+
+	cmd := &cobra.Command{}
+	cmd.SetErr(&strings.Builder{})
+	if err := runMerge(cmd, []string{unit, integration}); err != nil {
+		t.Fatalf("runMerge failed: %v", err)
+	}
+
+	result, err := covpeek.Load(output)
+	if err != nil {
+		t.Fatalf("Failed to parse merged report: %v", err)
+	}
+	report := result.Report
+
+	// Lines covered in both runs are counted once
+	file := report.GetFile("src/lib.rs")
+	if file == nil {
+		t.Fatal("Expected src/lib.rs in merged report")
+	}
+	if file.TotalLines != 3 || file.CoveredLines != 2 {
+		t.Errorf("Expected 3 total and 2 covered lines, got %d and %d", file.TotalLines, file.CoveredLines)
+	}
+	if file.Lines[1].ExecutionCount != 3 {
+		t.Errorf("Expected hit counts to be added, got %d", file.Lines[1].ExecutionCount)
+	}
+}
+
+func TestRunMergeStdout(t *testing.T) {
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	if err := runMerge(cmd, []string{"../../testdata/sample.out"}); err != nil {
+		t.Fatalf("runMerge failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "end_of_record") {
+		t.Errorf("Expected LCOV output on stdout, got: %s", out.String())
+	}
+}
+
+func TestRunMergeInvalidFile(t *testing.T) {
+	err := runMerge(&cobra.Command{}, []string{"nonexistent.lcov"})
+	if err == nil || !strings.Contains(err.Error(), "nonexistent.lcov") {
+		t.Errorf("Expected error naming the missing file, got: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// metricFlagUsage is the help text of the --metric flag shared by the commands
+const metricFlagUsage = "Coverage metric: line, statement (default: the format's own, statement for Go profiles)"
+
+// validateMetric checks a --metric value, empty keeps the format's own metric
+func validateMetric(metric string) error {
+	switch models.Metric(metric) {
+	case "", models.MetricLine, models.MetricStatement:
+		return nil
+	}
+	return fmt.Errorf("invalid metric '%s': must be one of: line, statement", metric)
+}
+
+// metricUnit names what the report's coverage counts, for column headers
+func metricUnit(report *models.CoverageReport) string {
+	if report.Metric == models.MetricStatement {
+		return "Statements"
+	}
+	return "Lines"
+}
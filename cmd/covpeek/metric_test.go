@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+func TestValidateMetric(t *testing.T) {
+	for _, metric := range []string{"", "line", "statement"} {
+		if err := validateMetric(metric); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", metric, err)
+		}
+	}
+	if err := validateMetric("function"); err == nil || !strings.Contains(err.Error(), "invalid metric 'function'") {
+		t.Errorf("Expected invalid metric error, got: %v", err)
+	}
+}
+
+func TestMetricUnit(t *testing.T) {
+	report := models.NewCoverageReport()
+	if unit := metricUnit(report); unit != "Lines" {
+		t.Errorf("Expected Lines, got: %s", unit)
+	}
+	report.Metric = models.MetricStatement
+	if unit := metricUnit(report); unit != "Statements" {
+		t.Errorf("Expected Statements, got: %s", unit)
+	}
+}
+
+func TestRunCIMetric(t *testing.T) {
+	minCoverage = 0
+	ciMetric = "branch"
+	defer func() { ciMetric = "" }()
+
+	err := runCI(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid metric") {
+		t.Errorf("Expected invalid metric error, got: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/Chapati-Systems/covpeek/pkg/models"
@@ -24,6 +25,10 @@ func outputTable(report *models.CoverageReport) error {
 
 	// Calculate overall coverage
 	totalLines, totalCovered, overallPct := report.CalculateOverallCoverage()
+	totalBranches, branchesCovered, branchPct := report.CalculateOverallBranchCoverage()
+
+	// Only show branch columns when the report carries branch data
+	showBranches := totalBranches > 0
 
 	// Sort files by coverage descending (highest first)
 	type fileEntry struct {
@@ -48,36 +53,60 @@ func outputTable(report *models.CoverageReport) error {
 		}
 	}()
 
-	// Print header
-	if _, err := fmt.Fprintln(w, "File\tTotal Lines\tCovered Lines\tCoverage %"); err != nil {
+	// Print header, Go reports count statements instead of lines
+	unit := metricUnit(report)
+	header := fmt.Sprintf("File\tTotal %s\tCovered %s\tCoverage %%", unit, unit)
+	separator := "----\t----------\t-------------\t----------"
+	if showBranches {
+		header += "\tTotal Branches\tCovered Branches\tBranch %"
+		separator += "\t--------------\t----------------\t--------"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
 		return fmt.Errorf("failed to write table header: %w", err)
 	}
-	if _, err := fmt.Fprintln(w, "----\t----------\t-------------\t----------"); err != nil {
+	if _, err := fmt.Fprintln(w, separator); err != nil {
 		return fmt.Errorf("failed to write table separator: %w", err)
 	}
 
 	// Print file rows
 	for _, entry := range entries {
-		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\n",
+		total, covered := entry.cov.Counts(report.Metric)
+		row := fmt.Sprintf("%s\t%d\t%d\t%.2f%%",
 			entry.name,
-			entry.cov.TotalLines,
-			entry.cov.CoveredLines,
-			entry.cov.CoveragePct); err != nil {
+			total,
+			covered,
+			entry.cov.CoveragePct)
+		if showBranches {
+			row += formatBranchColumns(entry.cov.TotalBranches, entry.cov.CoveredBranches, entry.cov.BranchPct)
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
 			return fmt.Errorf("failed to write table row: %w", err)
 		}
 	}
 
 	// Print overall summary
-	if _, err := fmt.Fprintf(w, "\nOverall\t%d\t%d\t%.2f%%\n",
+	summary := fmt.Sprintf("\nOverall\t%d\t%d\t%.2f%%",
 		totalLines,
 		totalCovered,
-		overallPct); err != nil {
+		overallPct)
+	if showBranches {
+		summary += formatBranchColumns(totalBranches, branchesCovered, branchPct)
+	}
+	if _, err := fmt.Fprintln(w, summary); err != nil {
 		return fmt.Errorf("failed to write table summary: %w", err)
 	}
 
 	return nil
 }
 
+// formatBranchColumns renders the branch columns of a table row, using "-" for files without branches
+func formatBranchColumns(total, covered int, pct float64) string {
+	if total == 0 {
+		return "\t-\t-\t-"
+	}
+	return fmt.Sprintf("\t%d\t%d\t%.2f%%", total, covered, pct)
+}
+
 // outputJSON outputs coverage data in JSON format
 func outputJSON(report *models.CoverageReport) error {
 	encoder := json.NewEncoder(os.Stdout)
@@ -91,7 +120,8 @@ func outputCSV(report *models.CoverageReport) error {
 	defer writer.Flush()
 
 	// Write header
-	if err := writer.Write([]string{"File", "Coverage %", "Covered Lines", "Total Lines"}); err != nil {
+	unit := metricUnit(report)
+	if err := writer.Write([]string{"File", "Coverage %", "Covered " + unit, "Total " + unit, "Branch Coverage %", "Covered Branches", "Total Branches"}); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
@@ -105,11 +135,168 @@ func outputCSV(report *models.CoverageReport) error {
 	// Write file rows
 	for _, filename := range filenames {
 		fileCov := report.Files[filename]
+		total, covered := fileCov.Counts(report.Metric)
 		row := []string{
 			filename,
 			fmt.Sprintf("%.2f", fileCov.CoveragePct),
-			fmt.Sprintf("%d", fileCov.CoveredLines),
-			fmt.Sprintf("%d", fileCov.TotalLines),
+			fmt.Sprintf("%d", covered),
+			fmt.Sprintf("%d", total),
+			fmt.Sprintf("%.2f", fileCov.BranchPct),
+			fmt.Sprintf("%d", fileCov.CoveredBranches),
+			fmt.Sprintf("%d", fileCov.TotalBranches),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// groupTotals adds up the totals of groups for the overall row
+func groupTotals(groups []*models.CoverageNode) *models.CoverageNode {
+	overall := &models.CoverageNode{Name: "Overall"}
+	for _, group := range groups {
+		overall.Files += group.Files
+		overall.TotalLines += group.TotalLines
+		overall.CoveredLines += group.CoveredLines
+		overall.TotalFunctions += group.TotalFunctions
+		overall.CoveredFunctions += group.CoveredFunctions
+		overall.TotalBranches += group.TotalBranches
+		overall.CoveredBranches += group.CoveredBranches
+	}
+	overall.CoveragePct = models.Percent(overall.CoveredLines, overall.TotalLines)
+	overall.FunctionPct = models.Percent(overall.CoveredFunctions, overall.TotalFunctions)
+	overall.BranchPct = models.Percent(overall.CoveredBranches, overall.TotalBranches)
+	return overall
+}
+
+// outputGroupTable outputs the totals of groups of files in a table, like outputTable
+func outputGroupTable(report *models.CoverageReport, groups []*models.CoverageNode, by models.GroupBy) error {
+	if report.TestName != "" {
+		fmt.Printf("Test Name: %s\n\n", report.TestName)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No files found in coverage report")
+		return nil
+	}
+
+	// Only show function and branch columns when the groups carry such data
+	overall := groupTotals(groups)
+	showFunctions := overall.TotalFunctions > 0
+	showBranches := overall.TotalBranches > 0
+
+	// Sort groups by coverage descending (highest first)
+	sorted := append([]*models.CoverageNode(nil), groups...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CoveragePct > sorted[j].CoveragePct
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() {
+		if err := w.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error flushing output: %v\n", err)
+		}
+	}()
+
+	unit := metricUnit(report)
+	title := groupTitle(by)
+	header := fmt.Sprintf("%s\tFiles\tTotal %s\tCovered %s\tCoverage %%", title, unit, unit)
+	separator := strings.Repeat("-", len(title)) + "\t-----\t----------\t-------------\t----------"
+	if showFunctions {
+		header += "\tTotal Functions\tCovered Functions\tFunction %"
+		separator += "\t---------------\t-----------------\t----------"
+	}
+	if showBranches {
+		header += "\tTotal Branches\tCovered Branches\tBranch %"
+		separator += "\t--------------\t----------------\t--------"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, separator); err != nil {
+		return fmt.Errorf("failed to write table separator: %w", err)
+	}
+
+	formatRow := func(group *models.CoverageNode) string {
+		row := fmt.Sprintf("%s\t%d\t%d\t%d\t%.2f%%", group.Name, group.Files, group.TotalLines, group.CoveredLines, group.CoveragePct)
+		if showFunctions {
+			row += formatBranchColumns(group.TotalFunctions, group.CoveredFunctions, group.FunctionPct)
+		}
+		if showBranches {
+			row += formatBranchColumns(group.TotalBranches, group.CoveredBranches, group.BranchPct)
+		}
+		return row
+	}
+
+	for _, group := range sorted {
+		if _, err := fmt.Fprintln(w, formatRow(group)); err != nil {
+			return fmt.Errorf("failed to write table row: %w", err)
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "\n"+formatRow(overall)); err != nil {
+		return fmt.Errorf("failed to write table summary: %w", err)
+	}
+
+	return nil
+}
+
+// groupReport is the JSON document written for groups of files
+type groupReport struct {
+	TestName string
+	Metric   models.Metric
+	GroupBy  models.GroupBy
+	Groups   []*models.CoverageNode
+}
+
+// outputGroupJSON outputs the totals of groups of files in JSON format,
+// without the files of each group
+func outputGroupJSON(report *models.CoverageReport, groups []*models.CoverageNode, by models.GroupBy) error {
+	summaries := make([]*models.CoverageNode, 0, len(groups))
+	for _, group := range groups {
+		summary := *group
+		summary.Children = nil
+		summaries = append(summaries, &summary)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(groupReport{
+		TestName: report.TestName,
+		Metric:   report.Metric,
+		GroupBy:  by,
+		Groups:   summaries,
+	})
+}
+
+// outputGroupCSV outputs the totals of groups of files in CSV format, in name order
+func outputGroupCSV(report *models.CoverageReport, groups []*models.CoverageNode, by models.GroupBy) error {
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	unit := metricUnit(report)
+	header := []string{groupTitle(by), "Files", "Coverage %", "Covered " + unit, "Total " + unit,
+		"Function Coverage %", "Covered Functions", "Total Functions",
+		"Branch Coverage %", "Covered Branches", "Total Branches"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, group := range groups {
+		row := []string{
+			group.Name,
+			fmt.Sprintf("%d", group.Files),
+			fmt.Sprintf("%.2f", group.CoveragePct),
+			fmt.Sprintf("%d", group.CoveredLines),
+			fmt.Sprintf("%d", group.TotalLines),
+			fmt.Sprintf("%.2f", group.FunctionPct),
+			fmt.Sprintf("%d", group.CoveredFunctions),
+			fmt.Sprintf("%d", group.TotalFunctions),
+			fmt.Sprintf("%.2f", group.BranchPct),
+			fmt.Sprintf("%d", group.CoveredBranches),
+			fmt.Sprintf("%d", group.TotalBranches),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
@@ -424,3 +424,181 @@ func TestTableModel(t *testing.T) {
 	cmd := model.Init()
 	_ = cmd // Just check it doesn't panic
 }
+
+func TestOutputTableWithBranches(t *testing.T) {
+	report := createBranchTestReport()
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := outputTable(report)
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("outputTable failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "Branch %") {
+		t.Error("Table output should contain 'Branch %' header")
+	}
+
+	if !strings.Contains(output, "50.00%") {
+		t.Error("Table should contain branch coverage of lib.rs")
+	}
+
+	// Overall branch coverage: 1 of 2 branches
+	if !strings.Contains(output, "Overall") || strings.Count(output, "50.00%") < 2 {
+		t.Error("Table should contain overall branch coverage")
+	}
+}
+
+func TestOutputTableWithoutBranches(t *testing.T) {
+	report := createTestReport()
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := outputTable(report)
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("outputTable failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if strings.Contains(buf.String(), "Branch %") {
+		t.Error("Table output should not contain branch columns without branch data")
+	}
+}
+
+func TestOutputCSVWithBranches(t *testing.T) {
+	report := createBranchTestReport()
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := outputCSV(report)
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("outputCSV failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV output is not valid: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+
+	// Rows are sorted by name: lib.rs, main.rs
+	expected := []string{"lib.rs", "100.00", "2", "2", "50.00", "1", "2"}
+	for i, value := range expected {
+		if records[1][i] != value {
+			t.Errorf("Expected column %d to be '%s', got '%s'", i, value, records[1][i])
+		}
+	}
+}
+
+func TestOutputJSONWithBranches(t *testing.T) {
+	report := createBranchTestReport()
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := outputJSON(report)
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("outputJSON failed: %v", err)
+	}
+
+	var decoded models.CoverageReport
+	if err := json.NewDecoder(r).Decode(&decoded); err != nil {
+		t.Fatalf("JSON output is not valid: %v", err)
+	}
+
+	lib := decoded.Files["lib.rs"]
+	if lib == nil || lib.TotalBranches != 2 || lib.BranchPct != 50.0 || len(lib.Branches) != 2 {
+		t.Errorf("Expected branch data for lib.rs in JSON output, got %+v", lib)
+	}
+}
+
+func TestTableModelWithBranches(t *testing.T) {
+	model := newTableModel(createBranchTestReport())
+
+	if len(model.table.Columns()) != 7 {
+		t.Fatalf("Expected 7 columns with branch data, got %d", len(model.table.Columns()))
+	}
+
+	model.sortByColumn(6) // Sort by branch coverage (descending)
+	rows := model.table.Rows()
+	if rows[0][0] != "lib.rs" || rows[1][6] != "-" {
+		t.Errorf("Expected lib.rs first and '-' branch coverage for main.rs, got %v", rows)
+	}
+
+	if !strings.Contains(model.View(), "branch coverage") {
+		t.Error("View should show branch coverage as the sort column")
+	}
+}
+
+// Helper function to create a test report with branch data
+func createBranchTestReport() *models.CoverageReport {
+	report := models.NewCoverageReport()
+
+	lib := &models.FileCoverage{
+		FileName:     "lib.rs",
+		TotalLines:   2,
+		CoveredLines: 2,
+		Lines: map[int]models.LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 2},
+			2: {LineNumber: 2, ExecutionCount: 1},
+		},
+		Branches: []models.BranchCoverage{
+			{LineNumber: 1, BlockNumber: 0, BranchID: "0", TakenCount: 1},
+			{LineNumber: 1, BlockNumber: 0, BranchID: "1", TakenCount: 0},
+		},
+	}
+	lib.CountBranches()
+	lib.CalculateCoverage()
+	report.AddFile(lib)
+
+	bin := &models.FileCoverage{
+		FileName:     "main.rs",
+		TotalLines:   4,
+		CoveredLines: 1,
+		Lines: map[int]models.LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 1},
+			2: {LineNumber: 2, ExecutionCount: 0},
+			3: {LineNumber: 3, ExecutionCount: 0},
+			4: {LineNumber: 4, ExecutionCount: 0},
+		},
+	}
+	bin.CalculateCoverage()
+	report.AddFile(bin)
+
+	return report
+}
@@ -1,13 +1,11 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 
-	"github.com/Chapati-Systems/covpeek/internal/detector"
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
 	"github.com/Chapati-Systems/covpeek/pkg/models"
 	"github.com/Chapati-Systems/covpeek/pkg/parser"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,15 +26,23 @@ var (
 	forceFormat  string
 	belowPct     float64
 	tuiMode      bool
+	rootMetric   string
+	sourceDir    string
+	groupBy      string
 )
 
 func init() {
 	// Define flags on root command
-	rootCmd.Flags().StringVarP(&coverageFile, "file", "f", "", "Path to coverage file")
-	rootCmd.Flags().StringVar(&forceFormat, "format", "", "Override format detection (rust, go, ts)")
+	rootCmd.Flags().StringVarP(&coverageFile, "file", "f", "", "Path to coverage file"+fileFlagStdin)
+	rootCmd.Flags().StringVar(&forceFormat, "format", "", "Override format detection ("+strings.Join(parser.Names(), ", ")+")")
 	rootCmd.Flags().Float64VarP(&belowPct, "below", "b", 0, "Coverage threshold filter (0-100)")
 	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, json, csv)")
 	rootCmd.Flags().BoolVar(&tuiMode, "tui", false, "Launch interactive TUI for exploring coverage data")
+	rootCmd.Flags().StringVar(&rootMetric, "metric", "", metricFlagUsage)
+	rootCmd.Flags().StringVar(&groupBy, "group-by", "", groupByFlagUsage)
+	rootCmd.Flags().StringVar(&sourceDir, "source-dir", "", "Directory of the Go module to read function coverage from (Go profiles only)")
+	rootWarnings.register(rootCmd)
+	registerPathFlags(rootCmd)
 
 	// Set the run function for the root command
 	rootCmd.RunE = runParse
@@ -46,17 +52,8 @@ func init() {
 func validateFlags(cmd *cobra.Command, args []string) error {
 	// Validate format if specified
 	if forceFormat != "" {
-		validFormats := map[string]bool{
-			"rust":   true,
-			"go":     true,
-			"ts":     true,
-			"lcov":   true, // alias for rust/ts
-			"python": true,
-			"pyxml":  true,
-			"pyjson": true,
-		}
-		if !validFormats[strings.ToLower(forceFormat)] {
-			return fmt.Errorf("invalid format '%s': must be one of: rust, go, ts, python, pyxml, pyjson", forceFormat)
+		if parser.Lookup(forceFormat) == nil {
+			return fmt.Errorf("invalid format '%s': must be one of: %s", forceFormat, strings.Join(parser.Names(), ", "))
 		}
 	}
 
@@ -75,7 +72,15 @@ func validateFlags(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid output format '%s': must be one of: table, json, csv", outputFormat)
 	}
 
-	return nil
+	if err := validateMetric(rootMetric); err != nil {
+		return err
+	}
+
+	if _, _, err := parseGroupBy(groupBy); err != nil {
+		return err
+	}
+
+	return rootWarnings.validate()
 }
 
 func runParse(cmd *cobra.Command, args []string) error {
@@ -103,99 +108,59 @@ func runParse(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate file exists and is readable
-	fileInfo, err := os.Stat(coverageFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("file does not exist: %s", coverageFile)
+	var fileInfo os.FileInfo
+	if coverageFile != stdinPath {
+		var err error
+		fileInfo, err = os.Stat(coverageFile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("file does not exist: %s", coverageFile)
+			}
+			return fmt.Errorf("cannot access file %s: %w", coverageFile, err)
 		}
-		return fmt.Errorf("cannot access file %s: %w", coverageFile, err)
 	}
 
-	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", coverageFile)
-	}
-
-	// Test if file is readable
-	file, err := os.Open(coverageFile)
+	// Detect the format (unless forced) and parse
+	loader, err := newLoader(covpeek.Loader{Format: forceFormat, Metric: models.Metric(rootMetric), SourceDir: sourceDir})
 	if err != nil {
-		return fmt.Errorf("cannot read file %s: %w", coverageFile, err)
+		return err
+	}
+	var result *covpeek.Result
+	switch {
+	case coverageFile == stdinPath:
+		result, err = loadCoverage(cmd, loader, coverageFile)
+	case fileInfo.IsDir():
+		// Directory formats such as a GOCOVERDIR
+		result, err = loader.LoadDir(coverageFile)
+	default:
+		// Test if file is readable
+		file, openErr := os.Open(coverageFile)
+		if openErr != nil {
+			return fmt.Errorf("cannot read file %s: %w", coverageFile, openErr)
+		}
+		defer func() { _ = file.Close() }()
+		result, err = loader.LoadReader(file, coverageFile)
 	}
-	defer func() { _ = file.Close() }()
-
-	// Read file content into memory for detection and parsing
-	content, err := io.ReadAll(file)
 	if err != nil {
-		return fmt.Errorf("failed to read coverage file: %w", err)
+		return err
+	}
+	// Archives may hold coverage files of several formats
+	description := "several formats"
+	if p := parser.Lookup(result.Format); p != nil {
+		description = p.Description()
 	}
-
-	// Detect format
-	var format detector.CoverageFormat
 	if forceFormat != "" {
-		// Map format names to detector format
-		switch strings.ToLower(forceFormat) {
-		case "lcov", "rust", "ts":
-			format = detector.LCOVFormat
-		case "go":
-			format = detector.GoCoverFormat
-		case "python", "pyxml":
-			format = detector.PyCoverXMLFormat
-		case "pyjson":
-			format = detector.PyCoverJSONFormat
-		default:
-			return fmt.Errorf("unknown format: %s (use 'rust', 'go', 'ts', 'python', 'pyxml', or 'pyjson')", forceFormat)
-		}
-		cmd.PrintErrf("Using forced format: %s\n", format)
+		cmd.PrintErrf("Using forced format: %s\n", description)
 	} else {
-		// Try detection by extension first
-		format = detector.DetectFormatByExtension(coverageFile)
-		if format == detector.UnknownFormat {
-			// Fall back to content-based detection
-			format, err = detector.DetectFormat(bytes.NewReader(content))
-			if err != nil {
-				return fmt.Errorf("failed to detect coverage format: %w", err)
-			}
-		}
-
-		if format == detector.UnknownFormat {
-			return fmt.Errorf("unable to detect coverage format for file: %s", coverageFile)
-		}
-
-		cmd.PrintErrf("Detected format: %s\n", format)
+		cmd.PrintErrf("Detected format: %s\n", description)
 	}
+	if err := rootWarnings.check(cmd, result.Warnings); err != nil {
+		return err
+	}
+	report := result.Report
 
-	// Parse based on detected format
-	var report *models.CoverageReport
-	switch format {
-	case detector.LCOVFormat:
-		p := parser.NewLCOVParser()
-		report, err = p.Parse(bytes.NewReader(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse LCOV file: %w", err)
-		}
-
-	case detector.GoCoverFormat:
-		p := parser.NewGoCoverParser()
-		report, err = p.Parse(bytes.NewReader(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse Go coverage file: %w", err)
-		}
-
-	case detector.PyCoverXMLFormat:
-		p := parser.NewPyCoverXMLParser()
-		report, err = p.Parse(bytes.NewReader(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse Python XML coverage file: %w", err)
-		}
-
-	case detector.PyCoverJSONFormat:
-		p := parser.NewPyCoverJSONParser()
-		report, err = p.Parse(bytes.NewReader(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse Python JSON coverage file: %w", err)
-		}
-
-	default:
-		return fmt.Errorf("unsupported coverage format: %s", format)
+	if groupBy != "" {
+		return outputGroups(report)
 	}
 
 	// Apply threshold filter if specified
@@ -220,11 +185,44 @@ func runParse(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// outputGroups outputs the totals of groups of files. The threshold filter
+// applies to the coverage of the groups.
+func outputGroups(report *models.CoverageReport) error {
+	by, depth, err := parseGroupBy(groupBy)
+	if err != nil {
+		return err
+	}
+	groups := report.Group(by, depth)
+	if tuiMode && by == models.GroupByDir {
+		// Directories nest in the tree
+		groups = report.Tree().Children
+	}
+	if belowPct > 0 {
+		groups = filterGroupsBelowThreshold(groups, belowPct)
+	}
+
+	if tuiMode {
+		return runTUI(newTreeModel(report, groups))
+	}
+
+	switch strings.ToLower(outputFormat) {
+	case "json":
+		return outputGroupJSON(report, groups, by)
+	case "csv":
+		return outputGroupCSV(report, groups, by)
+	default:
+		return outputGroupTable(report, groups, by)
+	}
+}
+
 // outputTUI launches an interactive TUI for exploring coverage data
 func outputTUI(report *models.CoverageReport) error {
 	// Create initial table model
-	model := newTableModel(report)
+	return runTUI(newTableModel(report))
+}
 
+// runTUI runs a TUI model until the user quits
+func runTUI(model tableModel) error {
 	// Run the TUI with mouse support
 	p := tea.NewProgram(model, tea.WithMouseAllMotion())
 	if _, err := p.Run(); err != nil {
@@ -332,3 +332,45 @@ func TestRunParseDirectory(t *testing.T) {
 		t.Error("Expected error for directory")
 	}
 }
+
+func TestRunParseStdin(t *testing.T) {
+	origFile := coverageFile
+	origFormat := outputFormat
+	origForce := forceFormat
+	origTui := tuiMode
+	defer func() {
+		coverageFile = origFile
+		outputFormat = origFormat
+		forceFormat = origForce
+		tuiMode = origTui
+	}()
+
+	coverageFile = "-"
+	outputFormat = "csv"
+	forceFormat = ""
+	tuiMode = false
+
+	var stderr strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetErr(&stderr)
+	cmd.SetIn(strings.NewReader("TN:\nSF:src/lib.rs\nDA:1,1\nend_of_record\n"))
+	if err := runParse(cmd, []string{}); err != nil {
+		t.Fatalf("runParse from stdin failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Detected format: LCOV") {
+		t.Errorf("Expected LCOV to be detected by content, got: %s", stderr.String())
+	}
+
+	// Content matching several formats equally well needs --format
+	ambiguous := "<report>\nSF:foo\n</report>\n"
+	cmd.SetIn(strings.NewReader(ambiguous))
+	if err := runParse(cmd, []string{}); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous format error, got: %v", err)
+	}
+
+	forceFormat = "lcov"
+	cmd.SetIn(strings.NewReader(ambiguous))
+	if err := runParse(cmd, []string{}); err != nil {
+		t.Errorf("Expected forced format to resolve the ambiguity, got: %v", err)
+	}
+}
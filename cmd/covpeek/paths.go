@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pathMaps      []string
+	stripPrefixes []string
+	keepPaths     bool
+)
+
+// registerPathFlags adds --path-map, --strip-prefix and --keep-paths to a
+// command. The commands share the values, they all map file names the same way.
+func registerPathFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&pathMaps, "path-map", nil, "Rewrite file names starting with a directory, as from=to (repeatable)")
+	cmd.Flags().StringArrayVar(&stripPrefixes, "strip-prefix", nil, "Remove a directory from the start of file names (repeatable)")
+	cmd.Flags().BoolVar(&keepPaths, "keep-paths", false, "Keep file names as the coverage report wrote them")
+}
+
+// newPathMapper returns the mapping of file names to names relative to the
+// working directory, nil with --keep-paths
+func newPathMapper() (*covpeek.PathMapper, error) {
+	if keepPaths {
+		return nil, nil
+	}
+	mapper, err := covpeek.NewPathMapper(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up path mapping: %w", err)
+	}
+	for _, value := range pathMaps {
+		from, to, ok := strings.Cut(value, "=")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid --path-map '%s': expected from=to", value)
+		}
+		mapper.Rules = append(mapper.Rules, covpeek.PathRule{From: from, To: to})
+	}
+	mapper.StripPrefixes = stripPrefixes
+	return mapper, nil
+}
+
+// newLoader returns a loader mapping file names as the flags ask
+func newLoader(loader covpeek.Loader) (*covpeek.Loader, error) {
+	mapper, err := newPathMapper()
+	if err != nil {
+		return nil, err
+	}
+	loader.PathMapper = mapper
+	return &loader, nil
+}
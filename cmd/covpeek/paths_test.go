@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+func resetPathFlags() {
+	pathMaps = nil
+	stripPrefixes = nil
+	keepPaths = false
+}
+
+func TestNewPathMapper(t *testing.T) {
+	defer resetPathFlags()
+
+	pathMaps = []string{"/ci/src=src", "/empty="}
+	stripPrefixes = []string{"/build"}
+	mapper, err := newPathMapper()
+	if err != nil {
+		t.Fatalf("newPathMapper failed: %v", err)
+	}
+	if len(mapper.Rules) != 2 || mapper.Rules[0].From != "/ci/src" || mapper.Rules[0].To != "src" || mapper.Rules[1].To != "" {
+		t.Errorf("Expected two rules, got %+v", mapper.Rules)
+	}
+	if name := mapper.Map("/build/lib/x.rs"); name != "lib/x.rs" {
+		t.Errorf("Expected the prefix to be stripped, got %s", name)
+	}
+
+	pathMaps = []string{"/ci/src"}
+	if _, err := newPathMapper(); err == nil || !strings.Contains(err.Error(), "expected from=to") {
+		t.Errorf("Expected an error for a rule without =, got %v", err)
+	}
+
+	keepPaths = true
+	if mapper, err := newPathMapper(); mapper != nil || err != nil {
+		t.Errorf("Expected no mapping with --keep-paths, got %v, %v", mapper, err)
+	}
+}
+
+func TestRunMerge_PathMap(t *testing.T) {
+	defer resetPathFlags()
+	defer func() { mergeOutput = "" }()
+
+	// The same file covered in a container and on a Windows runner
+	dir := t.TempDir()
+	linux := filepath.Join(dir, "linux.lcov")
+	windows := filepath.Join(dir, "windows.lcov")
+	if err := os.WriteFile(linux, []byte("SF:/app/src/lib.rs\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(windows, []byte("SF:C:\\work\\src\\lib.rs\nDA:1,0\nDA:2,4\nLF:2\nLH:1\nend_of_record\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	pathMaps = []string{"/app=", `C:\work=`}
+	mergeOutput = filepath.Join(dir, "merged.lcov")
+
+	cmd := &cobra.Command{}
+	cmd.SetErr(&strings.Builder{})
+	if err := runMerge(cmd, []string{linux, windows}); err != nil {
+		t.Fatalf("runMerge failed: %v", err)
+	}
+
+	result, err := covpeek.Load(mergeOutput)
+	if err != nil {
+		t.Fatalf("Failed to parse merged report: %v", err)
+	}
+	report := result.Report
+	file := report.GetFile("src/lib.rs")
+	if len(report.Files) != 1 || file == nil {
+		t.Fatalf("Expected src/lib.rs alone, got %v", report.Files)
+	}
+	if file.CoveredLines != 2 {
+		t.Errorf("Expected both lines covered, got %d", file.CoveredLines)
+	}
+
+	pathMaps = []string{"=src"}
+	if err := runMerge(cmd, []string{linux}); err == nil || !strings.Contains(err.Error(), "--path-map") {
+		t.Errorf("Expected an error for an invalid --path-map, got %v", err)
+	}
+}
@@ -14,7 +14,8 @@ var rootCmd = &cobra.Command{
 from multiple languages including Rust, Go, TypeScript, JavaScript, and Python.
 
 It supports LCOV format (.lcov, .info), Go coverage format (.out), 
-and Python coverage formats (.xml, .json).`,
+and Python coverage formats (.xml, .json). A Go coverage directory 
+(GOCOVERDIR) can be passed to --file as well.`,
 	Example: `  # Parse a coverage file and display table
   covpeek --file coverage.lcov
 
@@ -30,9 +31,18 @@ and Python coverage formats (.xml, .json).`,
   # Output as JSON
   covpeek --file coverage.out --output json
 
+  # Read coverage piped from another tool
+  cargo llvm-cov --lcov | covpeek --file -
+
   # Filter files below 80% coverage
   covpeek --file coverage.lcov --below 80
 
+  # Roll files up by directory
+  covpeek --file coverage.lcov --group-by dir
+
+  # Name files relative to the repository for a report written in a container
+  covpeek --file coverage.lcov --strip-prefix /app
+
   # Force format detection
   covpeek --file coverage.txt --force-format lcov`,
 	SilenceUsage: false,
@@ -12,33 +12,64 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// sortLabels names each table column in the help line, in column order
+var sortLabels = []string{"file", "total", "covered", "coverage", "branches", "covered branches", "branch coverage"}
+
+// Markers in front of the groups and files of the tree
+const (
+	treeCollapsed = "▸ "
+	treeExpanded  = "▾ "
+	treeLeaf      = "  "
+)
+
 // tableModel holds the state for the TUI table
 type tableModel struct {
 	table        table.Model
 	sortCol      int
 	sortAsc      bool
 	originalRows []table.Row
+
+	// roots are the groups shown as a collapsible tree, nil for a flat list of files
+	roots []*models.CoverageNode
+	// expanded holds the paths of the expanded groups
+	expanded map[string]bool
+	// visible lists the tree node of every row, in row order
+	visible      []treeRow
+	showBranches bool
+}
+
+// treeRow is a row of the tree and how deep it is nested
+type treeRow struct {
+	node  *models.CoverageNode
+	depth int
 }
 
 // newTableModel creates a new table model for the TUI
 func newTableModel(report *models.CoverageReport) tableModel {
-	// Create table columns
-	columns := []table.Column{
-		{Title: "File", Width: 50},
-		{Title: "Total Lines", Width: 12},
-		{Title: "Covered Lines", Width: 13},
-		{Title: "Coverage %", Width: 11},
-	}
+	columns, showBranches := coverageColumns(report)
 
 	// Create table rows
 	var rows []table.Row
 	for name, cov := range report.Files {
-		rows = append(rows, table.Row{
+		total, covered := cov.Counts(report.Metric)
+		row := table.Row{
 			name,
-			fmt.Sprintf("%d", cov.TotalLines),
-			fmt.Sprintf("%d", cov.CoveredLines),
+			fmt.Sprintf("%d", total),
+			fmt.Sprintf("%d", covered),
 			fmt.Sprintf("%.2f", cov.CoveragePct),
-		})
+		}
+		if showBranches {
+			branchPct := "-"
+			if cov.TotalBranches > 0 {
+				branchPct = fmt.Sprintf("%.2f", cov.BranchPct)
+			}
+			row = append(row,
+				fmt.Sprintf("%d", cov.TotalBranches),
+				fmt.Sprintf("%d", cov.CoveredBranches),
+				branchPct,
+			)
+		}
+		rows = append(rows, row)
 	}
 
 	// Sort rows by coverage descending initially
@@ -48,6 +79,61 @@ func newTableModel(report *models.CoverageReport) tableModel {
 		return covI > covJ
 	})
 
+	return tableModel{
+		table:        newCoverageTable(columns, rows),
+		sortCol:      3,     // Coverage % column
+		sortAsc:      false, // descending
+		originalRows: make([]table.Row, len(rows)),
+	}
+}
+
+// newTreeModel creates a table model showing groups of files as a collapsible
+// tree, sorted by coverage descending. A single group starts expanded.
+func newTreeModel(report *models.CoverageReport, roots []*models.CoverageNode) tableModel {
+	columns, showBranches := coverageColumns(report)
+	m := tableModel{
+		table:        newCoverageTable(columns, nil),
+		sortCol:      3,     // Coverage % column
+		sortAsc:      false, // descending
+		roots:        roots,
+		expanded:     make(map[string]bool),
+		showBranches: showBranches,
+	}
+	if len(roots) == 1 {
+		m.expanded[roots[0].Path] = true
+	}
+	m.sortByColumn(m.sortCol)
+	return m
+}
+
+// coverageColumns returns the table columns for a report, and whether they
+// include the branch columns
+func coverageColumns(report *models.CoverageReport) ([]table.Column, bool) {
+	// Create table columns
+	unit := metricUnit(report)
+	columns := []table.Column{
+		{Title: "File", Width: 50},
+		{Title: "Total " + unit, Width: len("Total "+unit) + 1},
+		{Title: "Covered " + unit, Width: len("Covered " + unit)},
+		{Title: "Coverage %", Width: 11},
+	}
+
+	// Only add branch columns when the report carries branch data
+	totalBranches, _, _ := report.CalculateOverallBranchCoverage()
+	showBranches := totalBranches > 0
+	if showBranches {
+		columns = append(columns,
+			table.Column{Title: "Branches", Width: 10},
+			table.Column{Title: "Covered Br.", Width: 11},
+			table.Column{Title: "Branch %", Width: 9},
+		)
+	}
+
+	return columns, showBranches
+}
+
+// newCoverageTable creates the focused table of the TUI
+func newCoverageTable(columns []table.Column, rows []table.Row) table.Model {
 	// Create table
 	t := table.New(
 		table.WithColumns(columns),
@@ -71,12 +157,29 @@ func newTableModel(report *models.CoverageReport) tableModel {
 	// Update viewport after setting styles
 	t.UpdateViewport()
 
-	return tableModel{
-		table:        t,
-		sortCol:      3,     // Coverage % column
-		sortAsc:      false, // descending
-		originalRows: make([]table.Row, len(rows)),
+	return t
+}
+
+// nodeRow returns the table row of a group or file of the tree
+func nodeRow(node *models.CoverageNode, showBranches bool) table.Row {
+	row := table.Row{
+		node.Name,
+		fmt.Sprintf("%d", node.TotalLines),
+		fmt.Sprintf("%d", node.CoveredLines),
+		fmt.Sprintf("%.2f", node.CoveragePct),
+	}
+	if showBranches {
+		branchPct := "-"
+		if node.TotalBranches > 0 {
+			branchPct = fmt.Sprintf("%.2f", node.BranchPct)
+		}
+		row = append(row,
+			fmt.Sprintf("%d", node.TotalBranches),
+			fmt.Sprintf("%d", node.CoveredBranches),
+			branchPct,
+		)
 	}
+	return row
 }
 
 // Init implements tea.Model
@@ -100,18 +203,20 @@ func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Check if click is in header area (roughly top 2 lines)
 			if msg.Y <= 2 {
 				// Determine which column was clicked based on X position
-				colWidths := []int{50, 12, 13, 11} // File, Total Lines, Covered Lines, Coverage %
 				x := 0
-				for i, width := range colWidths {
-					if msg.X >= x && msg.X < x+width {
+				for i, col := range m.table.Columns() {
+					if msg.X >= x && msg.X < x+col.Width {
 						m.sortByColumn(i)
 						break
 					}
-					x += width + 1 // +1 for separator
+					x += col.Width + 1 // +1 for separator
 				}
 			}
 		}
 	case tea.KeyMsg:
+		if m.roots != nil && m.updateTree(msg.String()) {
+			return m, nil
+		}
 		switch msg.String() {
 		case "esc":
 			if m.table.Focused() {
@@ -135,6 +240,10 @@ func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.sortByColumn(2)
 		case "p": // sort by coverage %
 			m.sortByColumn(3)
+		case "b": // sort by branch coverage %, when branch columns are shown
+			if len(m.table.Columns()) > 6 {
+				m.sortByColumn(6)
+			}
 		case "r": // reverse sort
 			m.sortAsc = !m.sortAsc
 			m.sortByColumn(m.sortCol)
@@ -147,9 +256,14 @@ func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // sortByColumn sorts the table by the specified column
 func (m *tableModel) sortByColumn(col int) {
-	rows := m.table.Rows()
 	m.sortCol = col
+	if m.roots != nil {
+		m.sortTree(m.roots)
+		m.refreshTree()
+		return
+	}
 
+	rows := m.table.Rows()
 	sort.Slice(rows, func(i, j int) bool {
 		var a, b string
 		if col < len(rows[i]) {
@@ -158,27 +272,112 @@ func (m *tableModel) sortByColumn(col int) {
 		if col < len(rows[j]) {
 			b = rows[j][col]
 		}
+		return m.less(a, b)
+	})
 
-		// For numeric columns, parse as numbers
-		if col == 1 || col == 2 || col == 3 { // Total Lines, Covered Lines, Coverage %
-			aNum, _ := strconv.ParseFloat(a, 64)
-			bNum, _ := strconv.ParseFloat(b, 64)
-			if m.sortAsc {
-				return aNum < bNum
-			}
-			return aNum > bNum
-		}
+	m.table.SetRows(rows)
+}
 
-		// String comparison for other columns
+// less compares two cells of the sort column in the sort direction
+func (m *tableModel) less(a, b string) bool {
+	// For numeric columns, parse as numbers
+	if m.sortCol > 0 { // every column except File is numeric
+		aNum, _ := strconv.ParseFloat(a, 64)
+		bNum, _ := strconv.ParseFloat(b, 64)
 		if m.sortAsc {
-			return a < b
+			return aNum < bNum
+		}
+		return aNum > bNum
+	}
+
+	// String comparison for other columns
+	if m.sortAsc {
+		return a < b
+	}
+	return a > b
+}
+
+// sortTree sorts the groups and files of every level of the tree by the sort column
+func (m *tableModel) sortTree(nodes []*models.CoverageNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodeRow(nodes[i], m.showBranches), nodeRow(nodes[j], m.showBranches)
+		if m.sortCol >= len(a) {
+			return false
 		}
-		return a > b
+		return m.less(a[m.sortCol], b[m.sortCol])
 	})
+	for _, node := range nodes {
+		m.sortTree(node.Children)
+	}
+}
+
+// refreshTree shows the rows of the expanded part of the tree
+func (m *tableModel) refreshTree() {
+	m.visible = nil
+	var rows []table.Row
+	var walk func(nodes []*models.CoverageNode, depth int)
+	walk = func(nodes []*models.CoverageNode, depth int) {
+		for _, node := range nodes {
+			marker := treeLeaf
+			if !node.IsFile() {
+				marker = treeCollapsed
+				if m.expanded[node.Path] {
+					marker = treeExpanded
+				}
+			}
+			row := nodeRow(node, m.showBranches)
+			row[0] = strings.Repeat("  ", depth) + marker + node.Name
+			rows = append(rows, row)
+			m.visible = append(m.visible, treeRow{node: node, depth: depth})
 
+			if m.expanded[node.Path] {
+				walk(node.Children, depth+1)
+			}
+		}
+	}
+	walk(m.roots, 0)
 	m.table.SetRows(rows)
 }
 
+// updateTree expands and collapses the group in the selected row. It reports
+// whether the key was handled.
+func (m *tableModel) updateTree(key string) bool {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.visible) {
+		return false
+	}
+	row := m.visible[cursor]
+
+	switch key {
+	case "right", "l":
+		if !row.node.IsFile() && !m.expanded[row.node.Path] {
+			m.expanded[row.node.Path] = true
+			m.refreshTree()
+		}
+	case "left", "h":
+		if m.expanded[row.node.Path] {
+			delete(m.expanded, row.node.Path)
+			m.refreshTree()
+			return true
+		}
+		// Move up to the enclosing group
+		for i := cursor - 1; i >= 0; i-- {
+			if m.visible[i].depth < row.depth {
+				m.table.SetCursor(i)
+				break
+			}
+		}
+	case " ":
+		if !row.node.IsFile() {
+			m.expanded[row.node.Path] = !m.expanded[row.node.Path]
+			m.refreshTree()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
 // View implements tea.Model
 func (m tableModel) View() string {
 	var b strings.Builder
@@ -196,10 +395,14 @@ func (m tableModel) View() string {
 		sortIndicator = "▲"
 	}
 
+	navigation := "↑/↓ navigate"
+	if m.roots != nil {
+		navigation += " • ←/→ collapse/expand"
+	}
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("241")).
-		Render(fmt.Sprintf("↑/↓ navigate • click headers to sort • s+r reverse • q quit (sorted by %s %s)",
-			[]string{"file", "total", "covered", "coverage"}[m.sortCol], sortIndicator))
+		Render(fmt.Sprintf("%s • click headers to sort • s+r reverse • q quit (sorted by %s %s)",
+			navigation, sortLabels[m.sortCol], sortIndicator))
 	b.WriteString(help + "\n\n")
 
 	// Table
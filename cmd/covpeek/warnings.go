@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+// warningFlags holds the flags controlling how parse diagnostics are reported
+type warningFlags struct {
+	strict      bool
+	maxWarnings int
+	format      string
+}
+
+var (
+	rootWarnings warningFlags
+	ciWarnings   warningFlags
+	diffWarnings warningFlags
+)
+
+// register adds --strict, --max-warnings and --warnings-format to a command
+func (f *warningFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.strict, "strict", false, "Fail if the coverage file produces any warnings")
+	cmd.Flags().IntVar(&f.maxWarnings, "max-warnings", -1, "Fail if the coverage file produces more than N warnings (-1 for no limit)")
+	cmd.Flags().StringVar(&f.format, "warnings-format", "text", "Warnings output format (text, json)")
+}
+
+// validate checks the flag values
+func (f *warningFlags) validate() error {
+	if f.format != "text" && f.format != "json" {
+		return fmt.Errorf("invalid warnings format '%s': must be one of: text, json", f.format)
+	}
+	if f.maxWarnings < -1 {
+		return fmt.Errorf("--max-warnings must be -1 or greater, got: %d", f.maxWarnings)
+	}
+	return nil
+}
+
+// check prints the warnings and fails in strict mode or when there are more
+// warnings than allowed
+func (f *warningFlags) check(cmd *cobra.Command, warnings []covpeek.Warning) error {
+	if f.format == "json" {
+		// The array is written even when empty so it can always be parsed
+		if warnings == nil {
+			warnings = []covpeek.Warning{}
+		}
+		data, err := json.MarshalIndent(warnings, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode warnings: %w", err)
+		}
+		cmd.PrintErrln(string(data))
+	} else {
+		printWarnings(cmd, warnings)
+	}
+
+	if f.strict && len(warnings) > 0 {
+		return fmt.Errorf("found %d warnings in strict mode", len(warnings))
+	}
+	if f.maxWarnings >= 0 && len(warnings) > f.maxWarnings {
+		return fmt.Errorf("found %d warnings, more than the maximum of %d", len(warnings), f.maxWarnings)
+	}
+	return nil
+}
+
+// printWarnings writes warnings to stderr, one per line
+func printWarnings(cmd *cobra.Command, warnings []covpeek.Warning) {
+	for _, warning := range warnings {
+		cmd.PrintErrf("Warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/spf13/cobra"
+)
+
+// runParseWithWarnings runs the root command on the malformed LCOV fixture with
+// the given warning flags and returns stderr
+func runParseWithWarnings(t *testing.T, flags warningFlags) (string, error) {
+	t.Helper()
+
+	coverageFile = "../../testdata/malformed.lcov"
+	forceFormat = ""
+	belowPct = 0
+	outputFormat = "json"
+	tuiMode = false
+	rootWarnings = flags
+	defer func() {
+		coverageFile = ""
+		outputFormat = "table"
+		rootWarnings = warningFlags{maxWarnings: -1, format: "text"}
+	}()
+
+	var stderr bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetErr(&stderr)
+	err := runParse(cmd, []string{})
+	return stderr.String(), err
+}
+
+func TestRunParseWarningsText(t *testing.T) {
+	stderr, err := runParseWithWarnings(t, warningFlags{maxWarnings: -1, format: "text"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(stderr, "Warning: ../../testdata/malformed.lcov: line 4: ") {
+		t.Errorf("Expected warning with file and line, got: %s", stderr)
+	}
+}
+
+func TestRunParseStrict(t *testing.T) {
+	_, err := runParseWithWarnings(t, warningFlags{strict: true, maxWarnings: -1, format: "text"})
+	if err == nil || !strings.Contains(err.Error(), "strict mode") {
+		t.Errorf("Expected strict mode error, got: %v", err)
+	}
+}
+
+func TestRunParseMaxWarnings(t *testing.T) {
+	_, err := runParseWithWarnings(t, warningFlags{maxWarnings: 1, format: "text"})
+	if err == nil || !strings.Contains(err.Error(), "more than the maximum of 1") {
+		t.Errorf("Expected max warnings error, got: %v", err)
+	}
+
+	_, err = runParseWithWarnings(t, warningFlags{maxWarnings: 100, format: "text"})
+	if err != nil {
+		t.Errorf("Expected no error below the limit, got: %v", err)
+	}
+}
+
+func TestRunParseWarningsJSON(t *testing.T) {
+	stderr, err := runParseWithWarnings(t, warningFlags{maxWarnings: -1, format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The format detection message precedes the JSON array
+	start := strings.Index(stderr, "[")
+	if start < 0 {
+		t.Fatalf("Expected JSON array on stderr, got: %s", stderr)
+	}
+	var warnings []map[string]interface{}
+	if err := json.Unmarshal([]byte(stderr[start:]), &warnings); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v\n%s", err, stderr)
+	}
+	if len(warnings) == 0 {
+		t.Fatal("Expected warnings in JSON output")
+	}
+	first := warnings[0]
+	if first["file"] != "../../testdata/malformed.lcov" || first["severity"] != "warning" || first["code"] == nil {
+		t.Errorf("Unexpected warning: %v", first)
+	}
+}
+
+func TestWarningFlagsCheckEmptyJSON(t *testing.T) {
+	var stderr bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetErr(&stderr)
+
+	flags := warningFlags{strict: true, maxWarnings: 0, format: "json"}
+	if err := flags.check(cmd, nil); err != nil {
+		t.Fatalf("Expected no error without warnings, got: %v", err)
+	}
+	if strings.TrimSpace(stderr.String()) != "[]" {
+		t.Errorf("Expected empty JSON array, got: %s", stderr.String())
+	}
+}
+
+func TestWarningFlagsValidate(t *testing.T) {
+	tests := []struct {
+		flags   warningFlags
+		wantErr bool
+	}{
+		{warningFlags{maxWarnings: -1, format: "text"}, false},
+		{warningFlags{maxWarnings: 0, format: "json"}, false},
+		{warningFlags{maxWarnings: -1, format: "xml"}, true},
+		{warningFlags{maxWarnings: -2, format: "text"}, true},
+	}
+
+	for _, test := range tests {
+		err := test.flags.validate()
+		if (err != nil) != test.wantErr {
+			t.Errorf("validate(%+v): expected error %v, got: %v", test.flags, test.wantErr, err)
+		}
+	}
+}
+
+func TestWarningFlagsCheckErrors(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetErr(&bytes.Buffer{})
+
+	warnings := []covpeek.Warning{{Path: "a.lcov"}, {Path: "b.lcov"}}
+	flags := warningFlags{maxWarnings: 2, format: "text"}
+	if err := flags.check(cmd, warnings); err != nil {
+		t.Errorf("Expected no error at the limit, got: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/covpeek"
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whoCoversFiles  []string
+	whoCoversFormat string
+	whoCoversOutput string
+)
+
+var whoCoversCmd = &cobra.Command{
+	Use:   "who-covers <file>:<line> [flags]",
+	Short: "List the tests that ran a line",
+	Long: `List the tests that ran a line of a source file, to choose the tests to run
+for a change or to find lines only a slow suite reaches.
+
+Tests are read from LCOV TN: sections and coverage.py contexts. Coverage files
+that do not record tests, such as the per-package profiles of go test, are
+attributed as a whole to their path, or to the archive member they came from,
+so pass one file per test suite. Without --file, the coverage files in standard locations
+are read.`,
+	Example: `  covpeek who-covers src/cart.py:42 --file coverage.json
+  covpeek who-covers pkg/api/handler.go:17 --file unit.out --file e2e.out
+  covpeek who-covers src/lib.rs:8 --file lcov.info --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWhoCovers,
+}
+
+func init() {
+	whoCoversCmd.Flags().StringArrayVarP(&whoCoversFiles, "file", "f", nil, "Path to a coverage file"+fileFlagStdin+", repeat for several test suites (auto-detect if not provided)")
+	whoCoversCmd.Flags().StringVar(&whoCoversFormat, "format", "", "Override format detection ("+strings.Join(parser.Names(), ", ")+")")
+	whoCoversCmd.Flags().StringVarP(&whoCoversOutput, "output", "o", "text", "Output format (text, json)")
+	registerPathFlags(whoCoversCmd)
+	rootCmd.AddCommand(whoCoversCmd)
+}
+
+// WhoCovers lists the tests that ran a line
+type WhoCovers struct {
+	File           string   `json:"file"`
+	Line           int      `json:"line"`
+	ExecutionCount int      `json:"execution_count"`
+	Tests          []string `json:"tests"`
+}
+
+func runWhoCovers(cmd *cobra.Command, args []string) error {
+	sourceFile, lineNum, err := parseLocation(args[0])
+	if err != nil {
+		return err
+	}
+	if whoCoversOutput != "text" && whoCoversOutput != "json" {
+		return fmt.Errorf("invalid output format '%s': must be one of: text, json", whoCoversOutput)
+	}
+	if whoCoversFormat != "" && parser.Lookup(whoCoversFormat) == nil {
+		return fmt.Errorf("invalid format '%s': must be one of: %s", whoCoversFormat, strings.Join(parser.Names(), ", "))
+	}
+
+	loader, err := newLoader(covpeek.Loader{Format: whoCoversFormat})
+	if err != nil {
+		return err
+	}
+	report, err := loadAttributedCoverage(cmd, loader)
+	if err != nil {
+		return err
+	}
+
+	file, err := findCoverageFile(report, sourceFile)
+	if err != nil {
+		return err
+	}
+	result, err := whoCovers(file, lineNum)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if whoCoversOutput == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	location := fmt.Sprintf("%s:%d", result.File, result.Line)
+	switch {
+	case result.ExecutionCount == 0:
+		_, _ = fmt.Fprintf(out, "%s is not covered by any test\n", location)
+	case len(result.Tests) == 0:
+		_, _ = fmt.Fprintf(out, "%s is covered, but the coverage data does not record which tests ran it\n", location)
+	case len(result.Tests) == 1:
+		_, _ = fmt.Fprintf(out, "%s is covered by 1 test:\n  %s\n", location, result.Tests[0])
+	default:
+		_, _ = fmt.Fprintf(out, "%s is covered by %d tests:\n", location, len(result.Tests))
+		for _, test := range result.Tests {
+			_, _ = fmt.Fprintf(out, "  %s\n", test)
+		}
+	}
+	return nil
+}
+
+// parseLocation splits a <file>:<line> argument
+func parseLocation(location string) (string, int, error) {
+	i := strings.LastIndex(location, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid location '%s': expected <file>:<line>", location)
+	}
+	lineNum, err := strconv.Atoi(location[i+1:])
+	if err != nil || lineNum <= 0 {
+		return "", 0, fmt.Errorf("invalid line number in '%s': expected <file>:<line>", location)
+	}
+	return location[:i], lineNum, nil
+}
+
+// loadAttributedCoverage loads and merges the coverage files given with
+// --file, or those in standard locations. The lines of a coverage file that
+// records no tests are attributed to the archive member they came from or to
+// the path of the file. The report's test name is not used, it joins the
+// names of several suites or only holds the first one.
+func loadAttributedCoverage(cmd *cobra.Command, loader *covpeek.Loader) (*models.CoverageReport, error) {
+	paths := whoCoversFiles
+	discovered := len(paths) == 0
+	if discovered {
+		paths = detectExistingCoverageFiles()
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no coverage files detected in standard locations. Please specify them with --file")
+		}
+	}
+
+	reports := make([]*models.CoverageReport, 0, len(paths))
+	for _, coverageFile := range paths {
+		result, err := loadCoverage(cmd, loader, coverageFile)
+		if err != nil {
+			// Explicitly named files must all load
+			if !discovered {
+				return nil, fmt.Errorf("failed to parse coverage file %s: %w", coverageFile, err)
+			}
+			cmd.PrintErrf("Warning: skipping %s: %v\n", coverageFile, err)
+			continue
+		}
+		printWarnings(cmd, result.Warnings)
+
+		if !result.Report.HasTests() {
+			name := coverageFile
+			if coverageFile == stdinPath {
+				name = "stdin"
+			}
+			for _, file := range result.Report.Files {
+				if file.Origin != "" {
+					file.AttributeTo(file.Origin)
+				} else {
+					file.AttributeTo(name)
+				}
+			}
+		}
+		reports = append(reports, result.Report)
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("no valid coverage files found")
+	}
+	return covpeek.Merge(reports...), nil
+}
+
+// findCoverageFile returns the coverage of a source file. Reports name files
+// by absolute, relative or module paths, so a file also matches when one of
+// the paths ends with the other.
+func findCoverageFile(report *models.CoverageReport, sourceFile string) (*models.FileCoverage, error) {
+	target := path.Clean(filepath.ToSlash(sourceFile))
+	if file := report.GetFile(sourceFile); file != nil {
+		return file, nil
+	}
+
+	var matches []string
+	for name := range report.Files {
+		candidate := path.Clean(filepath.ToSlash(name))
+		if candidate == target || strings.HasSuffix(candidate, "/"+target) || strings.HasSuffix(target, "/"+candidate) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no coverage data for %s", sourceFile)
+	case 1:
+		return report.GetFile(matches[0]), nil
+	default:
+		return nil, fmt.Errorf("%s matches several files, use a longer path: %s", sourceFile, strings.Join(matches, ", "))
+	}
+}
+
+// whoCovers returns the tests that ran a line. A line that is not executable
+// itself may declare a function, whose tests are returned instead.
+func whoCovers(file *models.FileCoverage, lineNum int) (*WhoCovers, error) {
+	result := &WhoCovers{File: file.FileName, Line: lineNum, Tests: []string{}}
+	if line, ok := file.Lines[lineNum]; ok {
+		result.ExecutionCount = line.ExecutionCount
+		result.Tests = append(result.Tests, line.Tests...)
+	} else {
+		found := false
+		for _, fn := range file.Functions {
+			if fn.LineNumber == lineNum {
+				found = true
+				result.ExecutionCount += fn.ExecutionCount
+				result.Tests = append(result.Tests, fn.Tests...)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("line %d of %s is not an executable line", lineNum, file.FileName)
+		}
+	}
+	sort.Strings(result.Tests)
+	result.Tests = slices.Compact(result.Tests)
+	return result, nil
+}
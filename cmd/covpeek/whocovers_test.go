@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func resetWhoCoversFlags() {
+	whoCoversFiles = nil
+	whoCoversFormat = ""
+	whoCoversOutput = "text"
+}
+
+func TestRunWhoCovers_LCOVSections(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	lcov := filepath.Join(t.TempDir(), "lcov.info")
+	content := `TN:unit
+SF:/build/src/lib.rs
+DA:1,1
+DA:2,0
+end_of_record
+TN:e2e
+SF:/build/src/lib.rs
+DA:1,3
+DA:2,1
+end_of_record
+`
+	if err := os.WriteFile(lcov, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	whoCoversFiles = []string{lcov}
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	// The relative path matches the absolute one in the report
+	if err := runWhoCovers(cmd, []string{"src/lib.rs:1"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	expected := "/build/src/lib.rs:1 is covered by 2 tests:\n  e2e\n  unit\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	// Line 2 is only reached by the e2e suite
+	out.Reset()
+	if err := runWhoCovers(cmd, []string{"src/lib.rs:2"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	if out.String() != "/build/src/lib.rs:2 is covered by 1 test:\n  e2e\n" {
+		t.Errorf("Expected line 2 to be covered by e2e, got %q", out.String())
+	}
+}
+
+func TestRunWhoCovers_AttributesFiles(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	// Per-package Go profiles record no tests, each is attributed to its path
+	dir := t.TempDir()
+	unit := filepath.Join(dir, "unit.out")
+	e2e := filepath.Join(dir, "e2e.out")
+	if err := os.WriteFile(unit, []byte("mode: set\nexample.com/shop/cart.go:3.20,5.2 1 1\nexample.com/shop/cart.go:7.20,9.2 1 0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(e2e, []byte("mode: set\nexample.com/shop/cart.go:3.20,5.2 1 1\nexample.com/shop/cart.go:7.20,9.2 1 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	whoCoversFiles = []string{unit, e2e}
+	whoCoversOutput = "json"
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runWhoCovers(cmd, []string{"shop/cart.go:8"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+
+	var result WhoCovers
+	if err := json.Unmarshal([]byte(out.String()), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+	if result.File != "example.com/shop/cart.go" || result.Line != 8 || result.ExecutionCount != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Tests) != 1 || result.Tests[0] != e2e {
+		t.Errorf("Expected line 8 to be covered by %s, got %v", e2e, result.Tests)
+	}
+}
+
+func TestRunWhoCovers_GoProfiles(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	// One profile per suite, both reaching the first block
+	dir := t.TempDir()
+	unit := filepath.Join(dir, "unit.out")
+	e2e := filepath.Join(dir, "e2e.out")
+	if err := os.WriteFile(unit, []byte("mode: count\nexample.com/shop/cart.go:3.20,5.2 1 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(e2e, []byte("mode: count\nexample.com/shop/cart.go:3.20,5.2 1 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	whoCoversFiles = []string{unit, e2e}
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runWhoCovers(cmd, []string{"example.com/shop/cart.go:4"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	expected := "example.com/shop/cart.go:4 is covered by 2 tests:\n  " + e2e + "\n  " + unit + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRunWhoCovers_JoinedTestName(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	// SimpleCov joins the names of the suites it merged
+	resultset := filepath.Join(t.TempDir(), ".resultset.json")
+	content := `{"RSpec, Minitest": {"coverage": {"lib/cart.rb": {"lines": [1, null, 0]}}, "timestamp": 1}}`
+	if err := os.WriteFile(resultset, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	whoCoversFiles = []string{resultset}
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runWhoCovers(cmd, []string{"lib/cart.rb:1"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	if expected := "lib/cart.rb:1 is covered by 1 test:\n  " + resultset + "\n"; out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRunWhoCovers_Stdin(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	coverage := `{"files": {"shop/cart.py": {"executed_lines": [1, 2], "missing_lines": [3],
+		"contexts": {"1": [""], "2": ["tests/test_cart.py::test_total|run"]}}}}`
+	whoCoversFiles = []string{"-"}
+
+	var out strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(coverage))
+	cmd.SetOut(&out)
+	if err := runWhoCovers(cmd, []string{"shop/cart.py:3"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	if out.String() != "shop/cart.py:3 is not covered by any test\n" {
+		t.Errorf("Expected line 3 not to be covered, got %q", out.String())
+	}
+
+	// Line 1 only ran while the module was imported
+	out.Reset()
+	cmd.SetIn(strings.NewReader(coverage))
+	if err := runWhoCovers(cmd, []string{"shop/cart.py:1"}); err != nil {
+		t.Fatalf("runWhoCovers failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "does not record which tests ran it") {
+		t.Errorf("Expected line 1 to have no tests, got %q", out.String())
+	}
+}
+
+func TestRunWhoCovers_Errors(t *testing.T) {
+	defer resetWhoCoversFlags()
+
+	whoCoversFiles = []string{"../../testdata/sample.out"}
+
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{"calculator.go", "expected <file>:<line>"},
+		{"calculator.go:0", "invalid line number"},
+		{"missing.go:1", "no coverage data for missing.go"},
+		{"calculator.go:1", "not an executable line"},
+	}
+	for _, tt := range tests {
+		err := runWhoCovers(&cobra.Command{}, []string{tt.location})
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.location, tt.expected, err)
+		}
+	}
+}
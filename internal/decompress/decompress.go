@@ -0,0 +1,44 @@
+// Package decompress unpacks the gzip, zstd and bzip2 streams coverage files
+// are stored in, e.g. the .gcov.json.gz files of gcov or a compressed CI
+// artifact.
+package decompress
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/Chapati-Systems/covpeek/internal/zstd"
+)
+
+// Magic numbers of the compressed streams NewReader unpacks
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+)
+
+// HeaderSize is the number of bytes NewReader needs to recognize a stream
+const HeaderSize = 4
+
+// NewReader returns a reader decompressing gzip, zstd or bzip2 data, chosen by
+// the magic number at the start of the header, which r also starts with. It
+// returns nil if the data is not compressed.
+func NewReader(header []byte, r io.Reader) (io.ReadCloser, error) {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return gz, nil
+	case bytes.HasPrefix(header, zstdMagic):
+		return io.NopCloser(zstd.NewReader(r)), nil
+	case bytes.HasPrefix(header, bzip2Magic) && len(header) > len(bzip2Magic) &&
+		header[len(bzip2Magic)] >= '1' && header[len(bzip2Magic)] <= '9':
+		// The magic is followed by the block size, "BZh9" for bzip2 -9
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	}
+	return nil, nil
+}
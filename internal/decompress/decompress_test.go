@@ -0,0 +1,44 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestNewReader_Gzip(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte("TN:\nSF:src/lib.rs\n"))
+	_ = gz.Close()
+
+	r, err := NewReader(compressed.Bytes()[:HeaderSize], &compressed)
+	if err != nil || r == nil {
+		t.Fatalf("Expected a gzip reader, got %v, %v", r, err)
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if string(data) != "TN:\nSF:src/lib.rs\n" {
+		t.Errorf("Unexpected data %q", data)
+	}
+}
+
+func TestNewReader_NotCompressed(t *testing.T) {
+	for _, header := range []string{"TN:\nSF:", "BZh", "BZhx", ""} {
+		r, err := NewReader([]byte(header), bytes.NewReader([]byte(header)))
+		if r != nil || err != nil {
+			t.Errorf("NewReader(%q): expected nil, got %v, %v", header, r, err)
+		}
+	}
+}
+
+func TestNewReader_InvalidGzip(t *testing.T) {
+	data := []byte{0x1f, 0x8b, 0x00}
+	if _, err := NewReader(data, bytes.NewReader(data)); err == nil {
+		t.Error("Expected error for truncated gzip data")
+	}
+}
@@ -1,150 +1,117 @@
 package detector
 
 import (
-	"bufio"
 	"io"
-	"strings"
+	"io/fs"
+
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
 )
 
-// CoverageFormat represents the detected coverage file format
-type CoverageFormat int
+// CoverageFormat represents the detected coverage file format. Its value is
+// the canonical name of the format in the parser registry.
+type CoverageFormat string
 
 const (
 	// UnknownFormat indicates the format could not be determined
-	UnknownFormat CoverageFormat = iota
+	UnknownFormat CoverageFormat = ""
 	// LCOVFormat indicates LCOV format (Rust, TypeScript, JavaScript)
-	LCOVFormat
+	LCOVFormat CoverageFormat = "lcov"
 	// GoCoverFormat indicates Go coverage format (.out)
-	GoCoverFormat
-	// PyCoverXMLFormat indicates Python coverage XML format (Cobertura-compatible)
-	PyCoverXMLFormat
+	GoCoverFormat CoverageFormat = "go"
+	// CoberturaXMLFormat indicates Cobertura XML format (Python, C/C++, .NET, PHP, JavaScript)
+	CoberturaXMLFormat CoverageFormat = "cobertura"
 	// PyCoverJSONFormat indicates Python coverage JSON format
-	PyCoverJSONFormat
+	PyCoverJSONFormat CoverageFormat = "pyjson"
+	// IstanbulJSONFormat indicates Istanbul's native coverage-final.json format (TypeScript, JavaScript)
+	IstanbulJSONFormat CoverageFormat = "istanbul"
+	// JaCoCoXMLFormat indicates JaCoCo XML format (Java, Kotlin)
+	JaCoCoXMLFormat CoverageFormat = "jacoco"
+	// LLVMJSONFormat indicates llvm-cov export JSON (Rust, C, C++)
+	LLVMJSONFormat CoverageFormat = "llvm"
+	// GcovJSONFormat indicates gcov's JSON intermediate format (C, C++)
+	GcovJSONFormat CoverageFormat = "gcov"
+	// GcovrJSONFormat indicates gcovr's JSON report (C, C++)
+	GcovrJSONFormat CoverageFormat = "gcovr"
+	// CloverXMLFormat indicates Clover XML format (PHP, JavaScript)
+	CloverXMLFormat CoverageFormat = "clover"
+	// OpenCoverXMLFormat indicates OpenCover XML (.NET)
+	OpenCoverXMLFormat CoverageFormat = "opencover"
+	// CoverletJSONFormat indicates Coverlet's native JSON format (.NET)
+	CoverletJSONFormat CoverageFormat = "coverlet"
+	// SimpleCovJSONFormat indicates SimpleCov's .resultset.json (Ruby)
+	SimpleCovJSONFormat CoverageFormat = "simplecov"
+	// GoCoverDirFormat indicates a directory of Go binary coverage data (GOCOVERDIR)
+	GoCoverDirFormat CoverageFormat = "gocoverdir"
 )
 
+// maxHeaderSize is the byte budget of the format sniffers. It covers long
+// license comments in front of an XML root and minified JSON alike.
+const maxHeaderSize = 64 * 1024
+
+// Candidate is a format recognizing the content of a coverage file
+type Candidate struct {
+	Format     CoverageFormat
+	Confidence parser.Confidence
+}
+
 // String returns the string representation of the coverage format
 func (f CoverageFormat) String() string {
-	switch f {
-	case LCOVFormat:
-		return "LCOV"
-	case GoCoverFormat:
-		return "Go Coverage"
-	case PyCoverXMLFormat:
-		return "Python XML Coverage"
-	case PyCoverJSONFormat:
-		return "Python JSON Coverage"
-	default:
-		return "Unknown"
+	if p := f.Parser(); p != nil {
+		return p.Description()
 	}
+	return "Unknown"
 }
 
-// DetectFormat attempts to detect the coverage file format by examining the file content
-func DetectFormat(reader io.Reader) (CoverageFormat, error) {
-	scanner := bufio.NewScanner(reader)
-
-	// Read first few lines to determine format
-	lineCount := 0
-	maxLinesToCheck := 10
-
-	hasLCOVMarkers := false
-	hasGoMarkers := false
-	hasXMLMarkers := false
-	hasJSONMarkers := false
-
-	for scanner.Scan() && lineCount < maxLinesToCheck {
-		line := strings.TrimSpace(scanner.Text())
-
-		lineCount++
-
-		// Skip empty lines
-		if line == "" {
-			continue
-		}
-
-		// Check for Go coverage format markers
-		// Go coverage files start with "mode: set|count|atomic"
-		if lineCount == 1 && strings.HasPrefix(line, "mode:") {
-			parts := strings.Fields(line)
-			if len(parts) == 2 {
-				mode := parts[1]
-				if mode == "set" || mode == "count" || mode == "atomic" {
-					hasGoMarkers = true
-				}
-			}
-		}
-
-		// Check for XML coverage format markers
-		if strings.Contains(line, "<coverage") || strings.Contains(line, "<class filename=") {
-			hasXMLMarkers = true
-		}
-
-		// Check for JSON coverage format markers
-		if strings.Contains(line, `"files"`) || strings.Contains(line, `"executed_lines"`) {
-			hasJSONMarkers = true
-		}
-
-		// Check for LCOV format markers
-		if strings.HasPrefix(line, "TN:") ||
-			strings.HasPrefix(line, "SF:") ||
-			strings.HasPrefix(line, "FN:") ||
-			strings.HasPrefix(line, "FNDA:") ||
-			strings.HasPrefix(line, "DA:") ||
-			strings.HasPrefix(line, "LH:") ||
-			strings.HasPrefix(line, "LF:") ||
-			line == "end_of_record" {
-			hasLCOVMarkers = true
-		}
+// Parser returns a new parser for the format, or nil for unknown formats
+func (f CoverageFormat) Parser() parser.Parser {
+	if f == UnknownFormat {
+		return nil
 	}
+	return parser.Lookup(string(f))
+}
 
-	if err := scanner.Err(); err != nil {
+// DetectFormat attempts to detect the coverage file format by examining the
+// file content. It returns the most confident of the candidates DetectAll finds.
+func DetectFormat(reader io.Reader) (CoverageFormat, error) {
+	candidates, err := DetectAll(reader)
+	if err != nil || len(candidates) == 0 {
 		return UnknownFormat, err
 	}
+	return candidates[0].Format, nil
+}
 
-	// Determine format based on markers found
-	if hasGoMarkers {
-		return GoCoverFormat, nil
-	}
-
-	if hasXMLMarkers {
-		return PyCoverXMLFormat, nil
-	}
-
-	if hasJSONMarkers {
-		return PyCoverJSONFormat, nil
+// DetectAll returns every format recognizing the first maxHeaderSize bytes of
+// the content, the most confident first. Several candidates with the top
+// confidence mean the content is ambiguous.
+func DetectAll(reader io.Reader) ([]Candidate, error) {
+	header, err := io.ReadAll(io.LimitReader(reader, maxHeaderSize))
+	if err != nil {
+		return nil, err
 	}
 
-	if hasLCOVMarkers {
-		return LCOVFormat, nil
+	var candidates []Candidate
+	for _, match := range parser.MatchContent(header) {
+		candidates = append(candidates, Candidate{
+			Format:     CoverageFormat(match.Parser.Name()),
+			Confidence: match.Confidence,
+		})
 	}
-
-	return UnknownFormat, nil
+	return candidates, nil
 }
 
 // DetectFormatByExtension attempts to detect format based on file extension
 func DetectFormatByExtension(filename string) CoverageFormat {
-	filename = strings.ToLower(filename)
-
-	// Go coverage files
-	if strings.HasSuffix(filename, ".out") {
-		return GoCoverFormat
-	}
-
-	// Python XML coverage files
-	if strings.HasSuffix(filename, ".xml") && strings.Contains(filename, "coverage") {
-		return PyCoverXMLFormat
-	}
-
-	// Python JSON coverage files
-	if strings.HasSuffix(filename, ".json") && strings.Contains(filename, "coverage") {
-		return PyCoverJSONFormat
+	if p := parser.ForFilename(filename); p != nil {
+		return CoverageFormat(p.Name())
 	}
+	return UnknownFormat
+}
 
-	// LCOV format files
-	if strings.HasSuffix(filename, ".lcov") ||
-		strings.HasSuffix(filename, ".info") ||
-		strings.Contains(filename, "lcov.info") {
-		return LCOVFormat
+// DetectDirFormat attempts to detect the format of coverage data written as a
+// directory of files, such as a GOCOVERDIR
+func DetectDirFormat(fsys fs.FS) CoverageFormat {
+	if p := parser.ForDirectory(fsys); p != nil {
+		return CoverageFormat(p.Name())
 	}
-
 	return UnknownFormat
 }
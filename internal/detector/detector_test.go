@@ -1,8 +1,11 @@
 package detector
 
 import (
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
 )
 
 func TestDetectFormat_LCOV(t *testing.T) {
@@ -101,17 +104,19 @@ func TestDetectFormatByExtension_Go(t *testing.T) {
 	}
 }
 
-func TestDetectFormatByExtension_PyXML(t *testing.T) {
+func TestDetectFormatByExtension_CoberturaXML(t *testing.T) {
 	tests := []string{
 		"coverage.xml",
 		"test/coverage.xml",
 		"COVERAGE.XML", // Test case insensitive
+		"cobertura.xml",
+		"coverage/cobertura-coverage.xml",
 	}
 
 	for _, filename := range tests {
 		format := DetectFormatByExtension(filename)
-		if format != PyCoverXMLFormat {
-			t.Errorf("Expected PyCoverXMLFormat for %s, got: %s", filename, format)
+		if format != CoberturaXMLFormat {
+			t.Errorf("Expected CoberturaXMLFormat for %s, got: %s", filename, format)
 		}
 	}
 }
@@ -138,8 +143,18 @@ func TestCoverageFormat_String(t *testing.T) {
 	}{
 		{LCOVFormat, "LCOV"},
 		{GoCoverFormat, "Go Coverage"},
-		{PyCoverXMLFormat, "Python XML Coverage"},
+		{CoberturaXMLFormat, "Cobertura XML Coverage"},
 		{PyCoverJSONFormat, "Python JSON Coverage"},
+		{IstanbulJSONFormat, "Istanbul JSON Coverage"},
+		{JaCoCoXMLFormat, "JaCoCo XML Coverage"},
+		{LLVMJSONFormat, "LLVM Coverage JSON"},
+		{GcovJSONFormat, "gcov JSON Coverage"},
+		{GcovrJSONFormat, "gcovr JSON Coverage"},
+		{CloverXMLFormat, "Clover XML Coverage"},
+		{OpenCoverXMLFormat, "OpenCover XML Coverage"},
+		{CoverletJSONFormat, "Coverlet JSON Coverage"},
+		{SimpleCovJSONFormat, "SimpleCov JSON Coverage"},
+		{GoCoverDirFormat, "Go Coverage Directory (GOCOVERDIR)"},
 		{UnknownFormat, "Unknown"},
 	}
 
@@ -195,7 +210,7 @@ func TestDetectFormat_PyCoverJSON(t *testing.T) {
 	}
 }
 
-func TestDetectFormat_PyCoverXML(t *testing.T) {
+func TestDetectFormat_CoberturaXML(t *testing.T) {
 	input := `<?xml version="1.0" encoding="UTF-8"?>
 <coverage version="5.0">
   <sources>
@@ -221,7 +236,190 @@ func TestDetectFormat_PyCoverXML(t *testing.T) {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if format != PyCoverXMLFormat {
-		t.Errorf("Expected PyCoverXMLFormat, got: %s", format)
+	if format != CoberturaXMLFormat {
+		t.Errorf("Expected CoberturaXMLFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormat_CloverXML(t *testing.T) {
+	// Clover's root element has the same name as Cobertura's
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage generated="1760601600" clover="3.2.0">
+  <project timestamp="1760601600" name="All files">
+    <file name="cart.js" path="/app/src/cart.js">
+      <line num="1" count="1" type="stmt"/>
+    </file>
+  </project>
+</coverage>`
+
+	format, err := DetectFormat(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if format != CloverXMLFormat {
+		t.Errorf("Expected CloverXMLFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormat_IstanbulJSON(t *testing.T) {
+	input := `{"/app/src/index.ts":{"path":"/app/src/index.ts","statementMap":{},"fnMap":{},"branchMap":{},"s":{},"f":{},"b":{}}}`
+
+	format, err := DetectFormat(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if format != IstanbulJSONFormat {
+		t.Errorf("Expected IstanbulJSONFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormatByExtension_IstanbulJSON(t *testing.T) {
+	tests := []string{
+		"coverage-final.json",
+		"coverage/coverage-final.json",
+		"COVERAGE/COVERAGE-FINAL.JSON", // Test case insensitive
+	}
+
+	for _, filename := range tests {
+		format := DetectFormatByExtension(filename)
+		if format != IstanbulJSONFormat {
+			t.Errorf("Expected IstanbulJSONFormat for %s, got: %s", filename, format)
+		}
+	}
+}
+
+func TestDetectFormat_JaCoCoXML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd"><report name="app"><sessioninfo id="a" start="1" dump="2"/></report>`
+
+	format, err := DetectFormat(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if format != JaCoCoXMLFormat {
+		t.Errorf("Expected JaCoCoXMLFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormatByExtension_JaCoCoXML(t *testing.T) {
+	tests := []string{
+		"jacoco.xml",
+		"target/site/jacoco/jacoco.xml",
+		"build/reports/jacoco/test/jacocoTestReport.xml",
+	}
+
+	for _, filename := range tests {
+		format := DetectFormatByExtension(filename)
+		if format != JaCoCoXMLFormat {
+			t.Errorf("Expected JaCoCoXMLFormat for %s, got: %s", filename, format)
+		}
+	}
+}
+
+func TestDetectDirFormat(t *testing.T) {
+	if format := DetectDirFormat(os.DirFS("../../testdata/gocoverdir")); format != GoCoverDirFormat {
+		t.Errorf("Expected GoCoverDirFormat, got: %s", format)
+	}
+	if format := DetectDirFormat(os.DirFS("../../testdata")); format != UnknownFormat {
+		t.Errorf("Expected UnknownFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormat_LongLine(t *testing.T) {
+	// llvm-cov writes its export as a single line of any length
+	input := `{"data":[{"files":[{"filename":"` + strings.Repeat("a", 2*maxHeaderSize) + `"}]}],"type":"llvm.coverage.json.export"}`
+
+	format, err := DetectFormat(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if format != LLVMJSONFormat {
+		t.Errorf("Expected LLVMJSONFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormat_BeyondTenLines(t *testing.T) {
+	license := "<!--\n" + strings.Repeat("  Licensed under the Apache License, Version 2.0\n", 30) + "-->\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected CoverageFormat
+	}{
+		{"Cobertura after a license comment", `<?xml version="1.0"?>` + "\n" + license + `<coverage line-rate="0.5"><packages/></coverage>`, CoberturaXMLFormat},
+		{"JaCoCo after a license comment", `<?xml version="1.0"?>` + "\n" + license + `<report name="app"><sessioninfo id="a"/></report>`, JaCoCoXMLFormat},
+		{"LCOV after blank lines", strings.Repeat("\n", 12) + "SF:src/lib.rs\nDA:1,1\nend_of_record\n", LCOVFormat},
+		{"minified Istanbul", `{"/app/a.js":{"path":"/app/a.js","statementMap":{"0":{"start":{"line":1,"column":0},"end":{"line":1,"column":9}}},"fnMap":{},"branchMap":{},"s":{"0":1},"f":{},"b":{}}}`, IstanbulJSONFormat},
+		{"minified coverage.py", `{"meta":{"format":3,"version":"7.4.0"},"files":{"a.py":{"executed_lines":[1],"summary":{},"missing_lines":[]}}}`, PyCoverJSONFormat},
+	}
+
+	for _, test := range tests {
+		format, err := DetectFormat(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.name, err)
+		}
+		if format != test.expected {
+			t.Errorf("%s: expected %s, got: %s", test.name, test.expected, format)
+		}
+	}
+}
+
+func TestDetectFormat_NotCoverageJSON(t *testing.T) {
+	// A "files" key alone is no reason to call a document coverage.py
+	format, err := DetectFormat(strings.NewReader(`{"files": {"a.py": {"size": 10}}}`))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if format != UnknownFormat {
+		t.Errorf("Expected UnknownFormat, got: %s", format)
+	}
+}
+
+func TestDetectFormat_PackageJSON(t *testing.T) {
+	// A coverage script is not SimpleCov's coverage of files
+	input := `{"name": "app", "scripts": {"test": "jest", "coverage": "jest --coverage"}}`
+
+	format, err := DetectFormat(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if format != UnknownFormat {
+		t.Errorf("Expected UnknownFormat, got: %s", format)
+	}
+}
+
+func TestDetectAll(t *testing.T) {
+	// Cobertura and Clover share the <coverage> root, the attributes decide
+	input := `<?xml version="1.0"?>
+<coverage line-rate="0.5" branch-rate="0">
+  <sources><source>/src</source></sources>
+</coverage>`
+
+	candidates, err := DetectAll(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].Format != CoberturaXMLFormat || candidates[0].Confidence != parser.ConfidenceHigh {
+		t.Errorf("Expected a single confident Cobertura candidate, got: %+v", candidates)
+	}
+
+	// Content matching several formats loosely yields all of them
+	candidates, err = DetectAll(strings.NewReader("<report>\nSF:foo\n</report>\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Confidence != candidates[1].Confidence {
+		t.Errorf("Expected two equally confident candidates, got: %+v", candidates)
 	}
 }
@@ -0,0 +1,184 @@
+package covpeek
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+)
+
+// Magic numbers of the archived inputs LoadReader unpacks
+var (
+	zipMagic = []byte("PK\x03\x04")
+	// tarMagic is found at tarMagicOffset, in the header of the first member
+	tarMagic = []byte("ustar")
+)
+
+const tarMagicOffset = 257
+
+// compressionSuffixes are stripped from a file name before detecting its format by name
+var compressionSuffixes = []string{".gz", ".zst", ".bz2"}
+
+// isZip reports whether the header starts a zip archive
+func isZip(header []byte) bool {
+	return bytes.HasPrefix(header, zipMagic)
+}
+
+// isTar reports whether the header starts a POSIX or GNU tar archive
+func isTar(header []byte) bool {
+	return len(header) >= tarMagicOffset+len(tarMagic) &&
+		bytes.Equal(header[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic)
+}
+
+// memberPath names a member of an archive, e.g. "artifacts.zip!backend/lcov.info"
+func memberPath(archive, name string) string {
+	if archive == "" {
+		return name
+	}
+	return archive + "!" + name
+}
+
+// loadZip loads the members of a zip archive. The central directory of a zip
+// archive is at its end, so an archive is read from the file it is stored in
+// when there is one, and into memory from r otherwise.
+func (l *Loader) loadZip(r io.Reader, file *os.File, hint string) (*Result, error) {
+	var (
+		readerAt io.ReaderAt
+		size     int64
+	)
+	if file != nil {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip archive: %w", err)
+		}
+		readerAt, size = file, info.Size()
+	} else {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip archive: %w", err)
+		}
+		readerAt, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	members := &collector{}
+	for _, member := range archive.File {
+		if !member.Mode().IsRegular() {
+			continue
+		}
+		content, err := member.Open()
+		if err != nil {
+			members.fail(memberPath(hint, member.Name), err)
+			continue
+		}
+		l.loadMember(members, content, memberPath(hint, member.Name))
+		_ = content.Close()
+	}
+	return members.archiveResult(hint)
+}
+
+// loadTar loads the members of a tar archive, one at a time as they are read
+func (l *Loader) loadTar(r io.Reader, hint string) (*Result, error) {
+	archive := tar.NewReader(r)
+
+	members := &collector{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		l.loadMember(members, archive, memberPath(hint, header.Name))
+	}
+	return members.archiveResult(hint)
+}
+
+// loadMember loads one member of an archive. Members that are not coverage
+// data, such as READMEs or HTML reports next to the coverage files, are
+// skipped quietly. Members that fail to load are skipped with a warning.
+func (l *Loader) loadMember(members *collector, r io.Reader, path string) {
+	loaded, err := l.LoadReader(r, path)
+	var formatErr *FormatError
+	if errors.As(err, &formatErr) && formatErr.Format == "" && len(formatErr.Candidates) == 0 ||
+		errors.Is(err, ErrNoCoverage) {
+		return
+	}
+	if err != nil {
+		members.fail(path, err)
+		return
+	}
+
+	// Members of nested archives already name their innermost archive
+	for _, file := range loaded.Report.Files {
+		if file.Origin == "" {
+			file.Origin = path
+		}
+	}
+	members.add(loaded)
+}
+
+// collector merges the results of loading several files
+type collector struct {
+	result  Result
+	reports []*models.CoverageReport
+}
+
+// add adds a loaded file to the merged result
+func (c *collector) add(loaded *Result) {
+	if len(c.reports) == 0 {
+		c.result.Format = loaded.Format
+	} else if c.result.Format != loaded.Format {
+		c.result.Format = ""
+	}
+	c.reports = append(c.reports, loaded.Report)
+	c.result.Paths = append(c.result.Paths, loaded.Paths...)
+	c.result.Warnings = append(c.result.Warnings, loaded.Warnings...)
+}
+
+// fail records a file that could not be loaded as a warning
+func (c *collector) fail(path string, err error) {
+	c.result.Warnings = append(c.result.Warnings, Warning{
+		Path: path,
+		Diagnostic: parser.Diagnostic{
+			Severity: parser.SeverityError,
+			Code:     CodeLoadFailed,
+			Message:  err.Error(),
+		},
+		Err: err,
+	})
+}
+
+// merge returns the merged result, or the result holding the warnings with
+// ErrNoCoverage if nothing was loaded
+func (c *collector) merge() (*Result, error) {
+	if len(c.reports) == 0 {
+		return &c.result, ErrNoCoverage
+	}
+	c.result.Report = Merge(c.reports...)
+	return &c.result, nil
+}
+
+// archiveResult is merge for the members of an archive, naming the archive
+// when it holds no coverage
+func (c *collector) archiveResult(archive string) (*Result, error) {
+	result, err := c.merge()
+	if err != nil && archive != "" {
+		return result, fmt.Errorf("%w in archive %s", err, archive)
+	}
+	return result, err
+}
@@ -0,0 +1,172 @@
+package covpeek
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveMembers are the files written to the test archives
+var archiveMembers = []struct {
+	name string
+	path string
+}{
+	{"rust/lcov.info", "../../testdata/sample.lcov"},
+	{"go/coverage.out", "../../testdata/sample.out"},
+	{"README.md", ""},
+}
+
+// memberContent returns the content of a test archive member
+func memberContent(t *testing.T, path string) []byte {
+	t.Helper()
+	if path == "" {
+		return []byte("# Coverage artifacts\n")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	return data
+}
+
+func writeZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, member := range archiveMembers {
+		w, err := archive.Create(member.name)
+		if err != nil {
+			t.Fatalf("Failed to write zip: %v", err)
+		}
+		_, _ = w.Write(memberContent(t, member.path))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeTarGz(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	_ = archive.WriteHeader(&tar.Header{Name: "rust/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for _, member := range archiveMembers {
+		data := memberContent(t, member.path)
+		if err := archive.WriteHeader(&tar.Header{Name: member.name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("Failed to write tar: %v", err)
+		}
+		_, _ = archive.Write(data)
+	}
+	_ = archive.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// checkArchiveResult checks the report merged from the test archive members
+func checkArchiveResult(t *testing.T, result *Result, archive string) {
+	t.Helper()
+	if result.Format != "" {
+		t.Errorf("Expected no format for members of different formats, got: %s", result.Format)
+	}
+	expectedPaths := []string{archive + "!rust/lcov.info", archive + "!go/coverage.out"}
+	if len(result.Paths) != 2 || result.Paths[0] != expectedPaths[0] || result.Paths[1] != expectedPaths[1] {
+		t.Errorf("Expected paths %v, got: %v", expectedPaths, result.Paths)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected the README to be skipped quietly, got: %v", result.Warnings)
+	}
+
+	lib := result.Report.GetFile("src/lib.rs")
+	if lib == nil || lib.Origin != archive+"!rust/lcov.info" {
+		t.Errorf("Expected src/lib.rs from the LCOV member, got: %+v", lib)
+	}
+	calc := result.Report.GetFile("git.kernel.fun/myproject/pkg/calculator/calculator.go")
+	if calc == nil || calc.Origin != archive+"!go/coverage.out" {
+		t.Errorf("Expected calculator.go from the Go member, got: %+v", calc)
+	}
+}
+
+func TestLoad_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.zip")
+	if err := os.WriteFile(path, writeZip(t), 0o644); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+
+	// Read from the file
+	result, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	checkArchiveResult(t, result, path)
+
+	// Read into memory from a stream
+	result, err = LoadReader(bytes.NewReader(writeZip(t)), "artifacts.zip")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	checkArchiveResult(t, result, "artifacts.zip")
+}
+
+func TestLoadReader_TarGz(t *testing.T) {
+	result, err := LoadReader(bytes.NewReader(writeTarGz(t)), "coverage.tar.gz")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	checkArchiveResult(t, result, "coverage.tar.gz")
+}
+
+func TestLoadReader_ArchiveMemberFails(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create("lcov.info")
+	_, _ = w.Write(memberContent(t, "../../testdata/sample.lcov"))
+	w, _ = archive.Create("broken/coverage.xml")
+	_, _ = w.Write([]byte("<coverage line-rate=\"1\"><packages>"))
+	_ = archive.Close()
+
+	result, err := LoadReader(&buf, "artifacts.zip")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "lcov" {
+		t.Errorf("Expected format lcov, got: %s", result.Format)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Path != "artifacts.zip!broken/coverage.xml" || result.Warnings[0].Code != CodeLoadFailed {
+		t.Errorf("Expected a warning about the broken member, got: %v", result.Warnings)
+	}
+}
+
+func TestLoadReader_ArchiveWithoutCoverage(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create("README.md")
+	_, _ = w.Write([]byte("nothing to see\n"))
+	_ = archive.Close()
+
+	_, err := LoadReader(&buf, "artifacts.zip")
+	if !errors.Is(err, ErrNoCoverage) {
+		t.Errorf("Expected ErrNoCoverage, got: %v", err)
+	}
+}
+
+func TestLoad_Compressed(t *testing.T) {
+	for _, path := range []string{"../../testdata/sample.lcov.zst", "../../testdata/sample.lcov.bz2"} {
+		result, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", path, err)
+		}
+		if result.Format != "lcov" || result.Report.GetFile("src/lib.rs") == nil {
+			t.Errorf("%s: expected LCOV report, got format %s with %d files", path, result.Format, len(result.Report.Files))
+		}
+		if file := result.Report.GetFile("src/lib.rs"); file != nil && file.Origin != "" {
+			t.Errorf("%s: expected no origin outside archives, got: %s", path, file.Origin)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Package covpeek loads coverage reports of any supported format. It detects
+// the format of a file, parses it and merges reports from several files, the
+// same way the covpeek command line tool does.
+package covpeek
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/internal/decompress"
+	"github.com/Chapati-Systems/covpeek/internal/detector"
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+)
+
+// Result is the outcome of loading one or more coverage files
+type Result struct {
+	// Report holds the parsed coverage data, merged when several files were loaded
+	Report *models.CoverageReport
+	// Format is the canonical name of the format, empty if files of different formats were merged
+	Format string
+	// Paths lists the files the report was loaded from
+	Paths []string
+	// Warnings lists the non-fatal problems found while loading
+	Warnings []Warning
+}
+
+// detectHeaderSize is how much of a file is read ahead for format detection
+const detectHeaderSize = 64 * 1024
+
+// CodeLoadFailed is the diagnostic code of a file LoadAll skipped
+const CodeLoadFailed = "load-failed"
+
+// Warning is a diagnostic found while loading coverage, together with the file it belongs to
+type Warning struct {
+	// Path is the file the warning is about, if any
+	Path string `json:"file,omitempty"`
+	parser.Diagnostic
+	// Err is set when LoadAll skipped the file because it could not be loaded
+	Err error `json:"-"`
+}
+
+func (w Warning) String() string {
+	if w.Path == "" {
+		return w.Diagnostic.String()
+	}
+	return w.Path + ": " + w.Diagnostic.String()
+}
+
+// Loader loads coverage files. The zero value detects formats automatically.
+type Loader struct {
+	// Format forces a format by name or alias instead of detecting it
+	Format string
+	// Metric overrides the metric the format reports coverage in, e.g. lines
+	// instead of statements for Go profiles
+	Metric models.Metric
+	// SourceDir is a directory inside the Go module a Go profile was written
+	// for. When set, function coverage is read from the module's source.
+	SourceDir string
+	// PathMapper rewrites the file names of loaded reports when set, see
+	// PathMapper for the rules
+	PathMapper *PathMapper
+}
+
+// defaultLoader is used by the package level functions
+var defaultLoader = &Loader{}
+
+// Load reads and parses a coverage file, detecting its format
+func Load(path string) (*Result, error) {
+	return defaultLoader.Load(path)
+}
+
+// LoadReader parses coverage data from a reader. The hint is the file name the
+// data came from and is used for detection by name, it may be empty.
+func LoadReader(r io.Reader, hint string) (*Result, error) {
+	return defaultLoader.LoadReader(r, hint)
+}
+
+// LoadAll loads several coverage files and merges them into one report
+func LoadAll(paths []string) (*Result, error) {
+	return defaultLoader.LoadAll(paths)
+}
+
+// Load reads and parses a coverage file. A directory is loaded with LoadDir.
+func (l *Loader) Load(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		return l.LoadDir(path)
+	}
+	return l.LoadReader(file, path)
+}
+
+// LoadDir reads and parses coverage data written as a directory of files,
+// such as the GOCOVERDIR of a Go program built with -cover
+func (l *Loader) LoadDir(path string) (*Result, error) {
+	fsys := os.DirFS(path)
+
+	var p parser.DirParser
+	if l.Format != "" {
+		forced, ok := parser.Lookup(l.Format).(parser.DirParser)
+		if !ok {
+			return nil, &FormatError{Path: path, Format: l.Format, Dir: true}
+		}
+		p = forced
+	} else {
+		format := detector.DetectDirFormat(fsys)
+		if format == detector.UnknownFormat {
+			return nil, &FormatError{Path: path, Dir: true}
+		}
+		p = format.Parser().(parser.DirParser)
+	}
+
+	report, err := p.ParseDir(fsys)
+	if err != nil {
+		return nil, &ParseError{Path: path, Format: p.Name(), Err: err}
+	}
+	if l.Metric != "" {
+		report.SetMetric(l.Metric)
+	}
+	if l.PathMapper != nil {
+		l.PathMapper.Apply(report)
+	}
+
+	// SourceDir is not needed, the meta-data files already name the functions
+	result := &Result{
+		Report: report,
+		Format: p.Name(),
+		Paths:  []string{path},
+	}
+	for _, diagnostic := range p.Diagnostics() {
+		result.Warnings = append(result.Warnings, Warning{Path: path, Diagnostic: diagnostic})
+	}
+	return result, nil
+}
+
+// LoadReader parses coverage data from a reader, using hint as the file name.
+// Only the first detectHeaderSize bytes are buffered for detection, the
+// parser reads the rest as a stream. Gzip, zstd and bzip2 compressed data is
+// decompressed.
+//
+// Zip and tar archives are unpacked: every coverage file inside is detected,
+// parsed and merged, and the files of the report record the archive member
+// they came from in their Origin. Members that are not coverage data are
+// skipped, members that fail to load are skipped with a warning. If no member
+// can be loaded, the result holding those warnings is returned with
+// ErrNoCoverage.
+func (l *Loader) LoadReader(r io.Reader, hint string) (*Result, error) {
+	buffered := bufio.NewReaderSize(r, detectHeaderSize)
+	header, err := buffered.Peek(detectHeaderSize)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read coverage data: %w", err)
+	}
+
+	decompressed, err := decompress.NewReader(header, buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress coverage data: %w", err)
+	}
+	if decompressed != nil {
+		defer func() { _ = decompressed.Close() }()
+
+		buffered = bufio.NewReaderSize(decompressed, detectHeaderSize)
+		header, err = buffered.Peek(detectHeaderSize)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to decompress coverage data: %w", err)
+		}
+	}
+
+	switch {
+	case isZip(header):
+		file, _ := r.(*os.File)
+		if decompressed != nil {
+			file = nil
+		}
+		return l.loadZip(buffered, file, hint)
+	case isTar(header):
+		return l.loadTar(buffered, hint)
+	}
+
+	p, err := l.selectParser(header, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := p.Parse(buffered)
+	if err != nil {
+		return nil, &ParseError{Path: hint, Format: p.Name(), Err: err}
+	}
+	if l.Metric != "" {
+		report.SetMetric(l.Metric)
+	}
+
+	var diagnostics []parser.Diagnostic
+	if l.SourceDir != "" && p.Name() == "go" {
+		diagnostics, err = parser.AddGoFunctions(report, l.SourceDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Go source from %s: %w", l.SourceDir, err)
+		}
+	}
+	// Names are mapped last, the Go source is found by import path
+	if l.PathMapper != nil {
+		l.PathMapper.Apply(report)
+	}
+
+	result := &Result{
+		Report: report,
+		Format: p.Name(),
+	}
+	if hint != "" {
+		result.Paths = []string{hint}
+	}
+	for _, diagnostic := range append(p.Diagnostics(), diagnostics...) {
+		result.Warnings = append(result.Warnings, Warning{Path: hint, Diagnostic: diagnostic})
+	}
+	return result, nil
+}
+
+// LoadAll loads several coverage files and merges them into one report. Files
+// that cannot be loaded are skipped with a warning. If none of the files can be
+// loaded, the result holding those warnings is returned with ErrNoCoverage.
+func (l *Loader) LoadAll(paths []string) (*Result, error) {
+	loaded := &collector{}
+	for _, path := range paths {
+		result, err := l.Load(path)
+		if err != nil {
+			loaded.fail(path, err)
+			continue
+		}
+		loaded.add(result)
+	}
+	return loaded.merge()
+}
+
+// selectParser returns the parser for coverage data. A forced format is looked
+// up in the parser registry, otherwise the format is detected by file name first
+// and by content second. When the content does not look like the format the
+// file name suggests but like another one, the content wins: coverage.json is
+// written by coverage.py and gcovr alike. Content matching several formats
+// equally well is reported as ambiguous rather than guessed.
+func (l *Loader) selectParser(header []byte, hint string) (parser.Parser, error) {
+	if l.Format != "" {
+		p := parser.Lookup(l.Format)
+		if p == nil {
+			return nil, &FormatError{Path: hint, Format: l.Format}
+		}
+		return p, nil
+	}
+
+	// Try detection by extension first, ignoring a compression suffix
+	format := detector.UnknownFormat
+	if name := hint; name != "" {
+		for _, suffix := range compressionSuffixes {
+			if len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+				name = name[:len(name)-len(suffix)]
+				break
+			}
+		}
+		format = detector.DetectFormatByExtension(name)
+	}
+	if format == detector.UnknownFormat || !format.Parser().Detect(header) {
+		// Fall back to content-based detection
+		candidates, err := detector.DetectAll(bytes.NewReader(header))
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect coverage format: %w", err)
+		}
+		if len(candidates) > 1 && candidates[1].Confidence == candidates[0].Confidence {
+			ambiguous := &FormatError{Path: hint}
+			for _, candidate := range candidates {
+				if candidate.Confidence == candidates[0].Confidence {
+					ambiguous.Candidates = append(ambiguous.Candidates, string(candidate.Format))
+				}
+			}
+			return nil, ambiguous
+		}
+		if len(candidates) > 0 {
+			format = candidates[0].Format
+		}
+	}
+
+	p := format.Parser()
+	if p == nil {
+		return nil, &FormatError{Path: hint}
+	}
+	return p, nil
+}
+
+// StandardLocations returns the paths, relative to a project root, where the
+// common coverage tools write their reports
+func StandardLocations() []string {
+	return []string{
+		"coverage.out",                  // Go
+		"test/coverage.out",             // Go
+		"lcov.info",                     // Rust/TS
+		"target/coverage/lcov.info",     // Rust
+		"coverage/lcov.info",            // TS
+		"coverage/coverage-final.json",  // TS
+		"coverage.xml",                  // Python
+		"coverage.json",                 // Python
+		"target/site/jacoco/jacoco.xml", // Java/Kotlin (Maven)
+		"build/reports/jacoco/test/jacocoTestReport.xml", // Java/Kotlin (Gradle)
+		"coverage/.resultset.json",                       // Ruby
+	}
+}
+
+// Discover returns the standard coverage file locations that exist below dir
+func Discover(dir string) []string {
+	var existingFiles []string
+	for _, location := range StandardLocations() {
+		path := filepath.Join(dir, filepath.FromSlash(location))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			existingFiles = append(existingFiles, path)
+		}
+	}
+	return existingFiles
+}
+
+// Merge combines reports into a new report. Files that appear in several
+// reports are merged line by line, see models.FileCoverage.Merge. The input
+// reports are not modified.
+func Merge(reports ...*models.CoverageReport) *models.CoverageReport {
+	merged := models.NewCoverageReport()
+	for _, report := range reports {
+		merged.Merge(report)
+	}
+	return merged
+}
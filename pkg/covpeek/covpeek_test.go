@@ -0,0 +1,432 @@
+package covpeek
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+)
+
+func TestLoad(t *testing.T) {
+	result, err := Load("../../testdata/sample.lcov")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if result.Format != "lcov" {
+		t.Errorf("Expected format lcov, got: %s", result.Format)
+	}
+	if len(result.Paths) != 1 || result.Paths[0] != "../../testdata/sample.lcov" {
+		t.Errorf("Expected path of loaded file, got: %v", result.Paths)
+	}
+	if len(result.Report.Files) == 0 {
+		t.Error("Expected files in report")
+	}
+}
+
+func TestLoad_NonExistent(t *testing.T) {
+	_, err := Load("nonexistent.lcov")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected not exist error, got: %v", err)
+	}
+}
+
+func TestLoadReader_DetectsByHint(t *testing.T) {
+	content := "mode: set\ngithub.com/example/main.go:10.1,12.1 1 1\n"
+
+	result, err := LoadReader(strings.NewReader(content), "coverage.out")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "go" {
+		t.Errorf("Expected format go, got: %s", result.Format)
+	}
+}
+
+func TestLoadReader_DetectsByContent(t *testing.T) {
+	content := "TN:\nSF:src/lib.rs\nDA:1,1\nDA:2,0\nend_of_record\n"
+
+	result, err := LoadReader(strings.NewReader(content), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "lcov" {
+		t.Errorf("Expected format lcov, got: %s", result.Format)
+	}
+	if result.Paths != nil {
+		t.Errorf("Expected no paths without a hint, got: %v", result.Paths)
+	}
+}
+
+func TestLoadReader_UnknownFormat(t *testing.T) {
+	_, err := LoadReader(strings.NewReader("hello world\n"), "notes.txt")
+
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("Expected FormatError, got: %v", err)
+	}
+	if formatErr.Path != "notes.txt" {
+		t.Errorf("Expected path notes.txt, got: %s", formatErr.Path)
+	}
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected error to match ErrUnknownFormat")
+	}
+}
+
+func TestLoadReader_AmbiguousFormat(t *testing.T) {
+	// Loosely JaCoCo's root element and loosely an LCOV record
+	_, err := LoadReader(strings.NewReader("<report>\nSF:foo\n</report>\n"), "report.txt")
+
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("Expected FormatError, got: %v", err)
+	}
+	if strings.Join(formatErr.Candidates, ",") != "jacoco,lcov" {
+		t.Errorf("Expected candidates jacoco and lcov, got: %v", formatErr.Candidates)
+	}
+	if !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguity message, got: %v", err)
+	}
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected error to match ErrUnknownFormat")
+	}
+}
+
+func TestLoadReader_ParseError(t *testing.T) {
+	_, err := LoadReader(strings.NewReader("<coverage><packages>"), "coverage.xml")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got: %v", err)
+	}
+	if parseErr.Format != "cobertura" {
+		t.Errorf("Expected format cobertura, got: %s", parseErr.Format)
+	}
+	if !strings.Contains(err.Error(), "Cobertura XML Coverage") {
+		t.Errorf("Expected format description in error, got: %v", err)
+	}
+}
+
+func TestLoadReader_Warnings(t *testing.T) {
+	content := "SF:src/lib.rs\nDA:1,1\nDA:bad\nend_of_record\n"
+
+	result, err := LoadReader(strings.NewReader(content), "lcov.info")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Fatal("Expected parser warnings in result")
+	}
+	warning := result.Warnings[0]
+	if warning.Path != "lcov.info" || warning.Err != nil || warning.Line != 3 || warning.Code != parser.CodeInvalidRecord {
+		t.Errorf("Unexpected warning: %+v", warning)
+	}
+	if warning.String() != "lcov.info: line 3: "+warning.Message {
+		t.Errorf("Unexpected warning text: %s", warning)
+	}
+
+	data, err := json.Marshal(warning)
+	if err != nil {
+		t.Fatalf("Expected no error encoding warning, got: %v", err)
+	}
+	if !strings.Contains(string(data), `"file":"lcov.info","severity":"warning","line":3,"record":"DA","code":"invalid-record"`) {
+		t.Errorf("Unexpected warning JSON: %s", data)
+	}
+}
+
+func TestLoader_ForcedFormat(t *testing.T) {
+	content := "SF:src/lib.rs\nDA:1,1\nend_of_record\n"
+
+	loader := &Loader{Format: "rust"}
+	result, err := loader.LoadReader(strings.NewReader(content), "coverage.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "lcov" {
+		t.Errorf("Expected format lcov, got: %s", result.Format)
+	}
+
+	loader = &Loader{Format: "java"}
+	_, err = loader.LoadReader(strings.NewReader(content), "coverage.txt")
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) || formatErr.Format != "java" {
+		t.Errorf("Expected FormatError for forced format java, got: %v", err)
+	}
+}
+
+func TestLoad_GoCoverDir(t *testing.T) {
+	result, err := Load("../../testdata/gocoverdir")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "gocoverdir" {
+		t.Errorf("Expected format gocoverdir, got: %s", result.Format)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got: %v", result.Warnings)
+	}
+	file := result.Report.GetFile("example.com/demo/demo.go")
+	if file == nil || file.TotalStatements != 5 || file.CoveredStatements != 4 {
+		t.Errorf("Expected 4 of 5 statements covered, got: %+v", file)
+	}
+}
+
+func TestLoadDir_UnknownFormat(t *testing.T) {
+	_, err := Load(t.TempDir())
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) || !formatErr.Dir {
+		t.Errorf("Expected FormatError for directory, got: %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "directory") {
+		t.Errorf("Error should mention directory, got: %v", err)
+	}
+
+	loader := &Loader{Format: "lcov"}
+	if _, err := loader.Load("../../testdata/gocoverdir"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat for a file format forced on a directory, got: %v", err)
+	}
+}
+
+func TestLoad_Gzip(t *testing.T) {
+	result, err := Load("../../testdata/gcov/demo-a.gcov.json.gz")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "gcov" {
+		t.Errorf("Expected format gcov, got: %s", result.Format)
+	}
+
+	// A compressed file of another format is detected by the name without .gz
+	data, err := os.ReadFile("../../testdata/sample.lcov")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+
+	result, err = LoadReader(&compressed, "sample.lcov.gz")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "lcov" || len(result.Report.Files) == 0 {
+		t.Errorf("Expected LCOV files, got format %s with %d files", result.Format, len(result.Report.Files))
+	}
+}
+
+func TestLoad_GcovDir(t *testing.T) {
+	result, err := Load("../../testdata/gcov")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "gcov" {
+		t.Errorf("Expected format gcov, got: %s", result.Format)
+	}
+	if util := result.Report.GetFile("/home/dev/firmware/util.h"); util == nil || util.CoveredLines != 5 {
+		t.Errorf("Expected util.h merged from both translation units, got: %+v", util)
+	}
+}
+
+func TestLoadReader_ContentWinsOverName(t *testing.T) {
+	// coverage.json is the name coverage.py reports are detected by, gcovr and
+	// Coverlet write it as well. coverage.xml means Cobertura.
+	tests := []struct {
+		path   string
+		name   string
+		format string
+	}{
+		{"../../testdata/gcovr.json", "coverage.json", "gcovr"},
+		{"../../testdata/coverlet.json", "coverage.json", "coverlet"},
+		{"../../testdata/clover.xml", "coverage.xml", "clover"},
+	}
+	for _, test := range tests {
+		file, err := os.Open(test.path)
+		if err != nil {
+			t.Fatalf("Failed to open test file: %v", err)
+		}
+		result, err := LoadReader(file, test.name)
+		_ = file.Close()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Format != test.format {
+			t.Errorf("Expected format %s, got: %s", test.format, result.Format)
+		}
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	result, err := LoadAll([]string{
+		"../../testdata/sample.lcov",
+		"../../testdata/missing.lcov",
+		"../../testdata/sample.out",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(result.Paths) != 2 {
+		t.Errorf("Expected 2 loaded paths, got: %v", result.Paths)
+	}
+	if result.Format != "" {
+		t.Errorf("Expected no format for mixed formats, got: %s", result.Format)
+	}
+
+	skipped := 0
+	for _, warning := range result.Warnings {
+		if warning.Err != nil {
+			skipped++
+			if warning.Path != "../../testdata/missing.lcov" {
+				t.Errorf("Expected missing file to be skipped, got: %s", warning.Path)
+			}
+		}
+	}
+	if skipped != 1 {
+		t.Errorf("Expected 1 skipped file, got: %d", skipped)
+	}
+}
+
+func TestLoadAll_NothingLoaded(t *testing.T) {
+	result, err := LoadAll([]string{"missing.out"})
+	if !errors.Is(err, ErrNoCoverage) {
+		t.Fatalf("Expected ErrNoCoverage, got: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got: %d", len(result.Warnings))
+	}
+	if result.Warnings[0].Severity != parser.SeverityError || result.Warnings[0].Code != CodeLoadFailed {
+		t.Errorf("Expected load-failed error, got: %+v", result.Warnings[0])
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "coverage"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "coverage", "lcov.info"), []byte("SF:a\nend_of_record\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	// A directory at a standard location is not a coverage file
+	if err := os.Mkdir(filepath.Join(dir, "coverage.out"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	files := Discover(dir)
+	if len(files) != 1 || files[0] != filepath.Join(dir, "coverage", "lcov.info") {
+		t.Errorf("Expected only coverage/lcov.info, got: %v", files)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	report1 := models.NewCoverageReport()
+	file1 := &models.FileCoverage{
+		FileName: "file.go",
+		Lines: map[int]models.LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 2},
+			2: {LineNumber: 2, ExecutionCount: 0},
+		},
+	}
+	file1.CountLines()
+	report1.AddFile(file1)
+
+	report2 := models.NewCoverageReport()
+	file2 := &models.FileCoverage{
+		FileName: "file.go",
+		Lines: map[int]models.LineCoverage{
+			2: {LineNumber: 2, ExecutionCount: 1},
+			3: {LineNumber: 3, ExecutionCount: 0},
+		},
+	}
+	file2.CountLines()
+	report2.AddFile(file2)
+
+	merged := Merge(report1, report2)
+
+	fc := merged.GetFile("file.go")
+	if fc.TotalLines != 3 || fc.CoveredLines != 2 {
+		t.Errorf("Expected TotalLines 3, CoveredLines 2, got %d, %d", fc.TotalLines, fc.CoveredLines)
+	}
+
+	// Inputs are left untouched
+	if file1.Lines[2].ExecutionCount != 0 || len(file1.Lines) != 2 {
+		t.Errorf("Expected input report to be unchanged, got: %v", file1.Lines)
+	}
+}
+
+func TestLoadReader_LargerThanHeader(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("mode: set\n")
+	for i := 1; content.Len() < 3*detectHeaderSize; i++ {
+		fmt.Fprintf(&content, "example.com/pkg/file.go:%d.1,%d.10 1 %d\n", i, i, i%2)
+	}
+
+	result, err := LoadReader(strings.NewReader(content.String()), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Format != "go" {
+		t.Errorf("Expected format go, got: %s", result.Format)
+	}
+	file := result.Report.GetFile("example.com/pkg/file.go")
+	if file == nil || file.TotalLines < 3*detectHeaderSize/50 {
+		t.Errorf("Expected the whole profile to be parsed, got: %+v", file)
+	}
+}
+
+func TestLoader_Metric(t *testing.T) {
+	result, err := Load("../../testdata/sample.out")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Report.Metric != models.MetricStatement {
+		t.Errorf("Expected Go profiles to use the statement metric, got: %s", result.Report.Metric)
+	}
+
+	loader := &Loader{Metric: models.MetricLine}
+	result, err = loader.Load("../../testdata/sample.out")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Report.Metric != models.MetricLine {
+		t.Errorf("Expected the line metric, got: %s", result.Report.Metric)
+	}
+	for name, file := range result.Report.Files {
+		if file.TotalLines > 0 && file.CoveragePct != float64(file.CoveredLines)/float64(file.TotalLines)*100 {
+			t.Errorf("Expected line coverage for %s, got: %.2f", name, file.CoveragePct)
+		}
+	}
+}
+
+func TestLoader_SourceDir(t *testing.T) {
+	loader := &Loader{SourceDir: "../../testdata/gomodule"}
+	result, err := loader.Load("../../testdata/gomodule/coverage.out")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got: %v", result.Warnings)
+	}
+	file := result.Report.GetFile("example.com/demo/demo.go")
+	if file == nil || len(file.Functions) != 3 || file.Functions[1].Name != "Checker.Check" {
+		t.Fatalf("Expected the functions of demo.go, got: %+v", file)
+	}
+
+	// Other formats are left alone, they carry their own functions
+	result, err = loader.Load("../../testdata/sample.lcov")
+	if err != nil {
+		t.Fatalf("Expected no error for LCOV, got: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings for LCOV, got: %v", result.Warnings)
+	}
+}
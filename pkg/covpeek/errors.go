@@ -0,0 +1,80 @@
+package covpeek
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+)
+
+var (
+	// ErrUnknownFormat is matched by FormatError, use errors.Is to check for it
+	ErrUnknownFormat = errors.New("unknown coverage format")
+	// ErrNoCoverage is returned by LoadAll when none of the files could be loaded
+	ErrNoCoverage = errors.New("no coverage files found")
+)
+
+// FormatError is returned when the format of a coverage file cannot be
+// detected, or when a forced format is not registered
+type FormatError struct {
+	// Path is the file (or file name hint) that was being loaded, if any
+	Path string
+	// Format is the forced format name, empty when detection failed
+	Format string
+	// Dir is set when Path is a directory
+	Dir bool
+	// Candidates lists the formats the content matched equally well when
+	// detection was ambiguous
+	Candidates []string
+}
+
+func (e *FormatError) Error() string {
+	if e.Format != "" {
+		return fmt.Sprintf("unknown format: %s (use one of: %s)", e.Format, strings.Join(parser.Names(), ", "))
+	}
+	if len(e.Candidates) > 0 {
+		what := "coverage data"
+		if e.Path != "" {
+			what = "file " + e.Path
+		}
+		return fmt.Sprintf("ambiguous coverage format for %s: matches %s equally well, force one of them", what, strings.Join(e.Candidates, ", "))
+	}
+	if e.Dir {
+		return fmt.Sprintf("unable to detect coverage format for directory: %s", e.Path)
+	}
+	if e.Path != "" {
+		return fmt.Sprintf("unable to detect coverage format for file: %s", e.Path)
+	}
+	return "unable to detect coverage format"
+}
+
+// Is makes errors.Is(err, ErrUnknownFormat) report true for a FormatError
+func (e *FormatError) Is(target error) bool {
+	return target == ErrUnknownFormat
+}
+
+// ParseError is returned when a parser rejects a coverage file
+type ParseError struct {
+	// Path is the file (or file name hint) that was being loaded, if any
+	Path string
+	// Format is the canonical name of the format the file was parsed as
+	Format string
+	// Err is the error returned by the parser
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	name := e.Format
+	if p := parser.Lookup(e.Format); p != nil {
+		name = p.Description()
+	}
+	if e.Path != "" {
+		return fmt.Sprintf("failed to parse %s file %s: %v", name, e.Path, e.Err)
+	}
+	return fmt.Sprintf("failed to parse %s file: %v", name, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,216 @@
+package covpeek
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+	"github.com/Chapati-Systems/covpeek/pkg/parser"
+)
+
+// PathRule replaces a leading From of a file name with To. From and To are
+// whole path elements: /build does not match /builds/x.go.
+type PathRule struct {
+	From string
+	To   string
+}
+
+// PathMapper rewrites the file names of coverage reports to canonical names
+// relative to a repository root, so that reports written in containers, on CI
+// runners or on Windows name the same file the same way. A file name is
+// rewritten in this order:
+//
+//   - backslashes are turned into slashes
+//   - the first rule whose From the name starts with is applied
+//   - the first of StripPrefixes the name starts with is removed
+//   - a Go import path is replaced by the module's directory
+//   - an absolute name below Root is made relative to it
+//   - an absolute name elsewhere that no rule or prefix rewrote, such as
+//     /home/runner/work/app/app/src/x.ts, loses the directories in front of
+//     the longest tail that exists below Root, here src/x.ts when Root holds
+//     it, and a/src/x.ts rather than src/x.ts when Root holds both
+//   - such a name without a tail below Root, e.g. of a file deleted since,
+//     loses the shortest of the prefixes found for the names mapped before it
+//   - the name is cleaned, ./src/x.ts becomes src/x.ts
+//
+// Only names missing from Root depend on the names mapped before them, Apply
+// maps the names of a report in sorted order. A PathMapper remembers the
+// prefixes it found for absolute names and is not safe for concurrent use.
+type PathMapper struct {
+	// Root is the repository root, absolute. Leave it empty to keep absolute
+	// names that no rule or prefix rewrites.
+	Root string
+	// Rules are tried in order, the first matching one is applied
+	Rules []PathRule
+	// StripPrefixes are removed from the start of file names
+	StripPrefixes []string
+	// Modules maps Go module paths to their directory relative to Root, ""
+	// for a module at the root
+	Modules map[string]string
+
+	// prefixes are the directories found in front of Root's files
+	prefixes []string
+}
+
+// NewPathMapper returns a PathMapper for the repository at root. The module
+// declared by root's go.mod, if there is one, is mapped to the root.
+func NewPathMapper(root string) (*PathMapper, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	m := &PathMapper{Root: abs, Modules: make(map[string]string)}
+
+	data, err := os.ReadFile(filepath.Join(abs, "go.mod"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if module := parser.GoModulePath(data); module != "" {
+		m.Modules[module] = ""
+	}
+	return m, nil
+}
+
+// Map returns the canonical name of a file
+func (m *PathMapper) Map(name string) string {
+	if name == "" {
+		return name
+	}
+	name = strings.ReplaceAll(name, `\`, "/")
+
+	rewritten := false
+	for _, rule := range m.Rules {
+		if rest, ok := cutPathPrefix(name, rule.From); ok {
+			name = joinPath(strings.ReplaceAll(rule.To, `\`, "/"), rest)
+			rewritten = true
+			break
+		}
+	}
+	for _, prefix := range m.StripPrefixes {
+		if rest, ok := cutPathPrefix(name, prefix); ok {
+			name = rest
+			rewritten = true
+			break
+		}
+	}
+	name = m.mapModule(name)
+	if parser.IsAbsolutePath(name) {
+		name = m.relativize(name, !rewritten)
+	}
+
+	if cleaned := path.Clean(name); cleaned != "." {
+		return cleaned
+	}
+	return name
+}
+
+// mapModule replaces the longest module path a Go import path starts with by
+// the module's directory
+func (m *PathMapper) mapModule(name string) string {
+	best := ""
+	for module := range m.Modules {
+		if _, ok := cutPathPrefix(name, module); ok && len(module) > len(best) {
+			best = module
+		}
+	}
+	if best == "" {
+		return name
+	}
+	rest, _ := cutPathPrefix(name, best)
+	return joinPath(m.Modules[best], rest)
+}
+
+// relativize makes an absolute name relative to Root. With probe, names
+// outside Root lose the prefix in front of the longest tail Root holds, and
+// the prefix is kept for names whose files Root lacks.
+func (m *PathMapper) relativize(name string, probe bool) string {
+	if m.Root == "" {
+		return name
+	}
+	if rest, ok := cutPathPrefix(name, filepath.ToSlash(m.Root)); ok {
+		return rest
+	}
+	if !probe {
+		return name
+	}
+
+	for i := 0; i < len(name); i++ {
+		if name[i] != '/' || i == len(name)-1 {
+			continue
+		}
+		tail := name[i+1:]
+		if _, err := os.Stat(filepath.Join(m.Root, filepath.FromSlash(tail))); err == nil {
+			if !slices.Contains(m.prefixes, name[:i]) {
+				m.prefixes = append(m.prefixes, name[:i])
+			}
+			return tail
+		}
+	}
+
+	// The file is not in Root, the shortest known prefix keeps the longest tail
+	best, found := "", false
+	for _, prefix := range m.prefixes {
+		if _, ok := cutPathPrefix(name, prefix); ok && (!found || len(prefix) < len(best)) {
+			best, found = prefix, true
+		}
+	}
+	if !found {
+		return name
+	}
+	rest, _ := cutPathPrefix(name, best)
+	return rest
+}
+
+// Apply rewrites the file names of a report. Files that end up with the same
+// name are merged.
+func (m *PathMapper) Apply(report *models.CoverageReport) {
+	names := make([]string, 0, len(report.Files))
+	for name := range report.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make(map[string]*models.FileCoverage, len(report.Files))
+	for _, name := range names {
+		file := report.Files[name]
+		file.FileName = m.Map(file.FileName)
+		if existing := files[file.FileName]; existing != nil {
+			existing.Merge(file)
+			existing.CalculateCoverageFor(report.Metric)
+			continue
+		}
+		files[file.FileName] = file
+	}
+	report.Files = files
+}
+
+// cutPathPrefix removes prefix from name when it ends at a path separator.
+// A trailing slash of prefix is ignored, a name equal to prefix is not cut.
+func cutPathPrefix(name, prefix string) (string, bool) {
+	prefix = strings.TrimSuffix(strings.ReplaceAll(prefix, `\`, "/"), "/")
+	if prefix == "" {
+		if strings.HasPrefix(name, "/") {
+			return name[1:], true
+		}
+		return name, false
+	}
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok || !strings.HasPrefix(rest, "/") {
+		return name, false
+	}
+	return rest[1:], true
+}
+
+// joinPath joins a directory and a relative name, an empty directory keeps the name
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + name
+}
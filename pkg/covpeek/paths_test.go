@@ -0,0 +1,171 @@
+package covpeek
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// newTestRepo creates a repository holding the given files and returns its root
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	return root
+}
+
+func TestPathMapper_Map(t *testing.T) {
+	root := newTestRepo(t, map[string]string{
+		"go.mod":           "// The shop\nmodule example.com/shop // v2 is next\n\ngo 1.25\n",
+		"src/cart.ts":      "",
+		"src/checkout.ts":  "",
+		"tools/go.mod":     "module example.com/shop/tools\n",
+		"tools/cmd/gen.go": "",
+	})
+	mapper, err := NewPathMapper(root)
+	if err != nil {
+		t.Fatalf("NewPathMapper failed: %v", err)
+	}
+	mapper.Modules["example.com/shop/tools"] = "tools"
+	mapper.Rules = []PathRule{{From: "/build/gen", To: "generated"}}
+	mapper.StripPrefixes = []string{`D:\agent\_work\1\s`}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"src/cart.ts", "src/cart.ts"},
+		{"./src/cart.ts", "src/cart.ts"},
+		{`src\cart.ts`, "src/cart.ts"},
+		{filepath.Join(root, "src", "cart.ts"), "src/cart.ts"},
+		{"/home/runner/work/shop/shop/src/cart.ts", "src/cart.ts"},
+		// The prefix found for cart.ts is kept for files missing from the checkout
+		{"/home/runner/work/shop/shop/src/deleted.ts", "src/deleted.ts"},
+		{"example.com/shop/cart/cart.go", "cart/cart.go"},
+		{"example.com/shop/tools/cmd/gen.go", "tools/cmd/gen.go"},
+		{"example.com/shopping/cart.go", "example.com/shopping/cart.go"},
+		{"/build/gen/api.go", "generated/api.go"},
+		{"/build/generated/api.go", "/build/generated/api.go"},
+		{`D:\agent\_work\1\s\src\checkout.ts`, "src/checkout.ts"},
+		{"/usr/include/stdio.h", "/usr/include/stdio.h"},
+	}
+	for _, tt := range tests {
+		if name := mapper.Map(tt.name); name != tt.expected {
+			t.Errorf("Map(%q) = %q, expected %q", tt.name, name, tt.expected)
+		}
+	}
+}
+
+func TestPathMapper_Map_CollidingTails(t *testing.T) {
+	root := newTestRepo(t, map[string]string{
+		"a/src/x.ts": "",
+		"src/x.ts":   "",
+		"src/y.ts":   "",
+	})
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		// The longest tail below the root wins
+		{"/ci/app/a/src/x.ts", "a/src/x.ts"},
+		{"/ci/app/src/x.ts", "src/x.ts"},
+		{"/ci/app/a/src/y.ts", "src/y.ts"},
+	}
+
+	// Files in the root map the same whatever was mapped before them
+	for _, reverse := range []bool{false, true} {
+		mapper := &PathMapper{Root: root}
+		for i := range tests {
+			tt := tests[i]
+			if reverse {
+				tt = tests[len(tests)-1-i]
+			}
+			if name := mapper.Map(tt.name); name != tt.expected {
+				t.Errorf("Map(%q) = %q, expected %q (reverse %v)", tt.name, name, tt.expected, reverse)
+			}
+		}
+
+		// A file missing from the root loses the shortest prefix found, /ci/app
+		if name := mapper.Map("/ci/app/a/src/gone.ts"); name != "a/src/gone.ts" {
+			t.Errorf("Expected a/src/gone.ts, got %s (reverse %v)", name, reverse)
+		}
+	}
+
+	// Names a rule rewrote are not probed
+	mapper := &PathMapper{Root: root, Rules: []PathRule{{From: "/ci/app/a", To: "/vendor/a"}}}
+	if name := mapper.Map("/ci/app/a/src/x.ts"); name != "/vendor/a/src/x.ts" {
+		t.Errorf("Expected the rule's name to be kept, got %s", name)
+	}
+}
+
+func TestPathMapper_Apply(t *testing.T) {
+	mapper := &PathMapper{StripPrefixes: []string{"/build"}}
+
+	// The same file, once from a container and once from the host
+	report := models.NewCoverageReport()
+	report.AddFile(&models.FileCoverage{FileName: "/build/src/lib.rs", Lines: map[int]models.LineCoverage{
+		1: {LineNumber: 1, ExecutionCount: 1},
+		2: {LineNumber: 2, ExecutionCount: 0},
+	}})
+	report.AddFile(&models.FileCoverage{FileName: "src/lib.rs", Lines: map[int]models.LineCoverage{
+		2: {LineNumber: 2, ExecutionCount: 3},
+	}})
+	mapper.Apply(report)
+
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected the files to be merged, got %v", report.Files)
+	}
+	file := report.Files["src/lib.rs"]
+	if file == nil || file.FileName != "src/lib.rs" {
+		t.Fatalf("Expected src/lib.rs, got %+v", file)
+	}
+	if file.CoveredLines != 2 || file.TotalLines != 2 || file.CoveragePct != 100 {
+		t.Errorf("Expected both lines covered, got %d of %d", file.CoveredLines, file.TotalLines)
+	}
+}
+
+func TestLoader_PathMapper(t *testing.T) {
+	root := newTestRepo(t, map[string]string{"go.mod": "module git.kernel.fun/myproject\n"})
+	mapper, err := NewPathMapper(root)
+	if err != nil {
+		t.Fatalf("NewPathMapper failed: %v", err)
+	}
+
+	loader := &Loader{PathMapper: mapper}
+	result, err := loader.Load("../../testdata/sample.out")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for name := range result.Report.Files {
+		if strings.HasPrefix(name, "git.kernel.fun/") {
+			t.Errorf("Expected names relative to the module, got %s", name)
+		}
+	}
+	if result.Report.Files["pkg/calculator/calculator.go"] == nil {
+		t.Errorf("Expected pkg/calculator/calculator.go, got %v", result.Report.Files)
+	}
+}
+
+func TestNewPathMapper_NoGoMod(t *testing.T) {
+	mapper, err := NewPathMapper(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewPathMapper failed: %v", err)
+	}
+	if len(mapper.Modules) != 0 {
+		t.Errorf("Expected no modules, got %v", mapper.Modules)
+	}
+	if name := mapper.Map("github.com/org/repo/x.go"); name != "github.com/org/repo/x.go" {
+		t.Errorf("Expected the import path to be kept, got %s", name)
+	}
+}
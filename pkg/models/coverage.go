@@ -1,32 +1,128 @@
 package models
 
-// FileCoverage represents coverage data for a single source file
+import "sort"
+
+// Metric selects what coverage percentages count
+type Metric string
+
+const (
+	// MetricLine counts executable lines, the default
+	MetricLine Metric = "line"
+	// MetricStatement weights coverage by statements the way go test -cover
+	// does. Files without statement data are counted by lines.
+	MetricStatement Metric = "statement"
+)
+
+// FileCoverage represents coverage data for a single source file.
+// Package is only set when the format reports the package or namespace of a file,
+// the instruction counters are only reported by bytecode-level tools such as JaCoCo,
+// statements and blocks only by statement-based tools such as Go's cover, and
+// regions only by LLVM source-based coverage. Origin is only set for coverage
+// read from an archive and names the member it came from, such as
+// "artifacts.zip!backend/lcov.info". Classes and ExcludedLines are only
+// reported by coverage.py; excluded lines, such as those marked
+// "# pragma: no cover", are not part of Lines.
 type FileCoverage struct {
-	FileName     string
-	TotalLines   int
-	CoveredLines int
-	CoveragePct  float64
-	Functions    []FunctionCoverage
-	Lines        map[int]LineCoverage
+	FileName            string
+	Package             string
+	Origin              string
+	TotalLines          int
+	CoveredLines        int
+	CoveragePct         float64
+	TotalBranches       int
+	CoveredBranches     int
+	BranchPct           float64
+	TotalInstructions   int
+	CoveredInstructions int
+	TotalStatements     int
+	CoveredStatements   int
+	TotalRegions        int
+	CoveredRegions      int
+	Functions           []FunctionCoverage
+	Classes             []ClassCoverage
+	Lines               map[int]LineCoverage
+	ExcludedLines       []int
+	Branches            []BranchCoverage
+	Blocks              []BlockCoverage
+	Regions             []RegionCoverage
 }
 
-// FunctionCoverage represents coverage data for a function
+// FunctionCoverage represents coverage data for a function. The statement
+// counts are only set for formats that locate statements, such as Go profiles
+// combined with their source and coverage.py reports. Tests names the tests
+// that ran the function, see LineCoverage.
 type FunctionCoverage struct {
-	Name           string
-	LineNumber     int
-	ExecutionCount int
+	Name              string
+	LineNumber        int
+	ExecutionCount    int
+	TotalStatements   int
+	CoveredStatements int
+	CoveragePct       float64
+	Tests             []string
+}
+
+// ClassCoverage represents the statements of a class, as reported by coverage.py
+type ClassCoverage struct {
+	Name              string
+	LineNumber        int
+	TotalStatements   int
+	CoveredStatements int
+	CoveragePct       float64
 }
 
-// LineCoverage represents coverage data for a single line
+// LineCoverage represents coverage data for a single line. Tests names the
+// tests that ran the line, for formats recording them such as LCOV with
+// several TN: sections and coverage.py with --show-contexts. It is empty for
+// lines that did not run and when the format does not say which tests ran.
 type LineCoverage struct {
 	LineNumber     int
 	ExecutionCount int
 	Checksum       string
+	Tests          []string
+}
+
+// BranchCoverage represents coverage data for a single branch of a conditional
+type BranchCoverage struct {
+	LineNumber  int
+	BlockNumber int
+	BranchID    string
+	TakenCount  int
+	// NotExecuted is set when the enclosing block never ran, so the branch
+	// could not be evaluated (LCOV writes "-" as the taken count)
+	NotExecuted bool
+}
+
+// IsCovered reports whether the branch was taken at least once
+func (b BranchCoverage) IsCovered() bool {
+	return !b.NotExecuted && b.TakenCount > 0
+}
+
+// BlockCoverage represents a block of statements that run together, as
+// recorded by Go cover profiles. Columns are 1-based byte offsets in the line.
+type BlockCoverage struct {
+	StartLine     int
+	StartColumn   int
+	EndLine       int
+	EndColumn     int
+	NumStatements int
+	Count         int
+}
+
+// RegionCoverage represents a code region with its own counter, as recorded
+// by LLVM source-based coverage. Columns are 1-based.
+type RegionCoverage struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	Count       int
 }
 
-// CoverageReport represents the complete coverage report
+// CoverageReport represents the complete coverage report. Metric selects how
+// coverage percentages are computed, empty means MetricLine.
 type CoverageReport struct {
 	TestName string
+	Metric   Metric
 	Files    map[string]*FileCoverage
 }
 
@@ -47,21 +143,168 @@ func (r *CoverageReport) GetFile(filename string) *FileCoverage {
 	return r.Files[filename]
 }
 
-// CalculateOverallCoverage calculates the total lines, covered lines, and overall coverage percentage across all files
+// HasTests reports whether the report records the tests that ran any line or
+// function
+func (r *CoverageReport) HasTests() bool {
+	for _, fc := range r.Files {
+		if fc.HasTests() {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributeTo records test as having run every executed line and function of
+// the report that names no tests yet, see FileCoverage.AttributeTo
+func (r *CoverageReport) AttributeTo(test string) {
+	for _, fc := range r.Files {
+		fc.AttributeTo(test)
+	}
+}
+
+// SetMetric selects how coverage percentages are computed and recalculates
+// the percentage of every file
+func (r *CoverageReport) SetMetric(metric Metric) {
+	r.Metric = metric
+	for _, fc := range r.Files {
+		fc.CalculateCoverageFor(metric)
+	}
+}
+
+// Percent returns covered as a percentage of total, 0 when total is 0
+func Percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100.0
+}
+
+// CalculateOverallCoverage calculates the total lines, covered lines, and overall coverage
+// percentage across all files. With MetricStatement the totals count statements.
 func (r *CoverageReport) CalculateOverallCoverage() (totalLines int, totalCovered int, overallPct float64) {
 	for _, fc := range r.Files {
-		totalLines += fc.TotalLines
-		totalCovered += fc.CoveredLines
+		total, covered := fc.Counts(r.Metric)
+		totalLines += total
+		totalCovered += covered
 	}
-	if totalLines > 0 {
-		overallPct = (float64(totalCovered) / float64(totalLines)) * 100.0
+	overallPct = Percent(totalCovered, totalLines)
+	return
+}
+
+// CalculateOverallBranchCoverage calculates the total branches, covered branches, and overall branch coverage percentage across all files
+func (r *CoverageReport) CalculateOverallBranchCoverage() (totalBranches int, totalCovered int, overallPct float64) {
+	for _, fc := range r.Files {
+		totalBranches += fc.TotalBranches
+		totalCovered += fc.CoveredBranches
 	}
+	overallPct = Percent(totalCovered, totalBranches)
 	return
 }
 
-// CalculateCoverage calculates the coverage percentage for a file
+// CalculateCoverage calculates the line and branch coverage percentages for a file
 func (fc *FileCoverage) CalculateCoverage() {
-	if fc.TotalLines > 0 {
-		fc.CoveragePct = (float64(fc.CoveredLines) / float64(fc.TotalLines)) * 100.0
+	fc.CalculateCoverageFor(MetricLine)
+}
+
+// CalculateCoverageFor calculates the coverage percentage in the given metric
+// and the branch coverage percentage for a file
+func (fc *FileCoverage) CalculateCoverageFor(metric Metric) {
+	if total, covered := fc.Counts(metric); total > 0 {
+		fc.CoveragePct = Percent(covered, total)
+	}
+	if fc.TotalBranches > 0 {
+		fc.BranchPct = Percent(fc.CoveredBranches, fc.TotalBranches)
+	}
+}
+
+// Counts returns the total and covered count of the metric, statements for
+// MetricStatement when the file has blocks and lines otherwise. A file whose
+// blocks hold no statements, such as an empty func main, counts none.
+func (fc *FileCoverage) Counts(metric Metric) (total int, covered int) {
+	if metric == MetricStatement && len(fc.Blocks) > 0 {
+		return fc.TotalStatements, fc.CoveredStatements
+	}
+	return fc.TotalLines, fc.CoveredLines
+}
+
+// CountStatements sets the total and covered statement counts from the Blocks slice
+func (fc *FileCoverage) CountStatements() {
+	fc.TotalStatements = 0
+	fc.CoveredStatements = 0
+	for _, b := range fc.Blocks {
+		fc.TotalStatements += b.NumStatements
+		if b.Count > 0 {
+			fc.CoveredStatements += b.NumStatements
+		}
+	}
+}
+
+// CountRegions sets the total and covered region counts from the Regions slice
+func (fc *FileCoverage) CountRegions() {
+	fc.TotalRegions = len(fc.Regions)
+	fc.CoveredRegions = 0
+	for _, r := range fc.Regions {
+		if r.Count > 0 {
+			fc.CoveredRegions++
+		}
+	}
+}
+
+// LinesByTest maps the tests recorded for the file's lines to the lines each
+// of them ran, in ascending order
+func (fc *FileCoverage) LinesByTest() map[string][]int {
+	byTest := make(map[string][]int)
+	for lineNum, line := range fc.Lines {
+		for _, test := range line.Tests {
+			byTest[test] = append(byTest[test], lineNum)
+		}
+	}
+	for _, lines := range byTest {
+		sort.Ints(lines)
+	}
+	return byTest
+}
+
+// HasTests reports whether the file records the tests that ran any of its
+// lines or functions
+func (fc *FileCoverage) HasTests() bool {
+	for _, line := range fc.Lines {
+		if len(line.Tests) > 0 {
+			return true
+		}
+	}
+	for _, fn := range fc.Functions {
+		if len(fn.Tests) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributeTo records test as having run every executed line and function of
+// the file that names no tests yet. Formats without per-test data are
+// attributed this way to the test run that wrote them.
+func (fc *FileCoverage) AttributeTo(test string) {
+	for lineNum, line := range fc.Lines {
+		if line.ExecutionCount > 0 && len(line.Tests) == 0 {
+			line.Tests = []string{test}
+			fc.Lines[lineNum] = line
+		}
+	}
+	for i := range fc.Functions {
+		if fn := &fc.Functions[i]; fn.ExecutionCount > 0 && len(fn.Tests) == 0 {
+			fn.Tests = []string{test}
+		}
+	}
+}
+
+// CountBranches sets the total and covered branch counts from the Branches slice
+func (fc *FileCoverage) CountBranches() {
+	fc.TotalBranches = len(fc.Branches)
+	fc.CoveredBranches = 0
+	for _, b := range fc.Branches {
+		if b.IsCovered() {
+			fc.CoveredBranches++
+		}
 	}
 }
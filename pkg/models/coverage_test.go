@@ -181,6 +181,22 @@ func TestCoverageReportWithMultipleFiles(t *testing.T) {
 	}
 }
 
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		covered, total int
+		expected       float64
+	}{
+		{0, 0, 0},
+		{3, 4, 75},
+		{1, 8, 12.5},
+	}
+	for _, tt := range tests {
+		if pct := Percent(tt.covered, tt.total); pct != tt.expected {
+			t.Errorf("Percent(%d, %d) = %v, expected %v", tt.covered, tt.total, pct, tt.expected)
+		}
+	}
+}
+
 func TestCalculateOverallCoverage(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -347,3 +363,134 @@ func TestFileCoverageWithLines(t *testing.T) {
 		t.Error("Expected line 3 to be uncovered")
 	}
 }
+
+func TestFileCoverageWithBranches(t *testing.T) {
+	fc := &FileCoverage{
+		FileName: "branch.rs",
+		Branches: []BranchCoverage{
+			{LineNumber: 4, BlockNumber: 0, BranchID: "0", TakenCount: 3},
+			{LineNumber: 4, BlockNumber: 0, BranchID: "1", TakenCount: 0},
+			{LineNumber: 9, BlockNumber: 1, BranchID: "0", NotExecuted: true},
+			{LineNumber: 9, BlockNumber: 1, BranchID: "1", NotExecuted: true},
+		},
+	}
+
+	fc.CountBranches()
+	fc.CalculateCoverage()
+
+	if fc.TotalBranches != 4 {
+		t.Errorf("Expected 4 total branches, got %d", fc.TotalBranches)
+	}
+
+	if fc.CoveredBranches != 1 {
+		t.Errorf("Expected 1 covered branch, got %d", fc.CoveredBranches)
+	}
+
+	if fc.BranchPct != 25.0 {
+		t.Errorf("Expected branch coverage 25%%, got %.2f%%", fc.BranchPct)
+	}
+
+	if fc.Branches[2].IsCovered() {
+		t.Error("Expected not executed branch to be uncovered")
+	}
+}
+
+func TestCalculateOverallBranchCoverage(t *testing.T) {
+	report := NewCoverageReport()
+	report.AddFile(&FileCoverage{FileName: "a.ts", TotalBranches: 10, CoveredBranches: 5})
+	report.AddFile(&FileCoverage{FileName: "b.ts", TotalBranches: 30, CoveredBranches: 25})
+	report.AddFile(&FileCoverage{FileName: "c.go"})
+
+	total, covered, pct := report.CalculateOverallBranchCoverage()
+
+	if total != 40 || covered != 30 {
+		t.Errorf("Expected 40 total and 30 covered branches, got %d, %d", total, covered)
+	}
+
+	if pct != 75.0 {
+		t.Errorf("Expected overall branch coverage 75%%, got %.2f%%", pct)
+	}
+
+	empty := NewCoverageReport()
+	if _, _, pct := empty.CalculateOverallBranchCoverage(); pct != 0 {
+		t.Errorf("Expected 0%% branch coverage for empty report, got %.2f%%", pct)
+	}
+}
+
+func TestCalculateOverallCoverage_StatementMetric(t *testing.T) {
+	report := NewCoverageReport()
+	report.AddFile(&FileCoverage{
+		FileName: "a.go", TotalLines: 10, CoveredLines: 5,
+		Blocks: []BlockCoverage{
+			{StartLine: 1, EndLine: 4, NumStatements: 6, Count: 2},
+			{StartLine: 5, EndLine: 10, NumStatements: 2, Count: 0},
+		},
+	})
+	// Files without statement data are counted by lines
+	report.AddFile(&FileCoverage{FileName: "b.ts", TotalLines: 4, CoveredLines: 4})
+	report.Files["a.go"].CountStatements()
+
+	if total, covered, _ := report.CalculateOverallCoverage(); total != 14 || covered != 9 {
+		t.Errorf("Expected 9/14 lines, got %d/%d", covered, total)
+	}
+
+	report.SetMetric(MetricStatement)
+	if total, covered, _ := report.CalculateOverallCoverage(); total != 12 || covered != 10 {
+		t.Errorf("Expected 10/12 statements and lines, got %d/%d", covered, total)
+	}
+	if report.Files["a.go"].CoveragePct != 75.0 {
+		t.Errorf("Expected 75%% statement coverage for a.go, got %.2f%%", report.Files["a.go"].CoveragePct)
+	}
+
+	report.SetMetric(MetricLine)
+	if report.Files["a.go"].CoveragePct != 50.0 {
+		t.Errorf("Expected 50%% line coverage for a.go, got %.2f%%", report.Files["a.go"].CoveragePct)
+	}
+}
+
+func TestCoverageReportAttributeTo(t *testing.T) {
+	report := NewCoverageReport()
+	report.AddFile(&FileCoverage{
+		FileName: "a.go",
+		Lines: map[int]LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 2},
+			2: {LineNumber: 2, ExecutionCount: 0},
+			3: {LineNumber: 3, ExecutionCount: 1, Tests: []string{"TestOther"}},
+		},
+		Functions: []FunctionCoverage{
+			{Name: "Run", LineNumber: 1, ExecutionCount: 2},
+			{Name: "unused", LineNumber: 2},
+		},
+	})
+	if !report.HasTests() {
+		t.Error("Expected the report to record the tests of line 3")
+	}
+	if NewCoverageReport().HasTests() {
+		t.Error("Expected an empty report to record no tests")
+	}
+
+	report.AttributeTo("unit")
+
+	file := report.Files["a.go"]
+	if tests := file.Lines[1].Tests; len(tests) != 1 || tests[0] != "unit" {
+		t.Errorf("Expected line 1 to be run by unit, got %v", tests)
+	}
+	if tests := file.Lines[2].Tests; len(tests) != 0 {
+		t.Errorf("Expected missed line 2 to have no tests, got %v", tests)
+	}
+	// Lines that already name their tests are kept
+	if tests := file.Lines[3].Tests; len(tests) != 1 || tests[0] != "TestOther" {
+		t.Errorf("Expected line 3 to keep TestOther, got %v", tests)
+	}
+	if tests := file.Functions[0].Tests; len(tests) != 1 || tests[0] != "unit" {
+		t.Errorf("Expected Run to be run by unit, got %v", tests)
+	}
+	if len(file.Functions[1].Tests) != 0 {
+		t.Errorf("Expected unused to have no tests, got %v", file.Functions[1].Tests)
+	}
+
+	byTest := file.LinesByTest()
+	if len(byTest["unit"]) != 1 || len(byTest["TestOther"]) != 1 {
+		t.Errorf("Expected one line per test, got %v", byTest)
+	}
+}
@@ -0,0 +1,318 @@
+package models
+
+import "sort"
+
+// Merge adds the coverage of another report to this one. Files present in both
+// reports are merged line by line, other files are copied. The other report is
+// not modified and shares no data with this one afterwards.
+func (r *CoverageReport) Merge(other *CoverageReport) {
+	if r.TestName == "" {
+		r.TestName = other.TestName
+	}
+	if r.Metric == "" {
+		r.Metric = other.Metric
+	}
+
+	for name, file := range other.Files {
+		merged := r.Files[name]
+		if merged != nil {
+			merged.Merge(file)
+		} else {
+			merged = file.Clone()
+			r.Files[name] = merged
+		}
+		merged.CalculateCoverageFor(r.Metric)
+	}
+}
+
+// Merge adds the coverage of another run of the same file. Hit counts of lines,
+// functions and branches present in both are added up, and the totals are
+// recomputed from the merged data. Files that carry only totals and no line
+// data cannot be combined exactly, for them the larger totals are kept. Branch
+// totals larger than the recount of the merged branches are kept as well.
+func (fc *FileCoverage) Merge(other *FileCoverage) {
+	if fc.Package == "" {
+		fc.Package = other.Package
+	}
+	if fc.Origin == "" {
+		fc.Origin = other.Origin
+	}
+
+	hasLines := len(fc.Lines) > 0 && len(other.Lines) > 0
+	hasBranches := len(fc.Branches) > 0 && len(other.Branches) > 0
+	hasBlocks := len(fc.Blocks) > 0 && len(other.Blocks) > 0
+	hasRegions := len(fc.Regions) > 0 && len(other.Regions) > 0
+	// LCOV's BRF and BRH may count more branches than the BRDA records list
+	totalBranches := max(fc.TotalBranches, other.TotalBranches)
+	coveredBranches := max(fc.CoveredBranches, other.CoveredBranches)
+
+	fc.mergeLines(other.Lines)
+	fc.mergeExcludedLines(other.ExcludedLines)
+	fc.mergeFunctions(other.Functions)
+	fc.mergeClasses(other.Classes)
+	fc.mergeBranches(other.Branches)
+	fc.mergeBlocks(other.Blocks)
+	fc.mergeRegions(other.Regions)
+
+	if hasLines {
+		fc.CountLines()
+	} else {
+		fc.TotalLines = max(fc.TotalLines, other.TotalLines)
+		fc.CoveredLines = max(fc.CoveredLines, other.CoveredLines)
+	}
+
+	if hasBranches {
+		fc.CountBranches()
+	}
+	// The recount is kept unless a summary total exceeds it
+	fc.TotalBranches = max(fc.TotalBranches, totalBranches)
+	fc.CoveredBranches = max(fc.CoveredBranches, coveredBranches)
+
+	if hasBlocks {
+		fc.CountStatements()
+	} else {
+		fc.TotalStatements = max(fc.TotalStatements, other.TotalStatements)
+		fc.CoveredStatements = max(fc.CoveredStatements, other.CoveredStatements)
+	}
+
+	if hasRegions {
+		fc.CountRegions()
+	} else {
+		fc.TotalRegions = max(fc.TotalRegions, other.TotalRegions)
+		fc.CoveredRegions = max(fc.CoveredRegions, other.CoveredRegions)
+	}
+
+	// Instruction counters have no per-line data to union
+	fc.TotalInstructions = max(fc.TotalInstructions, other.TotalInstructions)
+	fc.CoveredInstructions = max(fc.CoveredInstructions, other.CoveredInstructions)
+
+	fc.CalculateCoverage()
+}
+
+// Clone returns a deep copy of the file coverage
+func (fc *FileCoverage) Clone() *FileCoverage {
+	clone := *fc
+	clone.Functions = append([]FunctionCoverage(nil), fc.Functions...)
+	for i := range clone.Functions {
+		clone.Functions[i].Tests = append([]string(nil), fc.Functions[i].Tests...)
+	}
+	clone.Classes = append([]ClassCoverage(nil), fc.Classes...)
+	clone.ExcludedLines = append([]int(nil), fc.ExcludedLines...)
+	clone.Branches = append([]BranchCoverage(nil), fc.Branches...)
+	clone.Blocks = append([]BlockCoverage(nil), fc.Blocks...)
+	clone.Regions = append([]RegionCoverage(nil), fc.Regions...)
+	if fc.Lines != nil {
+		clone.Lines = make(map[int]LineCoverage, len(fc.Lines))
+		for lineNum, line := range fc.Lines {
+			line.Tests = append([]string(nil), line.Tests...)
+			clone.Lines[lineNum] = line
+		}
+	}
+	return &clone
+}
+
+// CountLines sets the total and covered line counts from the Lines map
+func (fc *FileCoverage) CountLines() {
+	fc.TotalLines = len(fc.Lines)
+	fc.CoveredLines = 0
+	for _, line := range fc.Lines {
+		if line.ExecutionCount > 0 {
+			fc.CoveredLines++
+		}
+	}
+}
+
+// mergeLines unions the line maps, adding the hit counts of shared lines
+func (fc *FileCoverage) mergeLines(lines map[int]LineCoverage) {
+	if len(lines) == 0 {
+		return
+	}
+	if fc.Lines == nil {
+		fc.Lines = make(map[int]LineCoverage, len(lines))
+	}
+
+	for lineNum, line := range lines {
+		existing, exists := fc.Lines[lineNum]
+		if !exists {
+			line.Tests = append([]string(nil), line.Tests...)
+			fc.Lines[lineNum] = line
+			continue
+		}
+		existing.ExecutionCount += line.ExecutionCount
+		if existing.Checksum == "" {
+			existing.Checksum = line.Checksum
+		}
+		existing.Tests = mergeTests(existing.Tests, line.Tests)
+		fc.Lines[lineNum] = existing
+	}
+}
+
+// mergeTests returns the union of two test lists as a new list, keeping the
+// order in which they were first seen
+func mergeTests(tests, other []string) []string {
+	if len(other) == 0 {
+		return tests
+	}
+	seen := make(map[string]bool, len(tests))
+	merged := append([]string(nil), tests...)
+	for _, test := range tests {
+		seen[test] = true
+	}
+	for _, test := range other {
+		if !seen[test] {
+			seen[test] = true
+			merged = append(merged, test)
+		}
+	}
+	return merged
+}
+
+// mergeExcludedLines unions the excluded lines. A line excluded in one run
+// but measured in another stays measured.
+func (fc *FileCoverage) mergeExcludedLines(lines []int) {
+	if len(lines) == 0 && len(fc.ExcludedLines) == 0 {
+		return
+	}
+	excluded := make(map[int]bool, len(fc.ExcludedLines)+len(lines))
+	for _, list := range [][]int{fc.ExcludedLines, lines} {
+		for _, lineNum := range list {
+			if _, measured := fc.Lines[lineNum]; !measured {
+				excluded[lineNum] = true
+			}
+		}
+	}
+	fc.ExcludedLines = make([]int, 0, len(excluded))
+	for lineNum := range excluded {
+		fc.ExcludedLines = append(fc.ExcludedLines, lineNum)
+	}
+	sort.Ints(fc.ExcludedLines)
+}
+
+// mergeFunctions adds the execution counts of functions with the same name and line
+func (fc *FileCoverage) mergeFunctions(functions []FunctionCoverage) {
+	type functionKey struct {
+		name string
+		line int
+	}
+
+	index := make(map[functionKey]int, len(fc.Functions))
+	for i, fn := range fc.Functions {
+		index[functionKey{fn.Name, fn.LineNumber}] = i
+	}
+
+	for _, fn := range functions {
+		if i, exists := index[functionKey{fn.Name, fn.LineNumber}]; exists {
+			existing := &fc.Functions[i]
+			existing.ExecutionCount += fn.ExecutionCount
+			existing.Tests = mergeTests(existing.Tests, fn.Tests)
+			// Statement counts have no per-statement data to union
+			if fn.CoveredStatements > existing.CoveredStatements {
+				existing.TotalStatements = fn.TotalStatements
+				existing.CoveredStatements = fn.CoveredStatements
+				existing.CoveragePct = fn.CoveragePct
+			}
+			continue
+		}
+		index[functionKey{fn.Name, fn.LineNumber}] = len(fc.Functions)
+		fn.Tests = append([]string(nil), fn.Tests...)
+		fc.Functions = append(fc.Functions, fn)
+	}
+}
+
+// mergeClasses keeps the better covered run of classes with the same name and
+// line, their statement counts have no per-statement data to union
+func (fc *FileCoverage) mergeClasses(classes []ClassCoverage) {
+	type classKey struct {
+		name string
+		line int
+	}
+
+	index := make(map[classKey]int, len(fc.Classes))
+	for i, class := range fc.Classes {
+		index[classKey{class.Name, class.LineNumber}] = i
+	}
+
+	for _, class := range classes {
+		key := classKey{class.Name, class.LineNumber}
+		if i, exists := index[key]; exists {
+			if class.CoveredStatements > fc.Classes[i].CoveredStatements {
+				fc.Classes[i] = class
+			}
+			continue
+		}
+		index[key] = len(fc.Classes)
+		fc.Classes = append(fc.Classes, class)
+	}
+}
+
+// mergeBranches adds the taken counts of branches with the same line, block and id
+func (fc *FileCoverage) mergeBranches(branches []BranchCoverage) {
+	type branchKey struct {
+		line  int
+		block int
+		id    string
+	}
+
+	index := make(map[branchKey]int, len(fc.Branches))
+	for i, b := range fc.Branches {
+		index[branchKey{b.LineNumber, b.BlockNumber, b.BranchID}] = i
+	}
+
+	for _, b := range branches {
+		key := branchKey{b.LineNumber, b.BlockNumber, b.BranchID}
+		if i, exists := index[key]; exists {
+			existing := &fc.Branches[i]
+			existing.TakenCount += b.TakenCount
+			// A branch could be evaluated as soon as one of the runs reached it
+			existing.NotExecuted = existing.NotExecuted && b.NotExecuted
+			continue
+		}
+		index[key] = len(fc.Branches)
+		fc.Branches = append(fc.Branches, b)
+	}
+}
+
+// mergeBlocks adds the counts of blocks with the same position, the way
+// go tool cover combines profiles of several test binaries
+func (fc *FileCoverage) mergeBlocks(blocks []BlockCoverage) {
+	type blockKey struct {
+		startLine, startColumn, endLine, endColumn int
+	}
+
+	index := make(map[blockKey]int, len(fc.Blocks))
+	for i, b := range fc.Blocks {
+		index[blockKey{b.StartLine, b.StartColumn, b.EndLine, b.EndColumn}] = i
+	}
+
+	for _, b := range blocks {
+		key := blockKey{b.StartLine, b.StartColumn, b.EndLine, b.EndColumn}
+		if i, exists := index[key]; exists {
+			fc.Blocks[i].Count += b.Count
+			continue
+		}
+		index[key] = len(fc.Blocks)
+		fc.Blocks = append(fc.Blocks, b)
+	}
+}
+
+// mergeRegions adds the counts of regions with the same position
+func (fc *FileCoverage) mergeRegions(regions []RegionCoverage) {
+	type regionKey struct {
+		startLine, startColumn, endLine, endColumn int
+	}
+
+	index := make(map[regionKey]int, len(fc.Regions))
+	for i, r := range fc.Regions {
+		index[regionKey{r.StartLine, r.StartColumn, r.EndLine, r.EndColumn}] = i
+	}
+
+	for _, r := range regions {
+		key := regionKey{r.StartLine, r.StartColumn, r.EndLine, r.EndColumn}
+		if i, exists := index[key]; exists {
+			fc.Regions[i].Count += r.Count
+			continue
+		}
+		index[key] = len(fc.Regions)
+		fc.Regions = append(fc.Regions, r)
+	}
+}
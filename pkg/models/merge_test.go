@@ -0,0 +1,317 @@
+package models
+
+import (
+	"strconv"
+	"testing"
+)
+
+func newMergeTestFile(lines map[int]int) *FileCoverage {
+	fc := &FileCoverage{
+		FileName: "lib.rs",
+		Lines:    make(map[int]LineCoverage),
+	}
+	for lineNum, count := range lines {
+		fc.Lines[lineNum] = LineCoverage{LineNumber: lineNum, ExecutionCount: count}
+	}
+	fc.CountLines()
+	fc.CalculateCoverage()
+	return fc
+}
+
+func TestFileCoverageMerge_Lines(t *testing.T) {
+	unit := newMergeTestFile(map[int]int{1: 3, 2: 0, 3: 0})
+	integration := newMergeTestFile(map[int]int{2: 1, 3: 0, 4: 5})
+
+	unit.Merge(integration)
+
+	if unit.TotalLines != 4 {
+		t.Errorf("Expected 4 total lines, got %d", unit.TotalLines)
+	}
+	if unit.CoveredLines != 3 {
+		t.Errorf("Expected 3 covered lines, got %d", unit.CoveredLines)
+	}
+	if unit.CoveragePct != 75.0 {
+		t.Errorf("Expected 75%% coverage, got %.2f", unit.CoveragePct)
+	}
+	if unit.Lines[1].ExecutionCount != 3 || unit.Lines[2].ExecutionCount != 1 || unit.Lines[4].ExecutionCount != 5 {
+		t.Errorf("Expected hit counts to be added, got %v", unit.Lines)
+	}
+}
+
+func TestFileCoverageMerge_SameRunTwice(t *testing.T) {
+	fc := newMergeTestFile(map[int]int{1: 1, 2: 0})
+	fc.Merge(newMergeTestFile(map[int]int{1: 1, 2: 0}))
+
+	// The same lines must not be counted twice
+	if fc.TotalLines != 2 || fc.CoveredLines != 1 {
+		t.Errorf("Expected 2 total and 1 covered line, got %d and %d", fc.TotalLines, fc.CoveredLines)
+	}
+	if fc.Lines[1].ExecutionCount != 2 {
+		t.Errorf("Expected execution count 2, got %d", fc.Lines[1].ExecutionCount)
+	}
+}
+
+func TestFileCoverageMerge_Functions(t *testing.T) {
+	fc := newMergeTestFile(map[int]int{1: 1})
+	fc.Functions = []FunctionCoverage{
+		{Name: "parse", LineNumber: 1, ExecutionCount: 2},
+		{Name: "render", LineNumber: 10, ExecutionCount: 0},
+	}
+
+	other := newMergeTestFile(map[int]int{1: 1})
+	other.Functions = []FunctionCoverage{
+		{Name: "render", LineNumber: 10, ExecutionCount: 1},
+		{Name: "render", LineNumber: 20, ExecutionCount: 4},
+	}
+
+	fc.Merge(other)
+
+	if len(fc.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got %d", len(fc.Functions))
+	}
+	if fc.Functions[1].ExecutionCount != 1 {
+		t.Errorf("Expected render:10 to be executed once, got %d", fc.Functions[1].ExecutionCount)
+	}
+	if fc.Functions[2].LineNumber != 20 || fc.Functions[2].ExecutionCount != 4 {
+		t.Errorf("Expected render:20 to be appended, got %+v", fc.Functions[2])
+	}
+}
+
+func TestFileCoverageMerge_Branches(t *testing.T) {
+	fc := newMergeTestFile(map[int]int{5: 1})
+	fc.Branches = []BranchCoverage{
+		{LineNumber: 5, BranchID: "0", TakenCount: 1},
+		{LineNumber: 5, BranchID: "1", TakenCount: 0},
+		{LineNumber: 7, BranchID: "0", NotExecuted: true},
+	}
+	fc.CountBranches()
+
+	other := newMergeTestFile(map[int]int{5: 1})
+	other.Branches = []BranchCoverage{
+		{LineNumber: 5, BranchID: "1", TakenCount: 2},
+		{LineNumber: 7, BranchID: "0", TakenCount: 0},
+		{LineNumber: 9, BlockNumber: 1, BranchID: "0", TakenCount: 1},
+	}
+	other.CountBranches()
+
+	fc.Merge(other)
+
+	if fc.TotalBranches != 4 {
+		t.Errorf("Expected 4 total branches, got %d", fc.TotalBranches)
+	}
+	if fc.CoveredBranches != 3 {
+		t.Errorf("Expected 3 covered branches, got %d", fc.CoveredBranches)
+	}
+	if fc.Branches[2].NotExecuted {
+		t.Errorf("Expected branch reached by one run to be executed")
+	}
+	if fc.BranchPct != 75.0 {
+		t.Errorf("Expected 75%% branch coverage, got %.2f", fc.BranchPct)
+	}
+}
+
+func TestFileCoverageMerge_BranchSummaryTotals(t *testing.T) {
+	// BRF:6 and BRH:4 summarize more branches than the two BRDA records
+	fc := newMergeTestFile(map[int]int{5: 1})
+	fc.Branches = []BranchCoverage{
+		{LineNumber: 5, BranchID: "0", TakenCount: 1},
+		{LineNumber: 5, BranchID: "1", TakenCount: 0},
+	}
+	fc.TotalBranches, fc.CoveredBranches = 6, 4
+
+	other := newMergeTestFile(map[int]int{5: 1})
+	other.Branches = []BranchCoverage{
+		{LineNumber: 5, BranchID: "1", TakenCount: 1},
+	}
+	other.CountBranches()
+
+	fc.Merge(other)
+
+	if fc.TotalBranches != 6 || fc.CoveredBranches != 4 {
+		t.Errorf("Expected the summary totals 6/4, got %d/%d", fc.TotalBranches, fc.CoveredBranches)
+	}
+
+	// Once the records count more, the recount wins
+	more := newMergeTestFile(map[int]int{5: 1})
+	for i := range 6 {
+		more.Branches = append(more.Branches, BranchCoverage{LineNumber: 9, BranchID: strconv.Itoa(i), TakenCount: 1})
+	}
+	more.CountBranches()
+	fc.Merge(more)
+
+	if fc.TotalBranches != 8 || fc.CoveredBranches != 8 {
+		t.Errorf("Expected the recount 8/8, got %d/%d", fc.TotalBranches, fc.CoveredBranches)
+	}
+}
+
+func TestFileCoverageMerge_TotalsOnly(t *testing.T) {
+	fc := &FileCoverage{FileName: "a.go", TotalLines: 100, CoveredLines: 60, TotalInstructions: 400, CoveredInstructions: 100}
+	fc.Merge(&FileCoverage{FileName: "a.go", TotalLines: 100, CoveredLines: 80, TotalInstructions: 400, CoveredInstructions: 300})
+
+	if fc.TotalLines != 100 || fc.CoveredLines != 80 {
+		t.Errorf("Expected the larger totals 100/80, got %d/%d", fc.TotalLines, fc.CoveredLines)
+	}
+	if fc.CoveredInstructions != 300 {
+		t.Errorf("Expected 300 covered instructions, got %d", fc.CoveredInstructions)
+	}
+}
+
+func TestCoverageReportMerge(t *testing.T) {
+	report := NewCoverageReport()
+	report.AddFile(newMergeTestFile(map[int]int{1: 1, 2: 0}))
+
+	other := NewCoverageReport()
+	other.TestName = "integration"
+	otherFile := newMergeTestFile(map[int]int{2: 1})
+	other.AddFile(otherFile)
+	extra := newMergeTestFile(map[int]int{1: 0})
+	extra.FileName = "main.rs"
+	other.AddFile(extra)
+
+	report.Merge(other)
+
+	if report.TestName != "integration" {
+		t.Errorf("Expected test name to be taken over, got %q", report.TestName)
+	}
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(report.Files))
+	}
+
+	total, covered, _ := report.CalculateOverallCoverage()
+	if total != 3 || covered != 2 {
+		t.Errorf("Expected 3 total and 2 covered lines, got %d and %d", total, covered)
+	}
+
+	// Copied files do not share data with the other report
+	report.GetFile("main.rs").Lines[1] = LineCoverage{LineNumber: 1, ExecutionCount: 9}
+	if extra.Lines[1].ExecutionCount != 0 {
+		t.Errorf("Expected merged report not to share lines with its input")
+	}
+	if otherFile.Lines[2].ExecutionCount != 1 {
+		t.Errorf("Expected input file to be unchanged, got %d", otherFile.Lines[2].ExecutionCount)
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	fc := newMergeTestFile(map[int]int{1: 1, 2: 0, 3: 2})
+
+	if fc.TotalLines != 3 || fc.CoveredLines != 2 {
+		t.Errorf("Expected 3 total and 2 covered lines, got %d and %d", fc.TotalLines, fc.CoveredLines)
+	}
+}
+
+func TestFileCoverageMerge_Blocks(t *testing.T) {
+	fc := &FileCoverage{FileName: "a.go", Blocks: []BlockCoverage{
+		{StartLine: 1, StartColumn: 10, EndLine: 3, EndColumn: 2, NumStatements: 2, Count: 0},
+		{StartLine: 5, StartColumn: 1, EndLine: 6, EndColumn: 2, NumStatements: 1, Count: 1},
+	}}
+	fc.CountStatements()
+	fc.Merge(&FileCoverage{FileName: "a.go", Blocks: []BlockCoverage{
+		{StartLine: 1, StartColumn: 10, EndLine: 3, EndColumn: 2, NumStatements: 2, Count: 4},
+		{StartLine: 8, StartColumn: 1, EndLine: 8, EndColumn: 9, NumStatements: 1, Count: 0},
+	}})
+
+	if len(fc.Blocks) != 3 || fc.Blocks[0].Count != 4 {
+		t.Fatalf("Expected 3 blocks with merged counts, got %+v", fc.Blocks)
+	}
+	if fc.TotalStatements != 4 || fc.CoveredStatements != 3 {
+		t.Errorf("Expected 3/4 statements, got %d/%d", fc.CoveredStatements, fc.TotalStatements)
+	}
+}
+
+func TestFileCoverageMerge_Regions(t *testing.T) {
+	fc := &FileCoverage{FileName: "main.rs", Regions: []RegionCoverage{
+		{StartLine: 1, StartColumn: 1, EndLine: 3, EndColumn: 2, Count: 1},
+		{StartLine: 2, StartColumn: 8, EndLine: 2, EndColumn: 20, Count: 0},
+	}}
+	fc.CountRegions()
+	fc.Merge(&FileCoverage{FileName: "main.rs", Regions: []RegionCoverage{
+		{StartLine: 2, StartColumn: 8, EndLine: 2, EndColumn: 20, Count: 3},
+		{StartLine: 5, StartColumn: 1, EndLine: 6, EndColumn: 2, Count: 0},
+	}})
+
+	if len(fc.Regions) != 3 || fc.Regions[1].Count != 3 {
+		t.Fatalf("Expected 3 regions with merged counts, got %+v", fc.Regions)
+	}
+	if fc.TotalRegions != 3 || fc.CoveredRegions != 2 {
+		t.Errorf("Expected 2/3 regions, got %d/%d", fc.CoveredRegions, fc.TotalRegions)
+	}
+}
+
+func TestCoverageReportMerge_KeepsMetric(t *testing.T) {
+	goReport := NewCoverageReport()
+	goReport.Metric = MetricStatement
+	file := &FileCoverage{FileName: "a.go", TotalLines: 4, CoveredLines: 2, Blocks: []BlockCoverage{
+		{StartLine: 1, EndLine: 2, NumStatements: 9, Count: 1},
+		{StartLine: 3, EndLine: 4, NumStatements: 1, Count: 0},
+	}}
+	file.CountStatements()
+	goReport.AddFile(file)
+
+	merged := NewCoverageReport()
+	merged.Merge(goReport)
+
+	if merged.Metric != MetricStatement {
+		t.Errorf("Expected statement metric, got %s", merged.Metric)
+	}
+	if merged.Files["a.go"].CoveragePct != 90.0 {
+		t.Errorf("Expected 90%% statement coverage, got %.2f", merged.Files["a.go"].CoveragePct)
+	}
+}
+
+func TestFileCoverageMerge_KeepsOrigin(t *testing.T) {
+	fc := newMergeTestFile(map[int]int{1: 1})
+	other := newMergeTestFile(map[int]int{2: 1})
+	other.Origin = "artifacts.zip!lcov.info"
+
+	fc.Merge(other)
+	if fc.Origin != "artifacts.zip!lcov.info" {
+		t.Errorf("Expected the origin of the merged file, got %q", fc.Origin)
+	}
+
+	other.Origin = "artifacts.zip!other.info"
+	fc.Merge(other)
+	if fc.Origin != "artifacts.zip!lcov.info" {
+		t.Errorf("Expected the first origin to be kept, got %q", fc.Origin)
+	}
+}
+
+func TestFileCoverageMerge_TestsAndExcludedLines(t *testing.T) {
+	unit := newMergeTestFile(map[int]int{1: 1, 2: 0})
+	unit.Lines[1] = LineCoverage{LineNumber: 1, ExecutionCount: 1, Tests: []string{"test_a"}}
+	unit.ExcludedLines = []int{5, 6}
+	unit.Classes = []ClassCoverage{{Name: "Cart", LineNumber: 1, TotalStatements: 2, CoveredStatements: 1}}
+
+	integration := newMergeTestFile(map[int]int{1: 1, 5: 1})
+	integration.Lines[1] = LineCoverage{LineNumber: 1, ExecutionCount: 1, Tests: []string{"test_b", "test_a"}}
+	integration.ExcludedLines = []int{7}
+	integration.Classes = []ClassCoverage{{Name: "Cart", LineNumber: 1, TotalStatements: 2, CoveredStatements: 2}}
+
+	unit.Merge(integration)
+
+	if tests := unit.Lines[1].Tests; len(tests) != 2 || tests[0] != "test_a" || tests[1] != "test_b" {
+		t.Errorf("Expected tests test_a and test_b, got %v", tests)
+	}
+	if len(integration.Lines[1].Tests) != 2 {
+		t.Errorf("Expected the other file's tests to be unchanged, got %v", integration.Lines[1].Tests)
+	}
+	// Line 5 was measured by the integration run
+	if len(unit.ExcludedLines) != 2 || unit.ExcludedLines[0] != 6 || unit.ExcludedLines[1] != 7 {
+		t.Errorf("Expected excluded lines 6 and 7, got %v", unit.ExcludedLines)
+	}
+	if len(unit.Classes) != 1 || unit.Classes[0].CoveredStatements != 2 {
+		t.Errorf("Expected the better covered class, got %+v", unit.Classes)
+	}
+
+	// Functions run by both keep both tests
+	unit.Functions = []FunctionCoverage{{Name: "total", LineNumber: 1, ExecutionCount: 1, Tests: []string{"test_a"}}}
+	unit.Merge(&FileCoverage{FileName: unit.FileName, Functions: []FunctionCoverage{{Name: "total", LineNumber: 1, ExecutionCount: 1, Tests: []string{"test_c"}}}})
+	if tests := unit.Functions[0].Tests; len(tests) != 2 || tests[1] != "test_c" {
+		t.Errorf("Expected total to be run by test_a and test_c, got %v", tests)
+	}
+
+	byTest := unit.LinesByTest()
+	if len(byTest["test_b"]) != 1 || byTest["test_b"][0] != 1 {
+		t.Errorf("Expected test_b to run line 1, got %v", byTest)
+	}
+}
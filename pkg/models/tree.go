@@ -0,0 +1,230 @@
+package models
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GroupBy selects how files are rolled up into groups
+type GroupBy string
+
+const (
+	// GroupByDir groups files by the directory they are in
+	GroupByDir GroupBy = "dir"
+	// GroupByPackage groups files by package: the package or namespace the
+	// format reports, the directory for Go import paths and the dotted
+	// package name for Python files
+	GroupByPackage GroupBy = "package"
+	// GroupByDepth groups files by the first directories of their path
+	GroupByDepth GroupBy = "depth"
+)
+
+// rootGroup is the group of files without a directory
+const rootGroup = "."
+
+// CoverageNode is a directory, package or file in a coverage tree. The totals
+// of a directory or package add up those of all files below it, Files counts
+// them. File is only set for files. Lines count statements when the report
+// uses MetricStatement and the file has statement data.
+type CoverageNode struct {
+	Name             string
+	Path             string
+	Files            int
+	TotalLines       int
+	CoveredLines     int
+	CoveragePct      float64
+	TotalFunctions   int
+	CoveredFunctions int
+	FunctionPct      float64
+	TotalBranches    int
+	CoveredBranches  int
+	BranchPct        float64
+	File             *FileCoverage   `json:"-"`
+	Children         []*CoverageNode `json:",omitempty"`
+}
+
+// IsFile reports whether the node is a file rather than a group of files
+func (n *CoverageNode) IsFile() bool {
+	return n.File != nil
+}
+
+// add adds the totals of a file to the node
+func (n *CoverageNode) add(fc *FileCoverage, metric Metric) {
+	total, covered := fc.Counts(metric)
+	n.Files++
+	n.TotalLines += total
+	n.CoveredLines += covered
+	n.TotalFunctions += len(fc.Functions)
+	for _, fn := range fc.Functions {
+		if fn.ExecutionCount > 0 {
+			n.CoveredFunctions++
+		}
+	}
+	n.TotalBranches += fc.TotalBranches
+	n.CoveredBranches += fc.CoveredBranches
+}
+
+// calculatePercentages sets the coverage percentages from the totals
+func (n *CoverageNode) calculatePercentages() {
+	n.CoveragePct = Percent(n.CoveredLines, n.TotalLines)
+	n.FunctionPct = Percent(n.CoveredFunctions, n.TotalFunctions)
+	n.BranchPct = Percent(n.CoveredBranches, n.TotalBranches)
+}
+
+// newFileNode returns the leaf node of a file
+func newFileNode(fc *FileCoverage, metric Metric) *CoverageNode {
+	node := &CoverageNode{
+		Name: path.Base(filepath.ToSlash(fc.FileName)),
+		Path: fc.FileName,
+		File: fc,
+	}
+	node.add(fc, metric)
+	node.calculatePercentages()
+	return node
+}
+
+// Tree returns the directory hierarchy of the report's files, with the files
+// as leaves. The root has an empty name and path and holds the totals of the
+// whole report. Directories holding nothing but one other directory are
+// joined with it, so a tree of absolute paths such as /build/src/... starts
+// at the first directory that branches. Children list directories before
+// files, each in name order.
+func (r *CoverageReport) Tree() *CoverageNode {
+	root := &CoverageNode{}
+	dirs := map[string]*CoverageNode{"": root}
+
+	for _, fc := range r.Files {
+		name := filepath.ToSlash(fc.FileName)
+		parent := root
+		dirPath := ""
+		parts := strings.Split(name, "/")
+		for i, part := range parts[:len(parts)-1] {
+			switch {
+			case i == 0 && part == "":
+				dirPath, part = "/", "/"
+			case dirPath == "" || dirPath == "/":
+				dirPath += part
+			default:
+				dirPath += "/" + part
+			}
+			if part == "" {
+				// Doubled separators
+				continue
+			}
+			dir := dirs[dirPath]
+			if dir == nil {
+				dir = &CoverageNode{Name: part, Path: dirPath}
+				dirs[dirPath] = dir
+				parent.Children = append(parent.Children, dir)
+			}
+			dir.add(fc, r.Metric)
+			parent = dir
+		}
+		root.add(fc, r.Metric)
+		parent.Children = append(parent.Children, newFileNode(fc, r.Metric))
+	}
+
+	for _, child := range root.Children {
+		compactTree(child)
+	}
+	finishTree(root)
+	return root
+}
+
+// compactTree joins directories holding nothing but one other directory with it
+func compactTree(node *CoverageNode) {
+	for len(node.Children) == 1 && !node.Children[0].IsFile() {
+		child := node.Children[0]
+		if node.Name == "/" {
+			node.Name = "/" + child.Name
+		} else {
+			node.Name += "/" + child.Name
+		}
+		node.Path = child.Path
+		node.Children = child.Children
+	}
+	for _, child := range node.Children {
+		if !child.IsFile() {
+			compactTree(child)
+		}
+	}
+}
+
+// finishTree sorts the children of every directory and computes its percentages
+func finishTree(node *CoverageNode) {
+	node.calculatePercentages()
+	sort.Slice(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.IsFile() != b.IsFile() {
+			return !a.IsFile()
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range node.Children {
+		if !child.IsFile() {
+			finishTree(child)
+		}
+	}
+}
+
+// Group rolls the report's files up into one node per group, in name order.
+// The files of a group are its children, in name order. Depth is the number
+// of directories kept with GroupByDepth and ignored otherwise.
+func (r *CoverageReport) Group(by GroupBy, depth int) []*CoverageNode {
+	groups := make(map[string]*CoverageNode)
+	for _, fc := range r.Files {
+		key := fc.GroupKey(by, depth)
+		group := groups[key]
+		if group == nil {
+			group = &CoverageNode{Name: key, Path: key}
+			groups[key] = group
+		}
+		group.add(fc, r.Metric)
+		group.Children = append(group.Children, newFileNode(fc, r.Metric))
+	}
+
+	nodes := make([]*CoverageNode, 0, len(groups))
+	for _, group := range groups {
+		group.calculatePercentages()
+		sort.Slice(group.Children, func(i, j int) bool {
+			return group.Children[i].Path < group.Children[j].Path
+		})
+		nodes = append(nodes, group)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Path < nodes[j].Path
+	})
+	return nodes
+}
+
+// GroupKey returns the group a file belongs to, "." for files without a
+// directory. Depth is the number of directories kept with GroupByDepth.
+func (fc *FileCoverage) GroupKey(by GroupBy, depth int) string {
+	dir := path.Dir(filepath.ToSlash(fc.FileName))
+
+	switch by {
+	case GroupByPackage:
+		if fc.Package != "" {
+			return fc.Package
+		}
+		if path.Ext(fc.FileName) == ".py" && dir != rootGroup {
+			return strings.ReplaceAll(strings.TrimPrefix(dir, "/"), "/", ".")
+		}
+	case GroupByDepth:
+		if dir == rootGroup || dir == "/" || depth <= 0 {
+			return dir
+		}
+		prefix := ""
+		if strings.HasPrefix(dir, "/") {
+			prefix, dir = "/", dir[1:]
+		}
+		parts := strings.Split(dir, "/")
+		if len(parts) > depth {
+			parts = parts[:depth]
+		}
+		return prefix + strings.Join(parts, "/")
+	}
+	return dir
+}
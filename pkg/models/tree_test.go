@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+)
+
+// newTreeTestReport returns a report of files with the given covered and
+// total line counts
+func newTreeTestReport(files map[string][2]int) *CoverageReport {
+	report := NewCoverageReport()
+	for name, counts := range files {
+		fc := &FileCoverage{FileName: name, CoveredLines: counts[0], TotalLines: counts[1]}
+		fc.CalculateCoverage()
+		report.AddFile(fc)
+	}
+	return report
+}
+
+func TestCoverageReportTree(t *testing.T) {
+	report := newTreeTestReport(map[string][2]int{
+		"/build/src/pkg/parser/lcov.go":   {8, 10},
+		"/build/src/pkg/parser/jacoco.go": {2, 10},
+		"/build/src/pkg/models/model.go":  {5, 5},
+		"/build/src/main.go":              {0, 5},
+	})
+	report.Files["/build/src/main.go"].Functions = []FunctionCoverage{{Name: "main", ExecutionCount: 0}}
+	report.Files["/build/src/pkg/models/model.go"].Functions = []FunctionCoverage{{Name: "New", ExecutionCount: 2}}
+	report.Files["/build/src/pkg/parser/lcov.go"].TotalBranches = 4
+	report.Files["/build/src/pkg/parser/lcov.go"].CoveredBranches = 1
+
+	root := report.Tree()
+	if root.Files != 4 || root.TotalLines != 30 || root.CoveredLines != 15 || root.CoveragePct != 50 {
+		t.Errorf("Expected the root to hold 15 of 30 lines in 4 files, got %+v", root)
+	}
+	if root.TotalFunctions != 2 || root.CoveredFunctions != 1 || root.BranchPct != 25 {
+		t.Errorf("Expected 1 of 2 functions and 25%% branch coverage, got %+v", root)
+	}
+
+	// The directories above the first branching one are joined
+	if len(root.Children) != 1 {
+		t.Fatalf("Expected a single top level directory, got %d", len(root.Children))
+	}
+	src := root.Children[0]
+	if src.Name != "/build/src" || src.Path != "/build/src" || src.Files != 4 {
+		t.Errorf("Expected /build/src with 4 files, got %+v", src)
+	}
+
+	// Directories come before files
+	if len(src.Children) != 2 || src.Children[0].Name != "pkg" || src.Children[1].Name != "main.go" {
+		t.Fatalf("Expected pkg and main.go below /build/src, got %+v", src.Children)
+	}
+	if !src.Children[1].IsFile() || src.Children[1].File != report.Files["/build/src/main.go"] {
+		t.Errorf("Expected main.go to be a file, got %+v", src.Children[1])
+	}
+
+	pkg := src.Children[0]
+	if len(pkg.Children) != 2 || pkg.Children[0].Name != "models" || pkg.Children[1].Name != "parser" {
+		t.Fatalf("Expected models and parser below pkg, got %+v", pkg.Children)
+	}
+	parser := pkg.Children[1]
+	if parser.Path != "/build/src/pkg/parser" || parser.TotalLines != 20 || parser.CoveredLines != 10 || parser.Files != 2 {
+		t.Errorf("Expected 10 of 20 lines in parser, got %+v", parser)
+	}
+	if parser.Children[0].Name != "jacoco.go" || parser.Children[0].CoveragePct != 20 {
+		t.Errorf("Expected jacoco.go first, got %+v", parser.Children[0])
+	}
+}
+
+func TestCoverageReportTree_RelativePaths(t *testing.T) {
+	report := newTreeTestReport(map[string][2]int{
+		"main.go":         {1, 1},
+		"cmd/app/app.go":  {1, 2},
+		"cmd/tool/run.go": {0, 2},
+	})
+
+	root := report.Tree()
+	if len(root.Children) != 2 || root.Children[0].Name != "cmd" || root.Children[1].Name != "main.go" {
+		t.Fatalf("Expected cmd and main.go at the top, got %+v", root.Children)
+	}
+	if cmd := root.Children[0]; len(cmd.Children) != 2 || cmd.Children[0].Path != "cmd/app" || cmd.CoveredLines != 1 {
+		t.Errorf("Expected cmd/app and cmd/tool below cmd, got %+v", cmd)
+	}
+}
+
+func TestCoverageReportGroup(t *testing.T) {
+	report := newTreeTestReport(map[string][2]int{
+		"example.com/shop/cart/cart.go":  {3, 4},
+		"example.com/shop/cart/price.go": {1, 4},
+		"example.com/shop/main.go":       {0, 2},
+	})
+
+	groups := report.Group(GroupByDir, 0)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 directories, got %+v", groups)
+	}
+	cart := groups[1]
+	if cart.Name != "example.com/shop/cart" || cart.Files != 2 || cart.CoveragePct != 50 {
+		t.Errorf("Expected 50%% coverage of example.com/shop/cart, got %+v", cart)
+	}
+	if len(cart.Children) != 2 || cart.Children[0].Path != "example.com/shop/cart/cart.go" {
+		t.Errorf("Expected the files of the group as children, got %+v", cart.Children)
+	}
+
+	groups = report.Group(GroupByDepth, 2)
+	if len(groups) != 1 || groups[0].Name != "example.com/shop" || groups[0].TotalLines != 10 {
+		t.Errorf("Expected a single group example.com/shop, got %+v", groups)
+	}
+}
+
+func TestFileCoverageGroupKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		by       GroupBy
+		depth    int
+		expected string
+	}{
+		{"src/shop/cart.py", "", GroupByDir, 0, "src/shop"},
+		{"cart.py", "", GroupByDir, 0, "."},
+		{"src/shop/cart.py", "", GroupByPackage, 0, "src.shop"},
+		{"cart.py", "", GroupByPackage, 0, "."},
+		{"example.com/shop/cart/cart.go", "", GroupByPackage, 0, "example.com/shop/cart"},
+		{"src/main/java/shop/Cart.java", "shop", GroupByPackage, 0, "shop"},
+		{"a/b/c/d.ts", "", GroupByDepth, 2, "a/b"},
+		{"a/d.ts", "", GroupByDepth, 2, "a"},
+		{"/build/a/b/d.ts", "", GroupByDepth, 2, "/build/a"},
+		{"d.ts", "", GroupByDepth, 2, "."},
+	}
+	for _, tt := range tests {
+		fc := &FileCoverage{FileName: tt.name, Package: tt.pkg}
+		if key := fc.GroupKey(tt.by, tt.depth); key != tt.expected {
+			t.Errorf("%s grouped by %s: expected %q, got %q", tt.name, tt.by, tt.expected, key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// Benchmark sizes: files per report and executable lines per file
+const (
+	benchFiles        = 200
+	benchLinesPerFile = 500
+)
+
+// createBenchReport builds a synthetic report with lines, functions and branches
+func createBenchReport(files, linesPerFile int) *models.CoverageReport {
+	report := models.NewCoverageReport()
+	for f := 0; f < files; f++ {
+		file := &models.FileCoverage{
+			FileName: fmt.Sprintf("pkg/module%d/file%d.go", f%20, f),
+			Lines:    make(map[int]models.LineCoverage, linesPerFile),
+		}
+		for line := 1; line <= linesPerFile; line++ {
+			file.Lines[line] = models.LineCoverage{LineNumber: line, ExecutionCount: line % 3}
+			if line%10 == 0 {
+				file.Functions = append(file.Functions, models.FunctionCoverage{
+					Name:           fmt.Sprintf("fn%d", line),
+					LineNumber:     line,
+					ExecutionCount: line % 2,
+				})
+				file.Branches = append(file.Branches,
+					models.BranchCoverage{LineNumber: line, BranchID: "0", TakenCount: 1},
+					models.BranchCoverage{LineNumber: line, BranchID: "1", TakenCount: 0},
+				)
+			}
+		}
+		file.CountLines()
+		file.CountBranches()
+		report.AddFile(file)
+	}
+	return report
+}
+
+// writeBenchFixture renders the synthetic report in a format that has a writer
+func writeBenchFixture(b *testing.B, format string) []byte {
+	b.Helper()
+
+	var buf bytes.Buffer
+	if err := LookupWriter(format).Write(&buf, createBenchReport(benchFiles, benchLinesPerFile)); err != nil {
+		b.Fatalf("Failed to write %s fixture: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// createIstanbulBenchFixture renders a coverage-final.json with one statement per line
+func createIstanbulBenchFixture() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for f := 0; f < benchFiles; f++ {
+		if f > 0 {
+			buf.WriteString(",")
+		}
+		name := fmt.Sprintf("/src/module%d/file%d.ts", f%20, f)
+		var statements, counts []string
+		for line := 1; line <= benchLinesPerFile; line++ {
+			statements = append(statements, fmt.Sprintf(`"%d":{"start":{"line":%d,"column":0},"end":{"line":%d,"column":10}}`, line, line, line))
+			counts = append(counts, fmt.Sprintf(`"%d":%d`, line, line%3))
+		}
+		fmt.Fprintf(&buf, `"%s":{"path":"%s","statementMap":{%s},"fnMap":{},"branchMap":{},"s":{%s},"f":{},"b":{}}`,
+			name, name, strings.Join(statements, ","), strings.Join(counts, ","))
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// createJaCoCoBenchFixture renders a JaCoCo report with one class per source file
+func createJaCoCoBenchFixture() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?><report name="bench">`)
+	for f := 0; f < benchFiles; f++ {
+		if f%20 == 0 {
+			if f > 0 {
+				buf.WriteString("</package>")
+			}
+			fmt.Fprintf(&buf, `<package name="com/example/module%d">`, f/20)
+		}
+		fmt.Fprintf(&buf, `<class name="com/example/File%d" sourcefilename="File%d.java"><method name="run" desc="()V" line="1"><counter type="METHOD" missed="0" covered="1"/></method></class>`, f, f)
+		fmt.Fprintf(&buf, `<sourcefile name="File%d.java">`, f)
+		for line := 1; line <= benchLinesPerFile; line++ {
+			fmt.Fprintf(&buf, `<line nr="%d" mi="%d" ci="%d" mb="0" cb="0"/>`, line, line%2, (line+1)%2)
+		}
+		buf.WriteString("</sourcefile>")
+	}
+	buf.WriteString("</package></report>")
+	return buf.Bytes()
+}
+
+// benchmarkParse parses data with a fresh parser for the format on every iteration
+func benchmarkParse(b *testing.B, format string, data []byte) {
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		report, err := Lookup(format).Parse(bytes.NewReader(data))
+		if err != nil {
+			b.Fatalf("Failed to parse %s: %v", format, err)
+		}
+		if len(report.Files) != benchFiles {
+			b.Fatalf("Expected %d files, got: %d", benchFiles, len(report.Files))
+		}
+	}
+}
+
+func BenchmarkLCOVParser_Parse(b *testing.B) {
+	benchmarkParse(b, "lcov", writeBenchFixture(b, "lcov"))
+}
+
+func BenchmarkGoCoverParser_Parse(b *testing.B) {
+	benchmarkParse(b, "go", writeBenchFixture(b, "go"))
+}
+
+func BenchmarkCoberturaXMLParser_Parse(b *testing.B) {
+	benchmarkParse(b, "cobertura", writeBenchFixture(b, "cobertura"))
+}
+
+func BenchmarkPyCoverJSONParser_Parse(b *testing.B) {
+	benchmarkParse(b, "pyjson", writeBenchFixture(b, "pyjson"))
+}
+
+func BenchmarkIstanbulJSONParser_Parse(b *testing.B) {
+	benchmarkParse(b, "istanbul", createIstanbulBenchFixture())
+}
+
+func BenchmarkJaCoCoXMLParser_Parse(b *testing.B) {
+	benchmarkParse(b, "jacoco", createJaCoCoBenchFixture())
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// isCloverRoot reports whether a <coverage> root belongs to a Clover report,
+// which unlike Cobertura's carries a generated or clover attribute and
+// holds a <project>
+func isCloverRoot(doc *xmlHeader) bool {
+	return doc.hasAttr("generated") || doc.hasAttr("clover") || doc.hasChild("project")
+}
+
+// CloverXMLParser parses Clover XML coverage reports, as written by PHPUnit
+// and istanbul's clover reporter
+type CloverXMLParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewCloverXMLParser creates a new Clover XML coverage parser instance
+func NewCloverXMLParser() *CloverXMLParser {
+	return &CloverXMLParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// CloverFile represents a <file> element. istanbul writes the relative name
+// and the absolute path, PHPUnit only the absolute name.
+type CloverFile struct {
+	Name    string        `xml:"name,attr"`
+	Path    string        `xml:"path,attr"`
+	Classes []CloverClass `xml:"class"`
+	Lines   []CloverLine  `xml:"line"`
+}
+
+// CloverClass represents a class declared in a file
+type CloverClass struct {
+	Name      string `xml:"name,attr"`
+	Namespace string `xml:"namespace,attr"`
+}
+
+// CloverLine represents a <line> element. Type is "stmt" for statements,
+// "method" for method declarations and "cond" for conditionals, which also
+// count how often they were true and false.
+type CloverLine struct {
+	Num        int    `xml:"num,attr"`
+	Type       string `xml:"type,attr"`
+	Name       string `xml:"name,attr"`
+	Count      int    `xml:"count,attr"`
+	TrueCount  int    `xml:"truecount,attr"`
+	FalseCount int    `xml:"falsecount,attr"`
+}
+
+// Parse reads and parses a Clover XML file. Files are decoded one at a time.
+func (p *CloverXMLParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	pkgName := ""
+
+	decoder := xml.NewDecoder(reader)
+	_, err := walkXML(decoder, "coverage", func(start *xml.StartElement) (bool, error) {
+		switch start.Name.Local {
+		case "project":
+			report.TestName = xmlAttr(start, "name")
+		case "package":
+			pkgName = xmlAttr(start, "name")
+		case "file":
+			var file CloverFile
+			if err := decoder.DecodeElement(&file, start); err != nil {
+				return true, err
+			}
+			p.parseFile(pkgName, file, report)
+			return true, nil
+		}
+		return false, nil
+	}, func(name string) {
+		if name == "package" {
+			pkgName = ""
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile maps the lines of a <file> to statement lines, functions and branches
+func (p *CloverXMLParser) parseFile(pkgName string, cloverFile CloverFile, report *models.CoverageReport) {
+	filename := cloverFile.Path
+	if filename == "" {
+		filename = cloverFile.Name
+	}
+	if filename == "" {
+		p.addWarning("file", CodeMissingData, "file entry without a name")
+		return
+	}
+
+	// PHPUnit writes no packages, the namespace of the file's classes stands in
+	if pkgName == "" && len(cloverFile.Classes) > 0 {
+		pkgName = cloverFile.Classes[0].Namespace
+	}
+
+	file := &models.FileCoverage{
+		FileName:  filename,
+		Package:   pkgName,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+
+	for _, line := range cloverFile.Lines {
+		if line.Num <= 0 {
+			p.addWarning("line", CodeInvalidValue, fmt.Sprintf("file %s: invalid line number %d", filename, line.Num))
+			continue
+		}
+		if line.Count < 0 || line.TrueCount < 0 || line.FalseCount < 0 {
+			p.addWarning("line", CodeInvalidValue, fmt.Sprintf("file %s: line %d has negative counts", filename, line.Num))
+			continue
+		}
+
+		switch line.Type {
+		case "method":
+			// Method declarations are not statements
+			file.Functions = append(file.Functions, models.FunctionCoverage{
+				Name:           line.Name,
+				LineNumber:     line.Num,
+				ExecutionCount: line.Count,
+			})
+		case "stmt", "cond":
+			// A line listed twice keeps its highest count
+			if existing, ok := file.Lines[line.Num]; !ok || existing.ExecutionCount < line.Count {
+				file.Lines[line.Num] = models.LineCoverage{
+					LineNumber:     line.Num,
+					ExecutionCount: line.Count,
+				}
+			}
+			if line.Type == "stmt" {
+				continue
+			}
+			// Branch 0 is the true outcome, branch 1 the false one. Conditions
+			// on lines that never ran could not be evaluated.
+			for i, taken := range []int{line.TrueCount, line.FalseCount} {
+				file.Branches = append(file.Branches, models.BranchCoverage{
+					LineNumber:  line.Num,
+					BranchID:    strconv.Itoa(i),
+					TakenCount:  taken,
+					NotExecuted: line.Count == 0,
+				})
+			}
+		default:
+			p.addWarning("line", CodeUnknownRecord, fmt.Sprintf("file %s: line %d has unknown type %q", filename, line.Num, line.Type))
+		}
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	if existing := report.GetFile(filename); existing != nil {
+		existing.Merge(file)
+		return
+	}
+	report.AddFile(file)
+}
+
+// Name returns the canonical format name
+func (p *CloverXMLParser) Name() string {
+	return "clover"
+}
+
+// Description returns a human readable format name
+func (p *CloverXMLParser) Description() string {
+	return "Clover XML Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "clover"
+func (p *CloverXMLParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names Clover reports are usually written to
+func (p *CloverXMLParser) FilePatterns() []string {
+	return []string{"*clover*.xml"}
+}
+
+// Sniff is confident when the document has a Clover <coverage> root
+func (p *CloverXMLParser) Sniff(header []byte) Confidence {
+	if doc := sniffXML(header); doc.rootIs("coverage") && isCloverRoot(doc) {
+		return ConfidenceHigh
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document has a Clover <coverage> root
+func (p *CloverXMLParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *CloverXMLParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *CloverXMLParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *CloverXMLParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCloverXMLParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/clover.xml")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewCloverXMLParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	// Method declarations are functions, not lines
+	invoice := report.Files["/var/www/src/Billing/Invoice.php"]
+	if invoice == nil {
+		t.Fatalf("Expected file '/var/www/src/Billing/Invoice.php' not found")
+	}
+	if invoice.TotalLines != 5 || invoice.CoveredLines != 3 {
+		t.Errorf("Expected 3 of 5 lines covered, got: %d of %d", invoice.CoveredLines, invoice.TotalLines)
+	}
+	if invoice.Package != `App\Billing` {
+		t.Errorf("Expected the class namespace as package, got: %s", invoice.Package)
+	}
+	if len(invoice.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got: %+v", invoice.Functions)
+	}
+	if fn := invoice.Functions[1]; fn.Name != "total" || fn.LineNumber != 15 || fn.ExecutionCount != 0 {
+		t.Errorf("Unexpected second function: %+v", fn)
+	}
+
+	money := report.Files["/var/www/src/Support/Money.php"]
+	if money == nil {
+		t.Fatalf("Expected file '/var/www/src/Support/Money.php' not found")
+	}
+	if money.Package != `App\Support` {
+		t.Errorf("Expected package 'App\\Support', got: %s", money.Package)
+	}
+	if money.TotalLines != 2 || money.CoveredLines != 2 {
+		t.Errorf("Expected 2 of 2 lines covered, got: %d of %d", money.CoveredLines, money.TotalLines)
+	}
+	if money.TotalBranches != 2 || money.CoveredBranches != 1 {
+		t.Errorf("Expected 1 of 2 branches covered, got: %d of %d", money.CoveredBranches, money.TotalBranches)
+	}
+}
+
+func TestCloverXMLParser_Parse_Istanbul(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage generated="1760601600" clover="3.2.0">
+  <project timestamp="1760601600" name="All files">
+    <file name="cart.js" path="/app/src/cart.js">
+      <line num="1" count="2" type="stmt"/>
+      <line num="2" count="0" type="cond" truecount="0" falsecount="0"/>
+      <line num="3" count="1" type="loop"/>
+    </file>
+  </project>
+</coverage>`
+
+	parser := NewCloverXMLParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if report.TestName != "All files" {
+		t.Errorf("Expected test name 'All files', got: %s", report.TestName)
+	}
+	if len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeUnknownRecord {
+		t.Errorf("Expected one unknown-record warning, got: %v", parser.Diagnostics())
+	}
+
+	// The absolute path is preferred over the name
+	file := report.Files["/app/src/cart.js"]
+	if file == nil {
+		t.Fatalf("Expected file '/app/src/cart.js' not found")
+	}
+	if file.TotalLines != 2 || file.TotalBranches != 2 {
+		t.Errorf("Expected 2 lines and 2 branches, got: %d and %d", file.TotalLines, file.TotalBranches)
+	}
+	for _, branch := range file.Branches {
+		if !branch.NotExecuted {
+			t.Errorf("Expected branches of a line that never ran to be not executed, got: %+v", branch)
+		}
+	}
+}
+
+func TestCloverXMLParser_Parse_InvalidXML(t *testing.T) {
+	if _, err := NewCloverXMLParser().Parse(strings.NewReader(`<coverage generated="1"><project>`)); err == nil {
+		t.Error("Expected error for truncated XML")
+	}
+}
@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// CoberturaXMLParser parses Cobertura XML coverage files, as written by
+// coverage.py, gcovr, coverlet, PHPUnit and istanbul's cobertura reporter
+type CoberturaXMLParser struct {
+	diagnostics []Diagnostic
+}
+
+// PyCoverXMLParser is the former name of CoberturaXMLParser.
+//
+// Deprecated: use CoberturaXMLParser, the parser is not specific to Python.
+type PyCoverXMLParser = CoberturaXMLParser
+
+// NewCoberturaXMLParser creates a new Cobertura XML coverage parser instance
+func NewCoberturaXMLParser() *CoberturaXMLParser {
+	return &CoberturaXMLParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// NewPyCoverXMLParser creates a new Cobertura XML coverage parser instance.
+//
+// Deprecated: use NewCoberturaXMLParser.
+func NewPyCoverXMLParser() *CoberturaXMLParser {
+	return NewCoberturaXMLParser()
+}
+
+// CoberturaCoverage represents the root <coverage> element. The rate and
+// count attributes are only used when writing, the parser recomputes them.
+type CoberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        string             `xml:"line-rate,attr,omitempty"`
+	BranchRate      string             `xml:"branch-rate,attr,omitempty"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      string             `xml:"complexity,attr,omitempty"`
+	Version         string             `xml:"version,attr,omitempty"`
+	Timestamp       string             `xml:"timestamp,attr,omitempty"`
+	Sources         []string           `xml:"sources>source,omitempty"`
+	Packages        []CoberturaPackage `xml:"packages>package"`
+}
+
+// CoberturaPackage represents a package in the coverage report
+type CoberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr,omitempty"`
+	BranchRate string           `xml:"branch-rate,attr,omitempty"`
+	Complexity string           `xml:"complexity,attr,omitempty"`
+	Classes    []CoberturaClass `xml:"classes>class"`
+}
+
+// CoberturaClass represents a class/file in the coverage report
+type CoberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   string            `xml:"line-rate,attr,omitempty"`
+	BranchRate string            `xml:"branch-rate,attr,omitempty"`
+	Complexity string            `xml:"complexity,attr,omitempty"`
+	Methods    []CoberturaMethod `xml:"methods>method"`
+	Lines      []CoberturaLine   `xml:"lines>line"`
+}
+
+// CoberturaMethod represents a method of a class
+type CoberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   string          `xml:"line-rate,attr,omitempty"`
+	BranchRate string          `xml:"branch-rate,attr,omitempty"`
+	Hits       string          `xml:"hits,attr,omitempty"`
+	Lines      []CoberturaLine `xml:"lines>line"`
+}
+
+// CoberturaLine represents a line in the coverage report
+type CoberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int    `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr,omitempty"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// conditionCoveragePattern matches the "(covered/total)" part of condition-coverage="50% (1/2)"
+var conditionCoveragePattern = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+// Parse reads and parses a Cobertura XML file. Classes are decoded one at a
+// time, so large reports are never held in memory as a whole.
+func (p *CoberturaXMLParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	var sources []string
+	pkgName := ""
+
+	decoder := xml.NewDecoder(reader)
+	_, err := walkXML(decoder, "coverage", func(start *xml.StartElement) (bool, error) {
+		switch start.Name.Local {
+		case "source":
+			// <sources> precede <packages>, so they are known before the first class
+			var source string
+			if err := decoder.DecodeElement(&source, start); err != nil {
+				return true, err
+			}
+			if source = strings.TrimSpace(source); source != "" {
+				sources = append(sources, source)
+			}
+			return true, nil
+		case "package":
+			pkgName = xmlAttr(start, "name")
+		case "class":
+			var class CoberturaClass
+			if err := decoder.DecodeElement(&class, start); err != nil {
+				return true, err
+			}
+			if err := p.parseClass(pkgName, class, sources, report); err != nil {
+				p.addWarning("class", CodeMissingData, fmt.Sprintf("failed to parse class %s: %v", class.Filename, err))
+			}
+			return true, nil
+		}
+		return false, nil
+	}, func(name string) {
+		if name == "package" {
+			pkgName = ""
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	// Count lines once per file, several classes may share one file
+	for _, file := range report.Files {
+		file.CountLines()
+		file.CountBranches()
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseClass parses a single class from the XML. Several classes may share one
+// source file (inner classes, partial classes), their data is combined.
+func (p *CoberturaXMLParser) parseClass(pkgName string, class CoberturaClass, sources []string, report *models.CoverageReport) error {
+	if class.Filename == "" {
+		return fmt.Errorf("missing filename attribute")
+	}
+
+	filename := resolveSourcePath(sources, class.Filename)
+
+	file := report.GetFile(filename)
+	if file == nil {
+		file = &models.FileCoverage{
+			FileName:  filename,
+			Package:   pkgName,
+			Functions: make([]models.FunctionCoverage, 0),
+			Lines:     make(map[int]models.LineCoverage),
+			Branches:  make([]models.BranchCoverage, 0),
+		}
+		report.AddFile(file)
+	}
+
+	// Process lines
+	for _, line := range class.Lines {
+		if line.Hits < 0 {
+			p.addWarning("line", CodeInvalidValue, fmt.Sprintf("file %s: line %d has negative hit count %d", filename, line.Number, line.Hits))
+		}
+
+		// A line reported by more than one class keeps its highest hit count
+		existing, exists := file.Lines[line.Number]
+		if exists && existing.ExecutionCount >= line.Hits {
+			continue
+		}
+		file.Lines[line.Number] = models.LineCoverage{
+			LineNumber:     line.Number,
+			ExecutionCount: line.Hits,
+		}
+		if exists {
+			continue
+		}
+
+		if line.Branch && line.ConditionCoverage != "" {
+			if err := p.parseConditionCoverage(line, file); err != nil {
+				p.addWarning("line", CodeInvalidValue, fmt.Sprintf("file %s: line %d: %v", filename, line.Number, err))
+			}
+		}
+	}
+
+	// Process methods
+	for _, method := range class.Methods {
+		file.Functions = append(file.Functions, p.parseMethod(method, filename))
+	}
+
+	return nil
+}
+
+// parseConditionCoverage expands condition-coverage="50% (1/2)" into branch entries.
+// Cobertura only records how many conditions were taken, not how often.
+func (p *CoberturaXMLParser) parseConditionCoverage(line CoberturaLine, file *models.FileCoverage) error {
+	match := conditionCoveragePattern.FindStringSubmatch(line.ConditionCoverage)
+	if match == nil {
+		return fmt.Errorf("invalid condition-coverage: %s", line.ConditionCoverage)
+	}
+
+	covered, _ := strconv.Atoi(match[1])
+	total, _ := strconv.Atoi(match[2])
+	if covered > total {
+		return fmt.Errorf("condition-coverage covers more conditions than exist: %s", line.ConditionCoverage)
+	}
+
+	for i := 0; i < total; i++ {
+		taken := 0
+		if i < covered {
+			taken = 1
+		}
+		file.Branches = append(file.Branches, models.BranchCoverage{
+			LineNumber: line.Number,
+			BranchID:   strconv.Itoa(i),
+			TakenCount: taken,
+		})
+	}
+
+	return nil
+}
+
+// parseMethod converts a <method> element to function coverage. The method's own
+// hits attribute is used when present, otherwise the hits of its first line.
+func (p *CoberturaXMLParser) parseMethod(method CoberturaMethod, filename string) models.FunctionCoverage {
+	fn := models.FunctionCoverage{
+		Name: method.Name,
+	}
+
+	for _, line := range method.Lines {
+		if fn.LineNumber == 0 || line.Number < fn.LineNumber {
+			fn.LineNumber = line.Number
+			fn.ExecutionCount = line.Hits
+		}
+	}
+
+	if method.Hits != "" {
+		hits, err := strconv.Atoi(method.Hits)
+		if err != nil {
+			p.addWarning("method", CodeInvalidValue, fmt.Sprintf("file %s: method %s has invalid hits %q", filename, method.Name, method.Hits))
+		} else {
+			fn.ExecutionCount = hits
+		}
+	}
+
+	return fn
+}
+
+// resolveSourcePath joins a relative class filename with the report's <sources>.
+// With several sources the first one under which the file exists wins.
+func resolveSourcePath(sources []string, filename string) string {
+	// Normalize filename (remove leading ./ if present)
+	filename = strings.TrimPrefix(filename, "./")
+
+	if len(sources) == 0 || IsAbsolutePath(filename) {
+		return filename
+	}
+
+	if len(sources) > 1 {
+		for _, source := range sources {
+			candidate := joinSourcePath(source, filename)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+		}
+	}
+
+	return joinSourcePath(sources[0], filename)
+}
+
+// joinSourcePath joins a source directory and a filename, keeping Windows
+// separators when the source directory uses them
+func joinSourcePath(source, filename string) string {
+	if strings.Contains(source, `\`) && !strings.Contains(source, "/") {
+		return strings.TrimRight(source, `\`) + `\` + strings.ReplaceAll(filename, "/", `\`)
+	}
+	return path.Join(source, filename)
+}
+
+// IsAbsolutePath reports whether a path is absolute on Unix or Windows, e.g.
+// /src/x.go, C:\src\x.go or C:/src/x.go, whatever the system covpeek runs on
+func IsAbsolutePath(name string) bool {
+	if strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) {
+		return true
+	}
+	return len(name) > 2 && name[1] == ':' && (name[2] == '\\' || name[2] == '/') &&
+		(name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z')
+}
+
+// Name returns the canonical format name
+func (p *CoberturaXMLParser) Name() string {
+	return "cobertura"
+}
+
+// Description returns a human readable format name
+func (p *CoberturaXMLParser) Description() string {
+	return "Cobertura XML Coverage"
+}
+
+// Aliases returns the names the format was known by when it was Python only
+func (p *CoberturaXMLParser) Aliases() []string {
+	return []string{"python", "pyxml"}
+}
+
+// FilePatterns returns the file names Cobertura reports are usually written to
+func (p *CoberturaXMLParser) FilePatterns() []string {
+	return []string{"*coverage*.xml", "*cobertura*.xml"}
+}
+
+// Sniff is confident when the document has a <coverage> root with Cobertura's
+// rates, sources or packages. Clover reports share the root element name and
+// are left out.
+func (p *CoberturaXMLParser) Sniff(header []byte) Confidence {
+	doc := sniffXML(header)
+	switch {
+	case !doc.rootIs("coverage") || isCloverRoot(doc):
+		return NoMatch
+	case doc.hasAttr("line-rate") || doc.hasChild("sources") || doc.hasChild("packages"):
+		return ConfidenceHigh
+	}
+	return ConfidenceMedium
+}
+
+// Detect reports whether the document has a Cobertura <coverage> root
+func (p *CoberturaXMLParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *CoberturaXMLParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *CoberturaXMLParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *CoberturaXMLParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
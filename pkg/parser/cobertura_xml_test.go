@@ -0,0 +1,419 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoberturaXMLParser_Parse_ValidFile(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage>
+  <packages>
+    <package>
+      <classes>
+        <class filename="src/main.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="1"/>
+            <line number="3" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report == nil {
+		t.Fatal("Expected report to be non-nil")
+	}
+
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(report.Files))
+	}
+
+	file, exists := report.Files["src/main.py"]
+	if !exists {
+		t.Fatal("Expected file 'src/main.py' to exist")
+	}
+
+	if file.TotalLines != 3 {
+		t.Errorf("Expected 3 total lines, got %d", file.TotalLines)
+	}
+
+	if file.CoveredLines != 2 {
+		t.Errorf("Expected 2 covered lines, got %d", file.CoveredLines)
+	}
+
+	if file.CoveragePct != 66.66666666666666 {
+		t.Errorf("Expected coverage percentage 66.67, got %f", file.CoveragePct)
+	}
+}
+
+func TestCoberturaXMLParser_Parse_MultipleFiles(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage>
+  <packages>
+    <package>
+      <classes>
+        <class filename="src/main.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+          </lines>
+        </class>
+        <class filename="src/utils.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="1"/>
+            <line number="3" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(report.Files))
+	}
+
+	// Check first file
+	file1, exists := report.Files["src/main.py"]
+	if !exists {
+		t.Fatal("Expected file 'src/main.py' to exist")
+	}
+	if file1.TotalLines != 2 || file1.CoveredLines != 1 {
+		t.Errorf("File1: expected 2 total, 1 covered, got %d total, %d covered", file1.TotalLines, file1.CoveredLines)
+	}
+
+	// Check second file
+	file2, exists := report.Files["src/utils.py"]
+	if !exists {
+		t.Fatal("Expected file 'src/utils.py' to exist")
+	}
+	if file2.TotalLines != 3 || file2.CoveredLines != 2 {
+		t.Errorf("File2: expected 3 total, 2 covered, got %d total, %d covered", file2.TotalLines, file2.CoveredLines)
+	}
+}
+
+func TestCoberturaXMLParser_Parse_EmptyFile(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage>
+  <packages>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 0 {
+		t.Errorf("Expected 0 files, got %d", len(report.Files))
+	}
+}
+
+func TestCoberturaXMLParser_Parse_InvalidXML(t *testing.T) {
+	xmlData := `<invalid xml>`
+
+	parser := NewCoberturaXMLParser()
+	_, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err == nil {
+		t.Fatal("Expected error for invalid XML, got nil")
+	}
+}
+
+func TestCoberturaXMLParser_Parse_MalformedXML(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage>
+  <packages>
+    <package>
+      <classes>
+        <class filename="src/main.py">
+          <lines>
+            <line number="invalid" hits="1"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	// The XML should still parse, but the line number parsing might fail
+	// This depends on how strict the XML parsing is
+	if err != nil {
+		t.Logf("Got expected error: %v", err)
+	}
+
+	if report != nil && len(report.Files) > 0 {
+		t.Logf("Parsed %d files despite malformed data", len(report.Files))
+	}
+}
+
+func TestCoberturaXMLParser_GetWarnings(t *testing.T) {
+	// Test with negative hits to trigger warnings
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage>
+  <packages>
+    <package>
+      <classes>
+        <class filename="src/main.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="-1"/>
+            <line number="3" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report == nil || len(report.Files) == 0 {
+		t.Fatal("Expected report with files")
+	}
+
+	// Check warnings - should have warning about negative hits
+	warnings := parser.GetWarnings()
+	if len(warnings) == 0 {
+		t.Errorf("Expected warnings for negative hit count, got 0 warnings")
+	} else {
+		t.Logf("Got expected warnings: %v", warnings)
+	}
+}
+
+func TestCoberturaXMLParser_Parse_SourcesPackagesAndMethods(t *testing.T) {
+	xmlData := `<?xml version="1.0" ?>
+<coverage line-rate="0.75" branch-rate="0.5" version="5.0">
+  <sources>
+    <source>/builds/app/src</source>
+  </sources>
+  <packages>
+    <package name="MyApp.Services">
+      <classes>
+        <class name="MyApp.Services.OrderService" filename="Services/OrderService.cs">
+          <methods>
+            <method name="Total" signature="(System.Int32)">
+              <lines>
+                <line number="10" hits="4" branch="false"/>
+                <line number="11" hits="4" branch="true" condition-coverage="50% (1/2)"/>
+              </lines>
+            </method>
+            <method name="Cancel" signature="()" hits="0">
+              <lines>
+                <line number="20" hits="0" branch="false"/>
+              </lines>
+            </method>
+          </methods>
+          <lines>
+            <line number="10" hits="4" branch="false"/>
+            <line number="11" hits="4" branch="true" condition-coverage="50% (1/2)">
+              <conditions>
+                <condition number="0" type="jump" coverage="50%"/>
+              </conditions>
+            </line>
+            <line number="12" hits="2" branch="false"/>
+            <line number="20" hits="0" branch="false"/>
+          </lines>
+        </class>
+        <class name="MyApp.Services.OrderService/&lt;TotalAsync&gt;d__2" filename="Services/OrderService.cs">
+          <lines>
+            <line number="12" hits="5" branch="false"/>
+            <line number="30" hits="1" branch="true" condition-coverage="100% (4/4)"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(report.Files))
+	}
+
+	file := report.Files["/builds/app/src/Services/OrderService.cs"]
+	if file == nil {
+		t.Fatalf("Expected filename to be resolved against <sources>, got: %v", report.Files)
+	}
+
+	if file.Package != "MyApp.Services" {
+		t.Errorf("Expected package 'MyApp.Services', got '%s'", file.Package)
+	}
+
+	if file.TotalLines != 5 || file.CoveredLines != 4 {
+		t.Errorf("Expected 5 total, 4 covered lines, got %d total, %d covered", file.TotalLines, file.CoveredLines)
+	}
+
+	// Line 12 is reported by both classes and keeps the highest hit count
+	if file.Lines[12].ExecutionCount != 5 {
+		t.Errorf("Expected line 12 to have 5 hits, got %d", file.Lines[12].ExecutionCount)
+	}
+
+	if file.TotalBranches != 6 || file.CoveredBranches != 5 {
+		t.Errorf("Expected 6 total, 5 covered branches, got %d total, %d covered", file.TotalBranches, file.CoveredBranches)
+	}
+
+	if len(file.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %d", len(file.Functions))
+	}
+
+	if file.Functions[0].Name != "Total" || file.Functions[0].LineNumber != 10 || file.Functions[0].ExecutionCount != 4 {
+		t.Errorf("Unexpected first function: %+v", file.Functions[0])
+	}
+
+	if file.Functions[1].Name != "Cancel" || file.Functions[1].ExecutionCount != 0 {
+		t.Errorf("Unexpected second function: %+v", file.Functions[1])
+	}
+}
+
+func TestCoberturaXMLParser_Parse_MultipleSources(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "util.c"), []byte("int x;\n"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	xmlData := `<coverage>
+  <sources>
+    <source>/nonexistent/root</source>
+    <source>` + dir + `</source>
+  </sources>
+  <packages>
+    <package name="lib">
+      <classes>
+        <class name="util_c" filename="lib/util.c">
+          <lines><line number="1" hits="1"/></lines>
+        </class>
+        <class name="main_c" filename="main.c">
+          <lines><line number="1" hits="1"/></lines>
+        </class>
+        <class name="abs" filename="/abs/path.c">
+          <lines><line number="1" hits="1"/></lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The first source containing the file wins, otherwise the first source is used
+	for _, expected := range []string{filepath.ToSlash(filepath.Join(dir, "lib", "util.c")), "/nonexistent/root/main.c", "/abs/path.c"} {
+		if report.Files[expected] == nil {
+			t.Errorf("Expected file '%s' in report, got: %v", expected, report.Files)
+		}
+	}
+}
+
+func TestCoberturaXMLParser_Parse_InvalidConditionCoverage(t *testing.T) {
+	xmlData := `<coverage>
+  <packages>
+    <package name="">
+      <classes>
+        <class filename="a.php">
+          <methods>
+            <method name="run" hits="many"/>
+          </methods>
+          <lines>
+            <line number="1" hits="1" branch="true" condition-coverage="50%"/>
+            <line number="2" hits="1" branch="true" condition-coverage="100% (3/2)"/>
+          </lines>
+        </class>
+        <class name="nofile"/>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+	parser := NewCoberturaXMLParser()
+	report, err := parser.Parse(strings.NewReader(xmlData))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(parser.GetWarnings()) != 4 {
+		t.Errorf("Expected 4 warnings, got %d: %v", len(parser.GetWarnings()), parser.GetWarnings())
+	}
+
+	if report.Files["a.php"].TotalBranches != 0 {
+		t.Errorf("Expected no branches from invalid condition coverage, got %d", report.Files["a.php"].TotalBranches)
+	}
+}
+
+func TestPyCoverXMLParser_DeprecatedAlias(t *testing.T) {
+	parser := NewPyCoverXMLParser()
+	report, err := parser.Parse(strings.NewReader(`<coverage><packages/></coverage>`))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 0 {
+		t.Errorf("Expected 0 files, got %d", len(report.Files))
+	}
+}
+
+func TestIsAbsolutePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"/src/x.go", true},
+		{`\src\x.go`, true},
+		{`C:\src\x.go`, true},
+		{"c:/src/x.go", true},
+		{"src/x.go", false},
+		{"./src/x.go", false},
+		{"1:/x.go", false},
+		{"C:", false},
+	}
+	for _, tt := range tests {
+		if abs := IsAbsolutePath(tt.name); abs != tt.expected {
+			t.Errorf("IsAbsolutePath(%q) = %v, expected %v", tt.name, abs, tt.expected)
+		}
+	}
+}
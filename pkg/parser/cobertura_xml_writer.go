@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// coberturaDoctype is the DOCTYPE coverage.py writes into its Cobertura reports
+const coberturaDoctype = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+// CoberturaXMLWriter writes coverage reports in Cobertura XML format
+type CoberturaXMLWriter struct{}
+
+// NewCoberturaXMLWriter creates a new Cobertura XML writer instance
+func NewCoberturaXMLWriter() *CoberturaXMLWriter {
+	return &CoberturaXMLWriter{}
+}
+
+// Name returns the canonical format name
+func (w *CoberturaXMLWriter) Name() string {
+	return "cobertura"
+}
+
+// Write writes the report as Cobertura XML. Files are grouped into packages by
+// their package name, or by directory when the format did not report one.
+// Cobertura counts lines at every level, also for reports in statements, so
+// the totals agree with the <line> elements.
+func (w *CoberturaXMLWriter) Write(writer io.Writer, report *models.CoverageReport) error {
+	coverage := CoberturaCoverage{
+		Complexity: "0",
+		Version:    "covpeek",
+		Timestamp:  strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}
+
+	// Group files into packages
+	packages := make(map[string][]*models.FileCoverage)
+	for _, name := range sortedFileNames(report) {
+		file := report.Files[name]
+		pkgName := file.Package
+		if pkgName == "" {
+			pkgName = coberturaPackageName(file.FileName)
+		}
+		packages[pkgName] = append(packages[pkgName], file)
+	}
+
+	pkgNames := make([]string, 0, len(packages))
+	for pkgName := range packages {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+
+	for _, pkgName := range pkgNames {
+		pkg := CoberturaPackage{Name: pkgName, Complexity: "0"}
+		pkgLines, pkgCovered, pkgBranches, pkgCoveredBranches := 0, 0, 0, 0
+		for _, file := range packages[pkgName] {
+			pkg.Classes = append(pkg.Classes, coberturaClass(file))
+			pkgLines += file.TotalLines
+			pkgCovered += file.CoveredLines
+			pkgBranches += file.TotalBranches
+			pkgCoveredBranches += file.CoveredBranches
+		}
+		pkg.LineRate = coberturaRate(pkgCovered, pkgLines)
+		pkg.BranchRate = coberturaRate(pkgCoveredBranches, pkgBranches)
+		coverage.Packages = append(coverage.Packages, pkg)
+
+		coverage.LinesValid += pkgLines
+		coverage.LinesCovered += pkgCovered
+		coverage.BranchesValid += pkgBranches
+		coverage.BranchesCovered += pkgCoveredBranches
+	}
+	coverage.LineRate = coberturaRate(coverage.LinesCovered, coverage.LinesValid)
+	coverage.BranchRate = coberturaRate(coverage.BranchesCovered, coverage.BranchesValid)
+
+	if _, err := io.WriteString(writer, xml.Header+coberturaDoctype+"\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(coverage); err != nil {
+		return fmt.Errorf("failed to write XML: %w", err)
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// coberturaClass converts a file to a <class> element with its methods and lines
+func coberturaClass(file *models.FileCoverage) CoberturaClass {
+	class := CoberturaClass{
+		Name:       path.Base(file.FileName),
+		Filename:   file.FileName,
+		LineRate:   coberturaRate(file.CoveredLines, file.TotalLines),
+		BranchRate: coberturaRate(file.CoveredBranches, file.TotalBranches),
+		Complexity: "0",
+		Methods:    make([]CoberturaMethod, 0, len(file.Functions)),
+		Lines:      make([]CoberturaLine, 0, len(file.Lines)),
+	}
+
+	for _, fn := range file.Functions {
+		executed := 0
+		if fn.ExecutionCount > 0 {
+			executed = 1
+		}
+		class.Methods = append(class.Methods, CoberturaMethod{
+			Name:       fn.Name,
+			LineRate:   coberturaRate(executed, 1),
+			BranchRate: "1",
+			Lines:      []CoberturaLine{{Number: fn.LineNumber, Hits: fn.ExecutionCount}},
+		})
+	}
+
+	// Cobertura records branches per line as "covered/total" conditions
+	type conditions struct{ covered, total int }
+	branchesByLine := make(map[int]*conditions)
+	for _, b := range file.Branches {
+		c := branchesByLine[b.LineNumber]
+		if c == nil {
+			c = &conditions{}
+			branchesByLine[b.LineNumber] = c
+		}
+		c.total++
+		if b.IsCovered() {
+			c.covered++
+		}
+	}
+
+	for _, lineNum := range sortedLineNumbers(file) {
+		line := CoberturaLine{
+			Number: lineNum,
+			Hits:   file.Lines[lineNum].ExecutionCount,
+		}
+		if c := branchesByLine[lineNum]; c != nil {
+			line.Branch = true
+			line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", c.covered*100/c.total, c.covered, c.total)
+		}
+		class.Lines = append(class.Lines, line)
+	}
+
+	return class
+}
+
+// coberturaPackageName derives a package name from the directory of a file,
+// the way coverage.py does: "src/app/main.py" belongs to package "src.app"
+func coberturaPackageName(filename string) string {
+	dir := path.Dir(strings.ReplaceAll(filename, `\`, "/"))
+	if dir == "." || dir == "/" {
+		return "."
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(dir, "/"), "/", ".")
+}
+
+// coberturaRate formats a coverage ratio like coverage.py, a rate without
+// anything to cover is 1
+func coberturaRate(covered, total int) string {
+	if total == 0 {
+		return "1"
+	}
+	return strconv.FormatFloat(float64(covered)/float64(total), 'g', 4, 64)
+}
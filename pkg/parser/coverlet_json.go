@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// CoverletJSONParser parses Coverlet's native JSON format (.NET). The report
+// nests files, classes and methods below the assemblies (modules) they were
+// compiled into.
+type CoverletJSONParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewCoverletJSONParser creates a new Coverlet JSON parser instance
+func NewCoverletJSONParser() *CoverletJSONParser {
+	return &CoverletJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// CoverletMethod represents the coverage of a method, keyed by its signature
+// in its class. Lines maps line numbers to hit counts.
+type CoverletMethod struct {
+	Lines    map[string]int   `json:"Lines"`
+	Branches []CoverletBranch `json:"Branches"`
+}
+
+// CoverletBranch represents one path of a conditional jump at an IL offset
+type CoverletBranch struct {
+	Line      int `json:"Line"`
+	Offset    int `json:"Offset"`
+	EndOffset int `json:"EndOffset"`
+	Path      int `json:"Path"`
+	Ordinal   int `json:"Ordinal"`
+	Hits      int `json:"Hits"`
+}
+
+// Parse reads and parses a Coverlet JSON file. Files are decoded one at a
+// time with their classes.
+func (p *CoverletJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(module string) error {
+		moduleName := strings.TrimSuffix(module, path.Ext(module))
+		return forEachJSONKey(decoder, func(filename string) error {
+			var classes map[string]map[string]CoverletMethod
+			if err := decoder.Decode(&classes); err != nil {
+				return err
+			}
+			p.parseFile(moduleName, filename, classes, report)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for _, file := range report.Files {
+		file.CountLines()
+		file.CountBranches()
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile adds the lines, branches and methods of the classes of a file.
+// Classes and methods are handled in name order, the JSON object has none.
+func (p *CoverletJSONParser) parseFile(moduleName, filename string, classes map[string]map[string]CoverletMethod, report *models.CoverageReport) {
+	file := dotNetFile(report, moduleName, filename)
+
+	for _, className := range sortedKeys(classes) {
+		methods := classes[className]
+		for _, signature := range sortedKeys(methods) {
+			method := methods[signature]
+			fn := models.FunctionCoverage{Name: dotNetMethodName(signature)}
+
+			for _, key := range sortedKeys(method.Lines) {
+				hits := method.Lines[key]
+				line, err := strconv.Atoi(key)
+				if err != nil || line <= 0 {
+					p.addWarning("Lines", CodeInvalidValue, fmt.Sprintf("file %s: method %s has invalid line %q", filename, signature, key))
+					continue
+				}
+				if hits < 0 {
+					p.addWarning("Lines", CodeInvalidValue, fmt.Sprintf("file %s: line %d has negative hit count %d", filename, line, hits))
+					continue
+				}
+				addDotNetLines(file, line, line, hits)
+
+				if fn.LineNumber == 0 || line < fn.LineNumber {
+					fn.LineNumber = line
+					fn.ExecutionCount = hits
+				}
+			}
+
+			sort.Slice(method.Branches, func(i, j int) bool {
+				return method.Branches[i].Ordinal < method.Branches[j].Ordinal
+			})
+			for _, branch := range method.Branches {
+				if branch.Line <= 0 {
+					p.addWarning("Branches", CodeInvalidValue, fmt.Sprintf("file %s: method %s has a branch on invalid line %d", filename, signature, branch.Line))
+					continue
+				}
+				file.Branches = append(file.Branches, models.BranchCoverage{
+					LineNumber:  branch.Line,
+					BlockNumber: branch.Offset,
+					BranchID:    strconv.Itoa(branch.Path),
+					TakenCount:  branch.Hits,
+				})
+			}
+
+			// Compiler generated methods have no lines
+			if fn.LineNumber > 0 {
+				file.Functions = append(file.Functions, fn)
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Name returns the canonical format name
+func (p *CoverletJSONParser) Name() string {
+	return "coverlet"
+}
+
+// Description returns a human readable format name
+func (p *CoverletJSONParser) Description() string {
+	return "Coverlet JSON Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "coverlet"
+func (p *CoverletJSONParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns no patterns, Coverlet writes coverage.json like
+// coverage.py, so the format is detected by content
+func (p *CoverletJSONParser) FilePatterns() []string {
+	return nil
+}
+
+// Sniff is confident when the document is keyed by assembly names such as
+// "Shop.dll" holding files, and certain once a method's Lines are seen
+func (p *CoverletJSONParser) Sniff(header []byte) Confidence {
+	doc := sniffJSON(header)
+	for _, path := range doc.find("*", "*") {
+		ext := strings.ToLower(path[0][strings.LastIndex(path[0], ".")+1:])
+		if ext != "dll" && ext != "exe" {
+			continue
+		}
+		if doc.has(path[0], "*", "*", "*", "Lines") {
+			return ConfidenceCertain
+		}
+		return ConfidenceHigh
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document is keyed by assembly names
+func (p *CoverletJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *CoverletJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *CoverletJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *CoverletJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
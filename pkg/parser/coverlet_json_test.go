@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCoverletJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/coverlet.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewCoverletJSONParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	cart := report.Files["/src/Shop/Cart.cs"]
+	if cart == nil {
+		t.Fatalf("Expected file '/src/Shop/Cart.cs' not found")
+	}
+	if cart.Package != "Shop" {
+		t.Errorf("Expected package 'Shop', got: %s", cart.Package)
+	}
+	// Line 11 ran in the lambda
+	if cart.TotalLines != 6 || cart.CoveredLines != 6 || cart.Lines[11].ExecutionCount != 4 {
+		t.Errorf("Expected 6 of 6 lines covered, got: %d of %d", cart.CoveredLines, cart.TotalLines)
+	}
+	if cart.TotalBranches != 2 || cart.CoveredBranches != 1 {
+		t.Errorf("Expected 1 of 2 branches covered, got: %d of %d", cart.CoveredBranches, cart.TotalBranches)
+	}
+	if cart.Branches[0].BranchID != "0" || cart.Branches[0].BlockNumber != 7 {
+		t.Errorf("Expected branches in ordinal order, got: %+v", cart.Branches)
+	}
+	if len(cart.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got: %+v", cart.Functions)
+	}
+	// Methods are sorted by signature, the return type comes first
+	if fn := cart.Functions[0]; fn.Name != "Cart.Total" || fn.LineNumber != 8 || fn.ExecutionCount != 2 {
+		t.Errorf("Unexpected first function: %+v", fn)
+	}
+
+	discount := report.Files["/src/Shop/Discount.cs"]
+	if discount == nil {
+		t.Fatalf("Expected file '/src/Shop/Discount.cs' not found")
+	}
+	if discount.TotalLines != 2 || discount.CoveredLines != 0 {
+		t.Errorf("Expected 0 of 2 lines covered, got: %d of %d", discount.CoveredLines, discount.TotalLines)
+	}
+}
+
+func TestCoverletJSONParser_Parse_InvalidLines(t *testing.T) {
+	input := `{"Shop.dll": {"a.cs": {"Shop.A": {"System.Void Shop.A::Run()": {"Lines": {"x": 1, "3": -1, "4": 1}, "Branches": [{"Line": 0, "Hits": 1}]}}}}}`
+
+	parser := NewCoverletJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 3 {
+		t.Errorf("Expected 3 warnings, got: %v", parser.GetWarnings())
+	}
+	if file := report.Files["a.cs"]; file == nil || file.TotalLines != 1 || file.TotalBranches != 0 {
+		t.Errorf("Expected the valid line to be kept, got: %+v", file)
+	}
+}
+
+func TestCoverletJSONParser_Parse_InvalidJSON(t *testing.T) {
+	if _, err := NewCoverletJSONParser().Parse(strings.NewReader(`{"Shop.dll": {"a.cs": [`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
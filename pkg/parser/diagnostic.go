@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// Severity classifies a diagnostic
+type Severity string
+
+const (
+	// SeverityWarning marks data that was skipped or looks inconsistent, the rest of the report is usable
+	SeverityWarning Severity = "warning"
+	// SeverityError marks a problem that made a whole file unusable
+	SeverityError Severity = "error"
+)
+
+// Diagnostic codes identify the kind of problem independently of the message wording
+const (
+	// CodeInvalidRecord is used for records that cannot be parsed
+	CodeInvalidRecord = "invalid-record"
+	// CodeUnknownRecord is used for records of an unknown type
+	CodeUnknownRecord = "unknown-record"
+	// CodeOrphanRecord is used for records that appear outside of a source file
+	CodeOrphanRecord = "orphan-record"
+	// CodeInvalidValue is used for values that parse but make no sense, such as negative counts
+	CodeInvalidValue = "invalid-value"
+	// CodeMissingData is used for entries that lack data they need, such as a file name or count
+	CodeMissingData = "missing-data"
+	// CodeSummaryMismatch is used when summary totals contradict the detailed data
+	CodeSummaryMismatch = "summary-mismatch"
+	// CodeUnknownMode is used for an unknown Go coverage mode
+	CodeUnknownMode = "unknown-mode"
+	// CodeMissingSource is used for source files that cannot be found or parsed
+	CodeMissingSource = "missing-source"
+)
+
+// Diagnostic describes a problem found while parsing a coverage file
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Line is the line in the coverage file, 0 when the format is not line based
+	Line int `json:"line,omitempty"`
+	// Record is the kind of record or element the problem was found in, e.g. "DA" or "class"
+	Record  string `json:"record,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic the way parser warnings have always been printed
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+	}
+	return d.Message
+}
+
+// diagnosticStrings returns the messages of diagnostics, for GetWarnings
+func diagnosticStrings(diagnostics []Diagnostic) []string {
+	warnings := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		warnings = append(warnings, d.String())
+	}
+	return warnings
+}
@@ -0,0 +1,333 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/internal/decompress"
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// GcovJSONParser parses the JSON intermediate format written by
+// gcov --json-format (GCC 9 and newer), one .gcov.json.gz file per translation
+// unit. A directory of such files is parsed as one report, merging the headers
+// several translation units include.
+type GcovJSONParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewGcovJSONParser creates a new gcov JSON parser instance
+func NewGcovJSONParser() *GcovJSONParser {
+	return &GcovJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// GcovFile represents a source file of a translation unit. Headers appear in
+// the files of every translation unit that includes them.
+type GcovFile struct {
+	File      string         `json:"file"`
+	Functions []GcovFunction `json:"functions"`
+	Lines     []GcovLine     `json:"lines"`
+}
+
+// GcovFunction represents a function entry of a gcov file
+type GcovFunction struct {
+	Name           string `json:"name"`
+	DemangledName  string `json:"demangled_name"`
+	StartLine      int    `json:"start_line"`
+	ExecutionCount int    `json:"execution_count"`
+}
+
+// GcovLine represents a line entry of a gcov or gcovr file. A line is listed
+// once per function instance it belongs to, e.g. for C++ templates.
+type GcovLine struct {
+	LineNumber int          `json:"line_number"`
+	Count      int          `json:"count"`
+	Branches   []GcovBranch `json:"branches"`
+}
+
+// GcovBranch represents an arc leaving the last block of a line
+type GcovBranch struct {
+	Count       int  `json:"count"`
+	Fallthrough bool `json:"fallthrough"`
+	Throw       bool `json:"throw"`
+}
+
+// Parse reads and parses an uncompressed gcov JSON file, covpeek.Load
+// decompresses .gcov.json.gz files. File entries are decoded one at a time.
+func (p *GcovJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+	var cwd string
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(key string) error {
+		switch key {
+		case "files":
+			return forEachJSONElement(decoder, func() error {
+				var fileData GcovFile
+				if err := decoder.Decode(&fileData); err != nil {
+					return err
+				}
+				p.parseFile(fileData, report)
+				return nil
+			})
+		case "current_working_directory":
+			return decoder.Decode(&cwd)
+		default:
+			return skipJSONValue(decoder)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// File names are relative to the directory gcc ran in, which may be
+	// written after the files
+	if cwd != "" {
+		resolved := models.NewCoverageReport()
+		for name, file := range report.Files {
+			if !path.IsAbs(name) && !filepath.IsAbs(name) {
+				file.FileName = path.Join(filepath.ToSlash(cwd), name)
+			}
+			if existing := resolved.GetFile(file.FileName); existing != nil {
+				existing.Merge(file)
+				continue
+			}
+			resolved.AddFile(file)
+		}
+		report = resolved
+	}
+
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile converts the lines and functions of a file. A file listed twice is merged.
+func (p *GcovJSONParser) parseFile(fileData GcovFile, report *models.CoverageReport) {
+	if fileData.File == "" {
+		p.addWarning("file", CodeMissingData, "file entry without a file name")
+		return
+	}
+
+	file := &models.FileCoverage{
+		FileName:  fileData.File,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+	p.diagnostics = addGcovLines(file, fileData.Lines, p.diagnostics)
+
+	for _, fn := range fileData.Functions {
+		name := fn.DemangledName
+		if name == "" {
+			name = fn.Name
+		}
+		file.Functions = append(file.Functions, models.FunctionCoverage{
+			Name:           name,
+			LineNumber:     fn.StartLine,
+			ExecutionCount: fn.ExecutionCount,
+		})
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	if existing := report.GetFile(file.FileName); existing != nil {
+		existing.Merge(file)
+		return
+	}
+	report.AddFile(file)
+}
+
+// addGcovLines adds the lines of a gcov or gcovr file. Lines listed once per
+// function instance are summed, and their branches are numbered as separate
+// blocks. Branches of lines that never ran could not be evaluated, like in
+// LCOV files written by geninfo.
+func addGcovLines(file *models.FileCoverage, lines []GcovLine, diagnostics []Diagnostic) []Diagnostic {
+	blocks := make(map[int]int)
+	for _, line := range lines {
+		if line.LineNumber <= 0 {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Record:   "line",
+				Code:     CodeInvalidValue,
+				Message:  fmt.Sprintf("file %s: invalid line number %d", file.FileName, line.LineNumber),
+			})
+			continue
+		}
+		if line.Count < 0 {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Record:   "line",
+				Code:     CodeInvalidValue,
+				Message:  fmt.Sprintf("file %s: negative count %d on line %d", file.FileName, line.Count, line.LineNumber),
+			})
+			continue
+		}
+
+		existing := file.Lines[line.LineNumber]
+		file.Lines[line.LineNumber] = models.LineCoverage{
+			LineNumber:     line.LineNumber,
+			ExecutionCount: existing.ExecutionCount + line.Count,
+		}
+
+		if len(line.Branches) == 0 {
+			continue
+		}
+		block := blocks[line.LineNumber]
+		blocks[line.LineNumber]++
+		for i, branch := range line.Branches {
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber:  line.LineNumber,
+				BlockNumber: block,
+				BranchID:    strconv.Itoa(i),
+				TakenCount:  branch.Count,
+				NotExecuted: line.Count == 0,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// ParseDir parses all gcov JSON files of a directory into one report. Files
+// that cannot be parsed are skipped with a warning.
+func (p *GcovJSONParser) ParseDir(fsys fs.FS) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	names, err := gcovJSONFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no gcov JSON files found")
+	}
+
+	report := models.NewCoverageReport()
+	parsed := 0
+	for _, name := range names {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		tu := NewGcovJSONParser()
+		tuReport, err := tu.parseDirFile(file)
+		_ = file.Close()
+		if err != nil {
+			p.addWarning("file", CodeInvalidRecord, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, diagnostic := range tu.Diagnostics() {
+			diagnostic.Message = name + ": " + diagnostic.Message
+			p.diagnostics = append(p.diagnostics, diagnostic)
+		}
+		report.Merge(tuReport)
+		parsed++
+	}
+	if parsed == 0 {
+		return nil, fmt.Errorf("no readable gcov JSON files found")
+	}
+	return report, nil
+}
+
+// parseDirFile parses a gcov JSON file of a directory, which gcov writes gzip
+// compressed
+func (p *GcovJSONParser) parseDirFile(file io.Reader) (*models.CoverageReport, error) {
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(decompress.HeaderSize)
+	decompressed, err := decompress.NewReader(header, buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gcov JSON data: %w", err)
+	}
+	if decompressed == nil {
+		return p.Parse(buffered)
+	}
+	defer func() { _ = decompressed.Close() }()
+	return p.Parse(decompressed)
+}
+
+// gcovJSONFiles returns the names of the gcov JSON files of a directory, sorted
+func gcovJSONFiles(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if !entry.IsDir() && (strings.HasSuffix(name, ".gcov.json.gz") || strings.HasSuffix(name, ".gcov.json")) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Name returns the canonical format name
+func (p *GcovJSONParser) Name() string {
+	return "gcov"
+}
+
+// Description returns a human readable format name
+func (p *GcovJSONParser) Description() string {
+	return "gcov JSON Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "gcov"
+func (p *GcovJSONParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names gcov --json-format writes
+func (p *GcovJSONParser) FilePatterns() []string {
+	return []string{"*.gcov.json.gz", "*.gcov.json"}
+}
+
+// Sniff is certain when the document has the top-level gcc_version key gcov writes
+func (p *GcovJSONParser) Sniff(header []byte) Confidence {
+	if sniffJSON(header).has("gcc_version") {
+		return ConfidenceCertain
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document has a gcc_version key
+func (p *GcovJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// DetectDir reports whether the directory holds gcov JSON files
+func (p *GcovJSONParser) DetectDir(fsys fs.FS) bool {
+	names, err := gcovJSONFiles(fsys)
+	return err == nil && len(names) > 0
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *GcovJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *GcovJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *GcovJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGcovJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/gcov/demo-main.gcov.json.gz")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to decompress test file: %v", err)
+	}
+
+	parser := NewGcovJSONParser()
+	report, err := parser.Parse(gz)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	// Names are resolved against the directory gcc ran in
+	main := report.Files["/home/dev/firmware/main.c"]
+	if main == nil {
+		t.Fatalf("Expected file '/home/dev/firmware/main.c' not found")
+	}
+	if main.TotalLines != 4 || main.CoveredLines != 4 {
+		t.Errorf("Expected 4 of 4 lines covered, got: %d of %d", main.CoveredLines, main.TotalLines)
+	}
+	if len(main.Functions) != 1 || main.Functions[0].Name != "main" || main.Functions[0].LineNumber != 6 || main.Functions[0].ExecutionCount != 1 {
+		t.Errorf("Unexpected functions: %+v", main.Functions)
+	}
+
+	util := report.Files["/home/dev/firmware/util.h"]
+	if util == nil {
+		t.Fatalf("Expected file '/home/dev/firmware/util.h' not found")
+	}
+	if util.TotalLines != 6 || util.CoveredLines != 3 {
+		t.Errorf("Expected 3 of 6 lines covered, got: %d of %d", util.CoveredLines, util.TotalLines)
+	}
+	if util.TotalBranches != 4 || util.CoveredBranches != 1 {
+		t.Errorf("Expected 1 of 4 branches covered, got: %d of %d", util.CoveredBranches, util.TotalBranches)
+	}
+	// The branches on line 5 were never evaluated
+	for _, branch := range util.Branches {
+		if branch.NotExecuted != (branch.LineNumber == 5) {
+			t.Errorf("Unexpected branch: %+v", branch)
+		}
+	}
+}
+
+func TestGcovJSONParser_Parse_Uncompressed(t *testing.T) {
+	input := `{"format_version": "1", "gcc_version": "12.2.0", "files": [{"file": "/src/a.c",
+		"functions": [{"name": "_Z1fv", "demangled_name": "f()", "start_line": 1, "execution_count": 2}],
+		"lines": [{"line_number": 2, "count": 2, "branches": []}, {"line_number": 2, "count": 1, "branches": []}, {"line_number": 3, "count": 0, "branches": []}]}]}`
+
+	report, err := NewGcovJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["/src/a.c"]
+	if file == nil {
+		t.Fatalf("Expected file '/src/a.c' not found")
+	}
+	// Lines listed once per function instance are summed
+	if file.Lines[2].ExecutionCount != 3 || file.TotalLines != 2 {
+		t.Errorf("Unexpected lines: %+v", file.Lines)
+	}
+	if file.Functions[0].Name != "f()" {
+		t.Errorf("Expected demangled function name, got: %s", file.Functions[0].Name)
+	}
+}
+
+func TestGcovJSONParser_Parse_InvalidLines(t *testing.T) {
+	input := `{"gcc_version": "12.2.0", "files": [{"file": "a.c", "lines": [{"line_number": 0, "count": 1}, {"line_number": 2, "count": -1}, {"line_number": 3, "count": 1}]}, {"lines": []}]}`
+
+	parser := NewGcovJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 3 {
+		t.Errorf("Expected 3 warnings, got: %v", parser.GetWarnings())
+	}
+	if file := report.Files["a.c"]; file == nil || file.TotalLines != 1 {
+		t.Errorf("Expected the valid line to be kept, got: %+v", file)
+	}
+}
+
+func TestGcovJSONParser_ParseDir_InvalidGzip(t *testing.T) {
+	parser := NewGcovJSONParser()
+	_, err := parser.ParseDir(fstest.MapFS{
+		"a.gcov.json.gz": &fstest.MapFile{Data: []byte{0x1f, 0x8b, 0x00}},
+	})
+	if err == nil {
+		t.Error("Expected error for truncated gzip data")
+	}
+	if len(parser.Diagnostics()) != 1 || !strings.Contains(parser.Diagnostics()[0].Message, "failed to decompress") {
+		t.Errorf("Expected a decompression warning, got: %v", parser.Diagnostics())
+	}
+}
+
+func TestGcovJSONParser_ParseDir(t *testing.T) {
+	parser := NewGcovJSONParser()
+	if !parser.DetectDir(os.DirFS("../../testdata/gcov")) {
+		t.Fatal("Expected the directory to be detected")
+	}
+
+	report, err := parser.ParseDir(os.DirFS("../../testdata/gcov"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(report.Files) != 3 {
+		t.Fatalf("Expected 3 files, got: %d", len(report.Files))
+	}
+
+	// Both translation units include util.h, its counts are merged
+	util := report.Files["/home/dev/firmware/util.h"]
+	if util == nil {
+		t.Fatalf("Expected file '/home/dev/firmware/util.h' not found")
+	}
+	if util.TotalLines != 6 || util.CoveredLines != 5 || util.Lines[1].ExecutionCount != 2 {
+		t.Errorf("Expected 5 of 6 lines covered, got: %d of %d", util.CoveredLines, util.TotalLines)
+	}
+	if util.TotalBranches != 4 || util.CoveredBranches != 3 {
+		t.Errorf("Expected 3 of 4 branches covered, got: %d of %d", util.CoveredBranches, util.TotalBranches)
+	}
+	if len(util.Functions) != 1 || util.Functions[0].ExecutionCount != 2 {
+		t.Errorf("Expected clamp to be called twice, got: %+v", util.Functions)
+	}
+}
+
+func TestGcovJSONParser_ParseDir_SkipsBrokenFiles(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte(`{"gcc_version": "12.2.0", "files": [{"file": "a.c", "lines": [{"line_number": 1, "count": 1}]}]}`))
+	_ = gz.Close()
+
+	parser := NewGcovJSONParser()
+	report, err := parser.ParseDir(fstest.MapFS{
+		"a.gcov.json.gz": &fstest.MapFile{Data: compressed.Bytes()},
+		"b.gcov.json":    &fstest.MapFile{Data: []byte("{not json")},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeInvalidRecord {
+		t.Errorf("Expected one invalid-record warning, got: %v", parser.Diagnostics())
+	}
+	if report.Files["a.c"] == nil {
+		t.Error("Expected the readable file to be loaded")
+	}
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// GcovrJSONParser parses the JSON report written by gcovr --json, which
+// combines the gcov data of all translation units of a project
+type GcovrJSONParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewGcovrJSONParser creates a new gcovr JSON parser instance
+func NewGcovrJSONParser() *GcovrJSONParser {
+	return &GcovrJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// GcovrFile represents a source file of a gcovr report, relative to the gcovr root
+type GcovrFile struct {
+	File      string          `json:"file"`
+	Lines     []GcovrLine     `json:"lines"`
+	Functions []GcovrFunction `json:"functions"`
+}
+
+// GcovrLine represents a line entry of a gcovr report. gcovr marks lines
+// without code and lines excluded by comments instead of leaving them out.
+type GcovrLine struct {
+	GcovLine
+	Noncode  bool `json:"gcovr/noncode"`
+	Excluded bool `json:"gcovr/excluded"`
+}
+
+// GcovrFunction represents a function entry of a gcovr report
+type GcovrFunction struct {
+	Name           string `json:"name"`
+	DemangledName  string `json:"demangled_name"`
+	LineNo         int    `json:"lineno"`
+	ExecutionCount int    `json:"execution_count"`
+	Excluded       bool   `json:"gcovr/excluded"`
+}
+
+// Parse reads and parses a gcovr JSON report. File entries are decoded one at a time.
+func (p *GcovrJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(key string) error {
+		if key != "files" {
+			return skipJSONValue(decoder)
+		}
+		return forEachJSONElement(decoder, func() error {
+			var fileData GcovrFile
+			if err := decoder.Decode(&fileData); err != nil {
+				return err
+			}
+			p.parseFile(fileData, report)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile converts the lines and functions of a file, leaving out the ones
+// gcovr marks as non-code or excluded
+func (p *GcovrJSONParser) parseFile(fileData GcovrFile, report *models.CoverageReport) {
+	if fileData.File == "" {
+		p.addWarning("file", CodeMissingData, "file entry without a file name")
+		return
+	}
+
+	file := &models.FileCoverage{
+		FileName:  fileData.File,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+
+	lines := make([]GcovLine, 0, len(fileData.Lines))
+	for _, line := range fileData.Lines {
+		if !line.Noncode && !line.Excluded {
+			lines = append(lines, line.GcovLine)
+		}
+	}
+	p.diagnostics = addGcovLines(file, lines, p.diagnostics)
+
+	for _, fn := range fileData.Functions {
+		if fn.Excluded {
+			continue
+		}
+		name := fn.DemangledName
+		if name == "" {
+			name = fn.Name
+		}
+		file.Functions = append(file.Functions, models.FunctionCoverage{
+			Name:           name,
+			LineNumber:     fn.LineNo,
+			ExecutionCount: fn.ExecutionCount,
+		})
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	if existing := report.GetFile(file.FileName); existing != nil {
+		existing.Merge(file)
+		return
+	}
+	report.AddFile(file)
+}
+
+// Name returns the canonical format name
+func (p *GcovrJSONParser) Name() string {
+	return "gcovr"
+}
+
+// Description returns a human readable format name
+func (p *GcovrJSONParser) Description() string {
+	return "gcovr JSON Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "gcovr"
+func (p *GcovrJSONParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns no patterns, gcovr reports have no conventional name
+// and the format is detected by content
+func (p *GcovrJSONParser) FilePatterns() []string {
+	return nil
+}
+
+// Sniff is certain when the document has one of the top-level "gcovr/" keys,
+// such as gcovr/format_version
+func (p *GcovrJSONParser) Sniff(header []byte) Confidence {
+	for _, path := range sniffJSON(header).find("*") {
+		if strings.HasPrefix(path[0], "gcovr/") {
+			return ConfidenceCertain
+		}
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document has a "gcovr/" key
+func (p *GcovrJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *GcovrJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *GcovrJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *GcovrJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
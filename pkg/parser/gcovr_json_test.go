@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGcovrJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/gcovr.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewGcovrJSONParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	util := report.Files["src/util.h"]
+	if util == nil {
+		t.Fatalf("Expected file 'src/util.h' not found")
+	}
+	// Line 2 is marked as non-code
+	if util.TotalLines != 6 || util.CoveredLines != 5 {
+		t.Errorf("Expected 5 of 6 lines covered, got: %d of %d", util.CoveredLines, util.TotalLines)
+	}
+	if util.TotalBranches != 4 || util.CoveredBranches != 3 {
+		t.Errorf("Expected 3 of 4 branches covered, got: %d of %d", util.CoveredBranches, util.TotalBranches)
+	}
+
+	// Excluded lines and functions are left out
+	main := report.Files["src/main.c"]
+	if main == nil {
+		t.Fatalf("Expected file 'src/main.c' not found")
+	}
+	if main.TotalLines != 4 || main.CoveragePct != 100.0 {
+		t.Errorf("Expected 4 covered lines, got: %d at %.2f%%", main.TotalLines, main.CoveragePct)
+	}
+	if len(main.Functions) != 1 || main.Functions[0].Name != "main" || main.Functions[0].LineNumber != 6 {
+		t.Errorf("Unexpected functions: %+v", main.Functions)
+	}
+}
+
+func TestGcovrJSONParser_Parse_MissingFileName(t *testing.T) {
+	input := `{"files": [{"lines": [{"line_number": 1, "count": 1}]}], "gcovr/format_version": "0.6"}`
+
+	parser := NewGcovrJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(report.Files) != 0 || len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeMissingData {
+		t.Errorf("Expected the file to be skipped with a warning, got: %v", parser.Diagnostics())
+	}
+}
+
+func TestGcovrJSONParser_Parse_InvalidJSON(t *testing.T) {
+	if _, err := NewGcovrJSONParser().Parse(strings.NewReader(`{"files": [`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
@@ -4,23 +4,32 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
 	"strconv"
 	"strings"
 
 	"github.com/Chapati-Systems/covpeek/pkg/models"
 )
 
-// GoCoverParser parses Go coverage format (.out) files
+// GoCoverParser parses Go coverage format (.out) files. Coverage is weighted by
+// statements like go test -cover reports it, lines are kept for the line metric.
 type GoCoverParser struct {
-	warnings []string
-	mode     string
+	diagnostics []Diagnostic
+	mode        string
+	// blocks maps the position of each block to its index in the file's Blocks,
+	// profiles merged from several test binaries repeat blocks
+	blocks map[goBlockKey]int
+}
+
+// goBlockKey identifies a block of a Go cover profile
+type goBlockKey struct {
+	file                                       string
+	startLine, startColumn, endLine, endColumn int
 }
 
 // NewGoCoverParser creates a new Go coverage parser instance
 func NewGoCoverParser() *GoCoverParser {
 	return &GoCoverParser{
-		warnings: make([]string, 0),
+		diagnostics: make([]Diagnostic, 0),
 	}
 }
 
@@ -28,9 +37,12 @@ func NewGoCoverParser() *GoCoverParser {
 // Format: mode: set|count|atomic
 // Then: file:startLine.startCol,endLine.endCol numberOfStatements count
 func (p *GoCoverParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
 	report := models.NewCoverageReport()
+	report.Metric = models.MetricStatement
 	scanner := bufio.NewScanner(reader)
 	lineNumber := 0
+	p.blocks = make(map[goBlockKey]int)
 
 	// First line should be mode declaration
 	if !scanner.Scan() {
@@ -45,7 +57,7 @@ func (p *GoCoverParser) Parse(reader io.Reader) (*models.CoverageReport, error)
 
 	p.mode = strings.TrimSpace(strings.TrimPrefix(firstLine, "mode:"))
 	if p.mode != "set" && p.mode != "count" && p.mode != "atomic" {
-		p.addWarning(lineNumber, fmt.Sprintf("unknown coverage mode: %s", p.mode))
+		p.addWarning(lineNumber, "mode", CodeUnknownMode, fmt.Sprintf("unknown coverage mode: %s", p.mode))
 	}
 
 	// Parse coverage entries
@@ -59,7 +71,7 @@ func (p *GoCoverParser) Parse(reader io.Reader) (*models.CoverageReport, error)
 		}
 
 		if err := p.parseCoverageEntry(line, report, lineNumber); err != nil {
-			p.addWarning(lineNumber, err.Error())
+			p.addWarning(lineNumber, "block", CodeInvalidRecord, err.Error())
 			continue
 		}
 	}
@@ -68,14 +80,11 @@ func (p *GoCoverParser) Parse(reader io.Reader) (*models.CoverageReport, error)
 		return nil, fmt.Errorf("error reading coverage file: %w", err)
 	}
 
-	// Calculate coverage for all files
+	// Count once per file, blocks of a file may be spread over the profile
 	for _, file := range report.Files {
-		file.CalculateCoverage()
-	}
-
-	// Log all warnings
-	for _, warning := range p.warnings {
-		log.Println(warning)
+		file.CountLines()
+		file.CountStatements()
+		file.CalculateCoverageFor(report.Metric)
 	}
 
 	return report, nil
@@ -85,7 +94,7 @@ func (p *GoCoverParser) Parse(reader io.Reader) (*models.CoverageReport, error)
 // Format: file:startLine.startCol,endLine.endCol numberOfStatements count
 func (p *GoCoverParser) parseCoverageEntry(line string, report *models.CoverageReport, lineNum int) error {
 	// Split by colon to separate filename from coverage data
-	colonIdx := strings.Index(line, ":")
+	colonIdx := strings.LastIndex(line, ":")
 	if colonIdx == -1 {
 		return fmt.Errorf("invalid coverage entry format: %s", line)
 	}
@@ -121,11 +130,21 @@ func (p *GoCoverParser) parseCoverageEntry(line string, report *models.CoverageR
 		return fmt.Errorf("invalid start line number: %s", startParts[0])
 	}
 
+	startColumn, err := strconv.Atoi(startParts[1])
+	if err != nil {
+		return fmt.Errorf("invalid start column: %s", startParts[1])
+	}
+
 	endLine, err := strconv.Atoi(endParts[0])
 	if err != nil {
 		return fmt.Errorf("invalid end line number: %s", endParts[0])
 	}
 
+	endColumn, err := strconv.Atoi(endParts[1])
+	if err != nil {
+		return fmt.Errorf("invalid end column: %s", endParts[1])
+	}
+
 	// Parse number of statements
 	numStatements, err := strconv.Atoi(parts[1])
 	if err != nil {
@@ -138,6 +157,20 @@ func (p *GoCoverParser) parseCoverageEntry(line string, report *models.CoverageR
 		return fmt.Errorf("invalid execution count: %s", parts[2])
 	}
 
+	p.addBlock(report, filename, models.BlockCoverage{
+		StartLine:     startLine,
+		StartColumn:   startColumn,
+		EndLine:       endLine,
+		EndColumn:     endColumn,
+		NumStatements: numStatements,
+		Count:         execCount,
+	})
+	return nil
+}
+
+// addBlock adds a block of a profile to the report, merging it with repeated
+// blocks and the lines it spans according to the coverage mode
+func (p *GoCoverParser) addBlock(report *models.CoverageReport, filename string, block models.BlockCoverage) {
 	// Get or create file coverage entry
 	file := report.GetFile(filename)
 	if file == nil {
@@ -150,14 +183,14 @@ func (p *GoCoverParser) parseCoverageEntry(line string, report *models.CoverageR
 	}
 
 	// Add line coverage data for each line in the range
-	for lineNo := startLine; lineNo <= endLine; lineNo++ {
+	for lineNo := block.StartLine; lineNo <= block.EndLine; lineNo++ {
 		// If line already exists, update count (take max or sum based on mode)
 		if existing, exists := file.Lines[lineNo]; exists {
 			// For count mode, we sum the counts; for set mode, we just mark as covered
 			if p.mode == "count" || p.mode == "atomic" {
-				existing.ExecutionCount += execCount
+				existing.ExecutionCount += block.Count
 			} else {
-				if execCount > 0 {
+				if block.Count > 0 {
 					existing.ExecutionCount = 1
 				}
 			}
@@ -165,54 +198,85 @@ func (p *GoCoverParser) parseCoverageEntry(line string, report *models.CoverageR
 		} else {
 			file.Lines[lineNo] = models.LineCoverage{
 				LineNumber:     lineNo,
-				ExecutionCount: execCount,
+				ExecutionCount: block.Count,
 			}
 		}
 	}
 
-	// Update total lines and covered lines
-	for _, lineCov := range file.Lines {
-		if lineCov.ExecutionCount > 0 {
-			// Count covered lines
-			found := false
-			for existingLine := range file.Lines {
-				if existingLine == lineCov.LineNumber {
-					found = true
-					break
-				}
-			}
-			if !found {
-				file.CoveredLines++
+	// Keep the block for statement coverage, merging repeated blocks the way
+	// go tool cover does
+	key := goBlockKey{filename, block.StartLine, block.StartColumn, block.EndLine, block.EndColumn}
+	if i, exists := p.blocks[key]; exists {
+		existing := &file.Blocks[i]
+		if p.mode == "set" {
+			if block.Count > 0 {
+				existing.Count = 1
 			}
+		} else {
+			existing.Count += block.Count
 		}
+		return
 	}
+	p.blocks[key] = len(file.Blocks)
+	file.Blocks = append(file.Blocks, block)
+}
 
-	// Update total lines count
-	file.TotalLines = len(file.Lines)
+// Name returns the canonical format name
+func (p *GoCoverParser) Name() string {
+	return "go"
+}
 
-	// Count covered lines
-	coveredCount := 0
-	for _, lineCov := range file.Lines {
-		if lineCov.ExecutionCount > 0 {
-			coveredCount++
-		}
+// Description returns a human readable format name
+func (p *GoCoverParser) Description() string {
+	return "Go Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "go"
+func (p *GoCoverParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names Go cover profiles are usually written to
+func (p *GoCoverParser) FilePatterns() []string {
+	return []string{"*.out"}
+}
+
+// Sniff is certain when the first line is a "mode: set|count|atomic" declaration
+func (p *GoCoverParser) Sniff(header []byte) Confidence {
+	parts := strings.Fields(headerLines(header)[0])
+	if len(parts) != 2 || parts[0] != "mode:" {
+		return NoMatch
+	}
+	if parts[1] == "set" || parts[1] == "count" || parts[1] == "atomic" {
+		return ConfidenceCertain
 	}
-	file.CoveredLines = coveredCount
+	return NoMatch
+}
 
-	// Silently ignore numStatements for now (could be used for validation)
-	_ = numStatements
+// Detect reports whether the first line is a mode declaration
+func (p *GoCoverParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
 
-	return nil
+// addWarning adds a warning about a line of the profile to the parser
+func (p *GoCoverParser) addWarning(lineNum int, record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Line:     lineNum,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
 }
 
-// addWarning adds a warning message to the parser
-func (p *GoCoverParser) addWarning(lineNum int, message string) {
-	p.warnings = append(p.warnings, fmt.Sprintf("line %d: %s", lineNum, message))
+// Diagnostics returns all diagnostics collected during parsing
+func (p *GoCoverParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
 }
 
 // GetWarnings returns all warnings collected during parsing
 func (p *GoCoverParser) GetWarnings() []string {
-	return p.warnings
+	return diagnosticStrings(p.diagnostics)
 }
 
 // GetMode returns the coverage mode (set, count, or atomic)
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// goFuncExtent is the source range of a function declaration
+type goFuncExtent struct {
+	name                                       string
+	startLine, startColumn, endLine, endColumn int
+}
+
+// AddGoFunctions fills the function coverage of the Go files in a report from
+// their source, which Go cover profiles don't carry. The module is located by
+// searching dir and its parents for go.mod, profile file names are import paths
+// below the module path. Blocks are assigned to functions the way
+// go tool cover -func does. Files whose source cannot be found or parsed are
+// reported as diagnostics, an error is only returned when there is no module.
+func AddGoFunctions(report *models.CoverageReport, dir string) ([]Diagnostic, error) {
+	root, modulePath, err := findGoModule(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, name := range sortedFileNames(report) {
+		file := report.Files[name]
+		if len(file.Blocks) == 0 {
+			continue
+		}
+
+		path := goSourcePath(root, modulePath, name)
+		if path == "" {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Record:   "file",
+				Code:     CodeMissingSource,
+				Message:  fmt.Sprintf("cannot find source of %s in module %s", name, modulePath),
+			})
+			continue
+		}
+
+		extents, err := goFuncExtents(path)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Record:   "file",
+				Code:     CodeMissingSource,
+				Message:  fmt.Sprintf("cannot parse source of %s: %v", name, err),
+			})
+			continue
+		}
+
+		file.Functions = goFunctionCoverage(extents, file.Blocks)
+	}
+
+	return diagnostics, nil
+}
+
+// findGoModule returns the directory of the go.mod in dir or its closest
+// parent, and the module path declared in it
+func findGoModule(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			modulePath := GoModulePath(data)
+			if modulePath == "" {
+				return "", "", fmt.Errorf("no module directive in %s", filepath.Join(dir, "go.mod"))
+			}
+			return dir, modulePath, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// GoModulePath returns the path of the module directive of a go.mod file, ""
+// if it has none
+func GoModulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path
+		}
+		return fields[1]
+	}
+	return ""
+}
+
+// goSourcePath maps a profile file name to a file on disk. Names below the
+// module path are resolved against the module root, absolute and root-relative
+// names are used as they are. Returns "" if the file does not exist.
+func goSourcePath(root, modulePath, name string) string {
+	var candidates []string
+	if rel, ok := strings.CutPrefix(name, modulePath+"/"); ok {
+		candidates = append(candidates, filepath.Join(root, filepath.FromSlash(rel)))
+	}
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		candidates = append(candidates, filepath.Join(root, filepath.FromSlash(name)))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// goFuncExtents parses a Go file and returns the extents of its functions with
+// a body, in source order. Methods are named Type.Method.
+func goFuncExtents(path string) ([]goFuncExtent, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var extents []goFuncExtent
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		// Declarations of assembly functions have no body
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			if recv := goReceiverName(fn.Recv.List[0].Type); recv != "" {
+				name = recv + "." + name
+			}
+		}
+
+		start := fset.Position(fn.Pos())
+		end := fset.Position(fn.End())
+		extents = append(extents, goFuncExtent{
+			name:        name,
+			startLine:   start.Line,
+			startColumn: start.Column,
+			endLine:     end.Line,
+			endColumn:   end.Column,
+		})
+	}
+	return extents, nil
+}
+
+// goReceiverName returns the type name of a method receiver, without pointer
+// and type parameters
+func goReceiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// goFunctionCoverage counts the statements of the blocks inside each function.
+// A function's execution count is the count of its first block.
+func goFunctionCoverage(extents []goFuncExtent, blocks []models.BlockCoverage) []models.FunctionCoverage {
+	sorted := append([]models.BlockCoverage(nil), blocks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].StartLine != sorted[j].StartLine {
+			return sorted[i].StartLine < sorted[j].StartLine
+		}
+		return sorted[i].StartColumn < sorted[j].StartColumn
+	})
+
+	functions := make([]models.FunctionCoverage, 0, len(extents))
+	for _, extent := range extents {
+		fn := models.FunctionCoverage{
+			Name:       extent.name,
+			LineNumber: extent.startLine,
+		}
+
+		first := true
+		for _, b := range sorted {
+			if b.StartLine > extent.endLine || (b.StartLine == extent.endLine && b.StartColumn >= extent.endColumn) {
+				// Past the end of the function
+				break
+			}
+			if b.EndLine < extent.startLine || (b.EndLine == extent.startLine && b.EndColumn <= extent.startColumn) {
+				// Before the beginning of the function
+				continue
+			}
+			if first {
+				fn.ExecutionCount = b.Count
+				first = false
+			}
+			fn.TotalStatements += b.NumStatements
+			if b.Count > 0 {
+				fn.CoveredStatements += b.NumStatements
+			}
+		}
+
+		if fn.TotalStatements > 0 {
+			fn.CoveragePct = (float64(fn.CoveredStatements) / float64(fn.TotalStatements)) * 100.0
+		}
+		functions = append(functions, fn)
+	}
+	return functions
+}
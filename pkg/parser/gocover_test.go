@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"os"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
 )
 
 func TestGoCoverParser_Parse_ValidFile(t *testing.T) {
@@ -297,3 +301,176 @@ file.go:5.10,7.2 1 1
 		t.Error("Expected warnings for malformed line")
 	}
 }
+
+func TestGoCoverParser_Parse_StatementCoverage(t *testing.T) {
+	// Lines 3-5 hold 3 covered statements, line 6 one uncovered statement.
+	// Line coverage would be 3/4 lines, statement coverage weights the blocks.
+	input := `mode: set
+myproject/file.go:3.20,5.3 3 1
+myproject/file.go:5.3,6.2 1 0
+`
+
+	parser := NewGoCoverParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.Metric != models.MetricStatement {
+		t.Errorf("Expected statement metric, got: %s", report.Metric)
+	}
+
+	file := report.Files["myproject/file.go"]
+	if len(file.Blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got: %d", len(file.Blocks))
+	}
+	expected := models.BlockCoverage{StartLine: 3, StartColumn: 20, EndLine: 5, EndColumn: 3, NumStatements: 3, Count: 1}
+	if file.Blocks[0] != expected {
+		t.Errorf("Expected block %+v, got: %+v", expected, file.Blocks[0])
+	}
+
+	if file.TotalStatements != 4 || file.CoveredStatements != 3 {
+		t.Errorf("Expected 3/4 statements, got: %d/%d", file.CoveredStatements, file.TotalStatements)
+	}
+	if file.CoveragePct != 75.0 {
+		t.Errorf("Expected 75%% statement coverage, got: %.2f", file.CoveragePct)
+	}
+
+	// Line 5 is shared by both blocks and covered by the first
+	if file.TotalLines != 4 || file.CoveredLines != 3 {
+		t.Errorf("Expected 3/4 lines, got: %d/%d", file.CoveredLines, file.TotalLines)
+	}
+}
+
+func TestGoCoverParser_Parse_ZeroStatementBlock(t *testing.T) {
+	// An empty func main is a block without statements, go tool cover leaves
+	// it out of the total
+	input := `mode: set
+myproject/main.go:6.14,7.2 0 0
+myproject/file.go:3.20,5.3 3 1
+myproject/file.go:5.3,6.2 1 0
+`
+
+	parser := NewGoCoverParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	total, covered, pct := report.CalculateOverallCoverage()
+	if total != 4 || covered != 3 || pct != 75.0 {
+		t.Errorf("Expected 3/4 statements, got: %d/%d (%.2f%%)", covered, total, pct)
+	}
+	if root := report.Tree(); root.TotalLines != 4 {
+		t.Errorf("Expected 4 statements in the tree, got: %d", root.TotalLines)
+	}
+}
+
+func TestGoCoverParser_Parse_RepeatedBlocks(t *testing.T) {
+	// Profiles of several packages testing the same code repeat its blocks
+	tests := []struct {
+		mode     string
+		expected int
+	}{
+		{"set", 1},
+		{"count", 5},
+		{"atomic", 5},
+	}
+
+	for _, test := range tests {
+		input := "mode: " + test.mode + `
+myproject/file.go:3.20,5.3 2 0
+myproject/file.go:3.20,5.3 2 2
+myproject/file.go:3.20,5.3 2 3
+`
+		report, err := NewGoCoverParser().Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		file := report.Files["myproject/file.go"]
+		if len(file.Blocks) != 1 || file.Blocks[0].Count != test.expected {
+			t.Errorf("%s: expected one block with count %d, got: %+v", test.mode, test.expected, file.Blocks)
+		}
+		if file.TotalStatements != 2 || file.CoveredStatements != 2 {
+			t.Errorf("%s: expected 2/2 statements, got: %d/%d", test.mode, file.CoveredStatements, file.TotalStatements)
+		}
+	}
+}
+
+func TestAddGoFunctions(t *testing.T) {
+	file, err := os.Open("../../testdata/gomodule/coverage.out")
+	if err != nil {
+		t.Fatalf("Failed to open profile: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	report, err := NewGoCoverParser().Parse(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	diagnostics, err := AddGoFunctions(report, "../../testdata/gomodule")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got: %v", diagnostics)
+	}
+
+	// Matches go tool cover -func for the profile
+	expected := []models.FunctionCoverage{
+		{Name: "Add", LineNumber: 3, ExecutionCount: 1, TotalStatements: 1, CoveredStatements: 1, CoveragePct: 100},
+		{Name: "Checker.Check", LineNumber: 9, ExecutionCount: 1, TotalStatements: 3, CoveredStatements: 2, CoveragePct: float64(2) / float64(3) * 100.0},
+		{Name: "unused", LineNumber: 16, ExecutionCount: 0, TotalStatements: 1, CoveredStatements: 0, CoveragePct: 0},
+	}
+	functions := report.GetFile("example.com/demo/demo.go").Functions
+	if len(functions) != len(expected) {
+		t.Fatalf("Expected %d functions, got: %+v", len(expected), functions)
+	}
+	for i, fn := range expected {
+		if !reflect.DeepEqual(functions[i], fn) {
+			t.Errorf("Expected %+v, got: %+v", fn, functions[i])
+		}
+	}
+}
+
+func TestAddGoFunctions_MissingSource(t *testing.T) {
+	input := `mode: set
+example.com/demo/missing.go:4.2,5.1 1 1
+other.org/lib/lib.go:4.2,5.1 1 1
+`
+	report, err := NewGoCoverParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	diagnostics, err := AddGoFunctions(report, "../../testdata/gomodule")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(diagnostics) != 2 || diagnostics[0].Code != CodeMissingSource {
+		t.Errorf("Expected a missing-source diagnostic per file, got: %v", diagnostics)
+	}
+
+	if _, err := AddGoFunctions(report, t.TempDir()); err == nil || !strings.Contains(err.Error(), "go.mod not found") {
+		t.Errorf("Expected an error outside of a module, got: %v", err)
+	}
+}
+
+func TestGoModulePath(t *testing.T) {
+	tests := []struct {
+		gomod    string
+		expected string
+	}{
+		{"module example.com/shop\n\ngo 1.25\n", "example.com/shop"},
+		{"// The shop\nmodule example.com/shop // v2 is next\n", "example.com/shop"},
+		{"module \"example.com/shop\"\n", "example.com/shop"},
+		{"go 1.25\n", ""},
+	}
+	for _, tt := range tests {
+		if path := GoModulePath([]byte(tt.gomod)); path != tt.expected {
+			t.Errorf("GoModulePath(%q) = %q, expected %q", tt.gomod, path, tt.expected)
+		}
+	}
+}
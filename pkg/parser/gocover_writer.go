@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// goCoverLineEndColumn closes the one-line block written for every line. Go
+// profiles address columns, which other formats don't record, and go tool cover
+// ends a block at the next line when the column lies past the end of the line.
+const goCoverLineEndColumn = 1000
+
+// GoCoverWriter writes coverage reports as Go cover profiles
+type GoCoverWriter struct{}
+
+// NewGoCoverWriter creates a new Go cover profile writer instance
+func NewGoCoverWriter() *GoCoverWriter {
+	return &GoCoverWriter{}
+}
+
+// Name returns the canonical format name
+func (w *GoCoverWriter) Name() string {
+	return "go"
+}
+
+// Write writes the report in count mode. Files read from a Go profile keep
+// their blocks, other files get one single-statement block per line. Function
+// and branch data have no place in the profile and are dropped.
+func (w *GoCoverWriter) Write(writer io.Writer, report *models.CoverageReport) error {
+	out := bufio.NewWriter(writer)
+
+	fmt.Fprintln(out, "mode: count")
+	for _, name := range sortedFileNames(report) {
+		file := report.Files[name]
+		if len(file.Blocks) > 0 {
+			for _, block := range file.Blocks {
+				fmt.Fprintf(out, "%s:%d.%d,%d.%d %d %d\n", file.FileName, block.StartLine, block.StartColumn, block.EndLine, block.EndColumn, block.NumStatements, block.Count)
+			}
+			continue
+		}
+		for _, lineNum := range sortedLineNumbers(file) {
+			fmt.Fprintf(out, "%s:%d.1,%d.%d 1 %d\n", file.FileName, lineNum, lineNum, goCoverLineEndColumn, file.Lines[lineNum].ExecutionCount)
+		}
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,607 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// Magic strings of the binary files written to GOCOVERDIR, see
+// internal/coverage in the Go source tree
+var (
+	goCovMetaMagic    = []byte{0x00, 'c', 'v', 'm'}
+	goCovCounterMagic = []byte{0x00, 'c', 'w', 'm'}
+)
+
+const (
+	// goCovFileVersion is the newest meta-data and counter file version understood
+	goCovFileVersion = 1
+	// goCovPackageHeaderSize is the size of the header of a package in a meta-data file
+	goCovPackageHeaderSize = 44
+	// goCovFooterSize is the size of the footer ending every counter segment
+	goCovFooterSize = 16
+)
+
+// Counter encodings of counter data files
+const (
+	goCovFlavorRaw     = 1
+	goCovFlavorULEB128 = 2
+)
+
+// errGoCoverDirFile is returned when a single file of a GOCOVERDIR is parsed
+var errGoCoverDirFile = errors.New("Go coverage data files cannot be read one by one, pass the GOCOVERDIR directory instead")
+
+// GoCoverDirParser parses the binary coverage data that Go programs built with
+// -cover write to GOCOVERDIR: covmeta.<hash> files describing the instrumented
+// packages and covcounters.<hash>.<pid>.<time> files holding the counters of one
+// run each. Counters of all runs are merged the way go tool covdata does.
+type GoCoverDirParser struct {
+	diagnostics []Diagnostic
+	mode        string
+}
+
+// NewGoCoverDirParser creates a new GOCOVERDIR parser instance
+func NewGoCoverDirParser() *GoCoverDirParser {
+	return &GoCoverDirParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// goCovMeta is a decoded meta-data file
+type goCovMeta struct {
+	hash     [16]byte
+	mode     string
+	perFunc  bool
+	packages [][]goCovFunc
+}
+
+// goCovFunc describes the blocks of a function in a meta-data file, counts
+// accumulates the counters of all runs
+type goCovFunc struct {
+	name   string
+	file   string
+	lit    bool
+	units  []models.BlockCoverage
+	counts []int
+}
+
+// goCovCounters is a decoded counter data file
+type goCovCounters struct {
+	hash  [16]byte
+	funcs []goCovFuncCounters
+}
+
+// goCovFuncCounters holds the counters of a function in a counter data file
+type goCovFuncCounters struct {
+	pkg, fn  uint32
+	counters []uint32
+}
+
+// goCovFuncKey identifies a function across meta-data files
+type goCovFuncKey struct {
+	file, name string
+}
+
+// Parse always fails, GOCOVERDIR data is read with ParseDir
+func (p *GoCoverDirParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	return nil, errGoCoverDirFile
+}
+
+// ParseDir reads the meta-data and counter files of a GOCOVERDIR. Files that
+// cannot be decoded are skipped with a warning.
+func (p *GoCoverDirParser) ParseDir(fsys fs.FS) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var metaNames, counterNames []string
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir():
+		case strings.HasPrefix(entry.Name(), "covmeta."):
+			metaNames = append(metaNames, entry.Name())
+		case strings.HasPrefix(entry.Name(), "covcounters."):
+			counterNames = append(counterNames, entry.Name())
+		}
+	}
+	if len(metaNames) == 0 {
+		return nil, fmt.Errorf("no covmeta files found")
+	}
+
+	var metas []*goCovMeta
+	byHash := make(map[[16]byte]*goCovMeta)
+	for _, name := range metaNames {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := decodeGoCovMeta(data)
+		if err != nil {
+			p.addWarning("meta", CodeInvalidRecord, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if p.mode == "" {
+			p.mode = meta.mode
+		} else if meta.mode != p.mode {
+			p.addWarning("meta", CodeInvalidValue, fmt.Sprintf("%s: counter mode %s differs from %s, counters are merged as %s", name, meta.mode, p.mode, p.mode))
+		}
+		metas = append(metas, meta)
+		byHash[meta.hash] = meta
+	}
+	if len(metas) == 0 {
+		return nil, fmt.Errorf("no readable covmeta files found")
+	}
+
+	for _, name := range counterNames {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		counters, err := decodeGoCovCounters(data)
+		if err != nil {
+			p.addWarning("counters", CodeInvalidRecord, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		meta := byHash[counters.hash]
+		if meta == nil {
+			p.addWarning("counters", CodeOrphanRecord, fmt.Sprintf("%s: no covmeta file for hash %x", name, counters.hash))
+			continue
+		}
+		p.addCounters(name, meta, counters)
+	}
+
+	return p.buildReport(metas), nil
+}
+
+// addCounters adds the counters of one counter data file to its meta-data
+func (p *GoCoverDirParser) addCounters(name string, meta *goCovMeta, counters *goCovCounters) {
+	for _, fc := range counters.funcs {
+		if int(fc.pkg) >= len(meta.packages) || int(fc.fn) >= len(meta.packages[fc.pkg]) {
+			p.addWarning("counters", CodeInvalidRecord, fmt.Sprintf("%s: counters for unknown function %d of package %d", name, fc.fn, fc.pkg))
+			continue
+		}
+
+		fn := &meta.packages[fc.pkg][fc.fn]
+		for i := range fn.units {
+			var count int
+			switch {
+			case meta.perFunc && len(fc.counters) == 1:
+				// One counter covers all blocks of the function
+				count = int(fc.counters[0])
+			case i < len(fc.counters):
+				count = int(fc.counters[i])
+			}
+
+			if meta.mode == "set" {
+				if count > 0 {
+					fn.counts[i] = 1
+				}
+			} else {
+				fn.counts[i] += count
+			}
+		}
+	}
+}
+
+// buildReport turns the blocks of all meta-data files into a report. Packages
+// described by several meta-data files, one per test binary, are merged.
+func (p *GoCoverDirParser) buildReport(metas []*goCovMeta) *models.CoverageReport {
+	report := models.NewCoverageReport()
+	report.Metric = models.MetricStatement
+
+	profile := &GoCoverParser{mode: p.mode, blocks: make(map[goBlockKey]int)}
+	var order []goCovFuncKey
+	funcBlocks := make(map[goCovFuncKey][]goBlockKey)
+	for _, meta := range metas {
+		for _, funcs := range meta.packages {
+			for _, fn := range funcs {
+				key := goCovFuncKey{fn.file, strings.TrimPrefix(fn.name, "*")}
+				_, seen := funcBlocks[key]
+				for i, unit := range fn.units {
+					unit.Count = fn.counts[i]
+					profile.addBlock(report, fn.file, unit)
+					if !seen {
+						funcBlocks[key] = append(funcBlocks[key], goBlockKey{fn.file, unit.StartLine, unit.StartColumn, unit.EndLine, unit.EndColumn})
+					}
+				}
+				// Function literals are counted as blocks but not listed
+				if !seen && !fn.lit && len(fn.units) > 0 {
+					order = append(order, key)
+				}
+			}
+		}
+	}
+
+	// Function coverage is computed from the merged blocks
+	for _, key := range order {
+		file := report.GetFile(key.file)
+		fn := models.FunctionCoverage{Name: key.name}
+		for i, blockKey := range funcBlocks[key] {
+			block := file.Blocks[profile.blocks[blockKey]]
+			if i == 0 {
+				fn.LineNumber = block.StartLine
+				fn.ExecutionCount = block.Count
+			}
+			fn.TotalStatements += block.NumStatements
+			if block.Count > 0 {
+				fn.CoveredStatements += block.NumStatements
+			}
+		}
+		if fn.TotalStatements > 0 {
+			fn.CoveragePct = (float64(fn.CoveredStatements) / float64(fn.TotalStatements)) * 100.0
+		}
+		file.Functions = append(file.Functions, fn)
+	}
+
+	// Count once per file, like a profile written by go tool covdata textfmt
+	for _, file := range report.Files {
+		file.CountLines()
+		file.CountStatements()
+		file.CalculateCoverageFor(report.Metric)
+	}
+
+	return report
+}
+
+// decodeGoCovMeta decodes a covmeta file: a header, the offsets and lengths of
+// the packages, a string table and one self-contained blob per package
+func decodeGoCovMeta(data []byte) (*goCovMeta, error) {
+	r := &goCovReader{data: data}
+	if !bytes.Equal(r.bytes(4), goCovMetaMagic) {
+		return nil, fmt.Errorf("not a Go coverage meta-data file")
+	}
+	if version := r.uint32(); version > goCovFileVersion {
+		return nil, fmt.Errorf("unsupported meta-data file version %d", version)
+	}
+	r.uint64() // total length
+	numPackages := r.uint64()
+
+	meta := &goCovMeta{}
+	copy(meta.hash[:], r.bytes(16))
+	r.uint32() // string table offset
+	r.uint32() // string table length
+
+	switch mode := r.uint8(); mode {
+	case 1:
+		meta.mode = "set"
+	case 2:
+		meta.mode = "count"
+	case 3:
+		meta.mode = "atomic"
+	default:
+		return nil, fmt.Errorf("unknown counter mode %d", mode)
+	}
+	meta.perFunc = r.uint8() == 2
+	r.bytes(6) // padding
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if numPackages > uint64(len(data))/16 {
+		return nil, fmt.Errorf("invalid package count %d", numPackages)
+	}
+	offsets := make([]uint64, numPackages)
+	for i := range offsets {
+		offsets[i] = r.uint64()
+	}
+	lengths := make([]uint64, numPackages)
+	for i := range lengths {
+		lengths[i] = r.uint64()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for i := range offsets {
+		if offsets[i] > uint64(len(data)) || lengths[i] > uint64(len(data))-offsets[i] {
+			return nil, fmt.Errorf("package %d lies outside of the file", i)
+		}
+		funcs, err := decodeGoCovPackage(data[offsets[i] : offsets[i]+lengths[i]])
+		if err != nil {
+			return nil, fmt.Errorf("package %d: %w", i, err)
+		}
+		meta.packages = append(meta.packages, funcs)
+	}
+	return meta, nil
+}
+
+// decodeGoCovPackage decodes the functions of a package blob of a meta-data file
+func decodeGoCovPackage(data []byte) ([]goCovFunc, error) {
+	r := &goCovReader{data: data}
+	r.bytes(goCovPackageHeaderSize - 4)
+	numFuncs := r.uint32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if uint64(numFuncs) > uint64(len(data))/4 {
+		return nil, fmt.Errorf("invalid function count %d", numFuncs)
+	}
+
+	offsets := make([]uint32, numFuncs)
+	for i := range offsets {
+		offsets[i] = r.uint32()
+	}
+	strtab := r.stringTable()
+
+	funcs := make([]goCovFunc, 0, numFuncs)
+	for _, offset := range offsets {
+		r.seek(int(offset))
+		numUnits := r.uleb128()
+		fn := goCovFunc{
+			name: r.lookup(strtab, r.uleb128()),
+			file: r.lookup(strtab, r.uleb128()),
+		}
+		if r.err != nil || numUnits > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid function at offset %d", offset)
+		}
+
+		for k := uint64(0); k < numUnits; k++ {
+			fn.units = append(fn.units, models.BlockCoverage{
+				StartLine:     int(r.uleb128()),
+				StartColumn:   int(r.uleb128()),
+				EndLine:       int(r.uleb128()),
+				EndColumn:     int(r.uleb128()),
+				NumStatements: int(r.uleb128()),
+			})
+		}
+		fn.lit = r.uleb128() != 0
+		fn.counts = make([]int, len(fn.units))
+		funcs = append(funcs, fn)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return funcs, nil
+}
+
+// decodeGoCovCounters decodes a covcounters file: a header followed by one or
+// more segments, each holding the counters of a run and ending in a footer
+func decodeGoCovCounters(data []byte) (*goCovCounters, error) {
+	r := &goCovReader{data: data}
+	if !bytes.Equal(r.bytes(4), goCovCounterMagic) {
+		return nil, fmt.Errorf("not a Go coverage counter data file")
+	}
+	if version := r.uint32(); version > goCovFileVersion {
+		return nil, fmt.Errorf("unsupported counter data file version %d", version)
+	}
+
+	counters := &goCovCounters{}
+	copy(counters.hash[:], r.bytes(16))
+	flavor := r.uint8()
+	bigEndian := r.uint8() != 0
+	r.bytes(6) // padding
+	if r.err != nil {
+		return nil, r.err
+	}
+	if flavor != goCovFlavorRaw && flavor != goCovFlavorULEB128 {
+		return nil, fmt.Errorf("unknown counter encoding %d", flavor)
+	}
+
+	// The footer of the last segment holds the number of segments
+	footer := &goCovReader{data: data, off: len(data) - goCovFooterSize}
+	if footer.off < r.off || !bytes.Equal(footer.bytes(4), goCovCounterMagic) {
+		return nil, fmt.Errorf("missing counter data file footer")
+	}
+	footer.uint32() // padding
+	numSegments := footer.uint32()
+
+	value := func() uint32 {
+		if flavor == goCovFlavorULEB128 {
+			return uint32(r.uleb128())
+		}
+		b := r.bytes(4)
+		if b == nil {
+			return 0
+		}
+		if bigEndian {
+			return binary.BigEndian.Uint32(b)
+		}
+		return binary.LittleEndian.Uint32(b)
+	}
+
+	for segment := uint32(0); segment < numSegments && r.err == nil; segment++ {
+		numFuncs := r.uint64()
+		stringTableLength := r.uint32()
+		argsLength := r.uint32()
+		// The string table and arguments describe the run, e.g. os.Args
+		r.bytes(int(stringTableLength))
+		r.bytes(int(argsLength))
+		r.seek((r.off + 3) &^ 3)
+
+		for i := uint64(0); i < numFuncs && r.err == nil; i++ {
+			numCounters := value()
+			fc := goCovFuncCounters{pkg: value(), fn: value()}
+			if uint64(numCounters) > uint64(len(data)-r.off) {
+				r.fail()
+				break
+			}
+			fc.counters = make([]uint32, numCounters)
+			for k := range fc.counters {
+				fc.counters[k] = value()
+			}
+			counters.funcs = append(counters.funcs, fc)
+		}
+		r.bytes(goCovFooterSize)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return counters, nil
+}
+
+// goCovReader reads the little-endian and ULEB128 encoded values of Go's
+// binary coverage files. Reading past the end sets err and returns zero values.
+type goCovReader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// fail records that the data ended early
+func (r *goCovReader) fail() {
+	if r.err == nil {
+		r.err = fmt.Errorf("unexpected end of data at offset %d", r.off)
+	}
+}
+
+// seek moves to an offset from the start of the data
+func (r *goCovReader) seek(off int) {
+	if off < 0 || off > len(r.data) {
+		r.fail()
+		return
+	}
+	r.off = off
+}
+
+// bytes returns the next n bytes, or nil if there are fewer left
+func (r *goCovReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data)-r.off {
+		r.fail()
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *goCovReader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *goCovReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *goCovReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *goCovReader) uleb128() uint64 {
+	var value uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b := r.uint8()
+		if r.err != nil {
+			return 0
+		}
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value
+		}
+	}
+	r.err = fmt.Errorf("invalid ULEB128 value at offset %d", r.off)
+	return 0
+}
+
+// stringTable reads a string table: a count followed by length prefixed strings
+func (r *goCovReader) stringTable() []string {
+	count := r.uleb128()
+	if count > uint64(len(r.data)-r.off) {
+		r.fail()
+		return nil
+	}
+	strtab := make([]string, 0, count)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		length := r.uleb128()
+		if length > uint64(len(r.data)-r.off) {
+			r.fail()
+			break
+		}
+		strtab = append(strtab, string(r.bytes(int(length))))
+	}
+	return strtab
+}
+
+// lookup returns an entry of a string table
+func (r *goCovReader) lookup(strtab []string, index uint64) string {
+	if index >= uint64(len(strtab)) {
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid string table index %d", index)
+		}
+		return ""
+	}
+	return strtab[index]
+}
+
+// Name returns the canonical format name
+func (p *GoCoverDirParser) Name() string {
+	return "gocoverdir"
+}
+
+// Description returns a human readable format name
+func (p *GoCoverDirParser) Description() string {
+	return "Go Coverage Directory (GOCOVERDIR)"
+}
+
+// Aliases returns "covdata", the name of the Go tool reading the format
+func (p *GoCoverDirParser) Aliases() []string {
+	return []string{"covdata"}
+}
+
+// FilePatterns returns the names of the files inside a GOCOVERDIR, so that
+// passing one of them gives a hint to pass the directory
+func (p *GoCoverDirParser) FilePatterns() []string {
+	return []string{"covmeta.*", "covcounters.*"}
+}
+
+// Sniff is certain when the header starts with the magic string of a
+// meta-data or counter data file
+func (p *GoCoverDirParser) Sniff(header []byte) Confidence {
+	if bytes.HasPrefix(header, goCovMetaMagic) || bytes.HasPrefix(header, goCovCounterMagic) {
+		return ConfidenceCertain
+	}
+	return NoMatch
+}
+
+// Detect reports whether the header starts with a magic string
+func (p *GoCoverDirParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// DetectDir reports whether the directory holds a covmeta file
+func (p *GoCoverDirParser) DetectDir(fsys fs.FS) bool {
+	matches, err := fs.Glob(fsys, "covmeta.*")
+	return err == nil && len(matches) > 0
+}
+
+// addWarning adds a warning about a file of the directory to the parser
+func (p *GoCoverDirParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *GoCoverDirParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *GoCoverDirParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
+
+// GetMode returns the coverage mode (set, count, or atomic)
+func (p *GoCoverDirParser) GetMode() string {
+	return p.mode
+}
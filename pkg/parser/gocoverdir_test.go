@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// goCoverDirProfile is what go tool covdata textfmt writes for testdata/gocoverdir
+const goCoverDirProfile = `mode: set
+example.com/demo/demo.go:4.2,5.1 1 1
+example.com/demo/demo.go:10.2,10.11 1 1
+example.com/demo/demo.go:11.3,12.1 1 1
+example.com/demo/demo.go:13.2,13.14 1 1
+example.com/demo/demo.go:17.2,18.1 1 0
+`
+
+// readGoCoverDir copies testdata/gocoverdir into a MapFS that tests can modify
+func readGoCoverDir(t *testing.T) fstest.MapFS {
+	t.Helper()
+
+	dir := os.DirFS("../../testdata/gocoverdir")
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+	fsys := fstest.MapFS{}
+	for _, entry := range entries {
+		data, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			t.Fatalf("Failed to read test data: %v", err)
+		}
+		fsys[entry.Name()] = &fstest.MapFile{Data: data}
+	}
+	return fsys
+}
+
+func TestGoCoverDirParser_ParseDir(t *testing.T) {
+	parser := NewGoCoverDirParser()
+	report, err := parser.ParseDir(readGoCoverDir(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 0 {
+		t.Errorf("Expected no warnings, got: %v", parser.GetWarnings())
+	}
+	if parser.GetMode() != "set" {
+		t.Errorf("Expected mode 'set', got: %s", parser.GetMode())
+	}
+	if report.Metric != models.MetricStatement {
+		t.Errorf("Expected statement metric, got: %s", report.Metric)
+	}
+
+	// The report must match the one read from the textfmt profile
+	expected, err := NewGoCoverParser().Parse(strings.NewReader(goCoverDirProfile))
+	if err != nil {
+		t.Fatalf("Failed to parse expected profile: %v", err)
+	}
+	file := report.GetFile("example.com/demo/demo.go")
+	want := expected.GetFile("example.com/demo/demo.go")
+	if file == nil {
+		t.Fatalf("Expected file example.com/demo/demo.go, got: %v", report.Files)
+	}
+	if len(file.Blocks) != len(want.Blocks) {
+		t.Fatalf("Expected %d blocks, got: %d", len(want.Blocks), len(file.Blocks))
+	}
+	// Blocks of function literals follow their enclosing function, compare regardless of order
+	got := make(map[models.BlockCoverage]bool)
+	for _, block := range file.Blocks {
+		got[block] = true
+	}
+	for _, block := range want.Blocks {
+		if !got[block] {
+			t.Errorf("Expected block %+v, got: %+v", block, file.Blocks)
+		}
+	}
+	if file.CoveredStatements != want.CoveredStatements || file.TotalStatements != want.TotalStatements {
+		t.Errorf("Expected %d/%d statements, got: %d/%d", want.CoveredStatements, want.TotalStatements, file.CoveredStatements, file.TotalStatements)
+	}
+	if file.CoveragePct != want.CoveragePct {
+		t.Errorf("Expected %.2f%% coverage, got: %.2f%%", want.CoveragePct, file.CoveragePct)
+	}
+
+	// Function names come from the meta-data file
+	if len(file.Functions) == 0 {
+		t.Fatal("Expected function coverage from the meta-data file")
+	}
+	for _, fn := range file.Functions {
+		if fn.Name == "" || fn.TotalStatements == 0 {
+			t.Errorf("Expected named function with statements, got: %+v", fn)
+		}
+	}
+}
+
+func TestGoCoverDirParser_ParseDir_CorruptCounters(t *testing.T) {
+	fsys := readGoCoverDir(t)
+	for name, file := range fsys {
+		if strings.HasPrefix(name, "covcounters.") {
+			file.Data = file.Data[:len(file.Data)/2]
+			break
+		}
+	}
+
+	parser := NewGoCoverDirParser()
+	report, err := parser.ParseDir(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeInvalidRecord {
+		t.Errorf("Expected one invalid-record warning, got: %v", parser.Diagnostics())
+	}
+	if report.GetFile("example.com/demo/demo.go") == nil {
+		t.Error("Expected coverage from the remaining counter file")
+	}
+}
+
+func TestGoCoverDirParser_ParseDir_OrphanCounters(t *testing.T) {
+	fsys := readGoCoverDir(t)
+	for name := range fsys {
+		if strings.HasPrefix(name, "covmeta.") {
+			fsys["covmeta.00000000000000000000000000000000"] = &fstest.MapFile{Data: append([]byte(nil), fsys[name].Data...)}
+			// Change the hash so the counter files no longer match
+			fsys["covmeta.00000000000000000000000000000000"].Data[24] ^= 0xff
+			delete(fsys, name)
+			break
+		}
+	}
+
+	parser := NewGoCoverDirParser()
+	report, err := parser.ParseDir(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, diagnostic := range parser.Diagnostics() {
+		if diagnostic.Code != CodeOrphanRecord {
+			t.Errorf("Expected orphan-record warnings, got: %v", diagnostic)
+		}
+	}
+	if len(parser.Diagnostics()) != 2 {
+		t.Errorf("Expected a warning per counter file, got: %v", parser.Diagnostics())
+	}
+	if file := report.GetFile("example.com/demo/demo.go"); file == nil || file.CoveredStatements != 0 {
+		t.Errorf("Expected uncovered blocks without counters, got: %+v", file)
+	}
+}
+
+func TestGoCoverDirParser_ParseDir_NoMeta(t *testing.T) {
+	parser := NewGoCoverDirParser()
+	if _, err := parser.ParseDir(fstest.MapFS{"README": &fstest.MapFile{}}); err == nil {
+		t.Error("Expected error for a directory without covmeta files")
+	}
+}
+
+func TestGoCoverDirParser_Parse(t *testing.T) {
+	parser := NewGoCoverDirParser()
+	_, err := parser.Parse(strings.NewReader("\x00cvm"))
+	if err == nil || !strings.Contains(err.Error(), "GOCOVERDIR") {
+		t.Errorf("Expected error pointing at the directory, got: %v", err)
+	}
+}
+
+func TestForDirectory(t *testing.T) {
+	p := ForDirectory(readGoCoverDir(t))
+	if p == nil || p.Name() != "gocoverdir" {
+		t.Fatalf("Expected gocoverdir parser, got: %v", p)
+	}
+	if p := ForDirectory(fstest.MapFS{"coverage.out": &fstest.MapFile{}}); p != nil {
+		t.Errorf("Expected nil for a directory without coverage data, got: %s", p.Name())
+	}
+}
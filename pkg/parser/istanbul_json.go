@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// IstanbulJSONParser parses Istanbul's native coverage-final.json format
+type IstanbulJSONParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewIstanbulJSONParser creates a new Istanbul JSON coverage parser instance
+func NewIstanbulJSONParser() *IstanbulJSONParser {
+	return &IstanbulJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// IstanbulFileCoverage represents the coverage data for a single file in coverage-final.json
+type IstanbulFileCoverage struct {
+	Path         string                      `json:"path"`
+	StatementMap map[string]IstanbulLocation `json:"statementMap"`
+	FnMap        map[string]IstanbulFunction `json:"fnMap"`
+	BranchMap    map[string]IstanbulBranch   `json:"branchMap"`
+	S            map[string]int              `json:"s"`
+	F            map[string]int              `json:"f"`
+	B            map[string][]int            `json:"b"`
+}
+
+// IstanbulLocation represents a source range in the Istanbul format
+type IstanbulLocation struct {
+	Start IstanbulPosition `json:"start"`
+	End   IstanbulPosition `json:"end"`
+}
+
+// IstanbulPosition represents a line/column position in the Istanbul format
+type IstanbulPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// IstanbulFunction represents a function entry in fnMap
+type IstanbulFunction struct {
+	Name string           `json:"name"`
+	Decl IstanbulLocation `json:"decl"`
+	Loc  IstanbulLocation `json:"loc"`
+	Line int              `json:"line"`
+}
+
+// IstanbulBranch represents a branch entry in branchMap
+type IstanbulBranch struct {
+	Type      string             `json:"type"`
+	Loc       IstanbulLocation   `json:"loc"`
+	Locations []IstanbulLocation `json:"locations"`
+	Line      int                `json:"line"`
+}
+
+// Parse reads and parses an Istanbul coverage-final.json file. File entries
+// are decoded one at a time, so large reports are never held in memory as a whole.
+func (p *IstanbulJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(key string) error {
+		var fileData IstanbulFileCoverage
+		if err := decoder.Decode(&fileData); err != nil {
+			return err
+		}
+		filename := fileData.Path
+		if filename == "" {
+			filename = key
+		}
+		p.parseFile(filename, fileData, report)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Calculate coverage for all files
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile converts the statement, function and branch maps of a single file
+func (p *IstanbulJSONParser) parseFile(filename string, fileData IstanbulFileCoverage, report *models.CoverageReport) {
+	file := &models.FileCoverage{
+		FileName:  filename,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+
+	// Statements: a line takes the highest count of the statements starting on it,
+	// matching how Istanbul derives line coverage
+	for _, id := range sortedIDs(fileData.StatementMap) {
+		count, ok := fileData.S[id]
+		if !ok {
+			p.addWarning("statement", CodeMissingData, fmt.Sprintf("file %s: statement %s has no execution count", filename, id))
+		}
+		lineNum := fileData.StatementMap[id].Start.Line
+		if existing, exists := file.Lines[lineNum]; !exists || existing.ExecutionCount < count {
+			file.Lines[lineNum] = models.LineCoverage{
+				LineNumber:     lineNum,
+				ExecutionCount: count,
+			}
+		}
+	}
+
+	// Functions
+	for _, id := range sortedIDs(fileData.FnMap) {
+		fn := fileData.FnMap[id]
+		count, ok := fileData.F[id]
+		if !ok {
+			p.addWarning("function", CodeMissingData, fmt.Sprintf("file %s: function %s has no execution count", filename, id))
+		}
+		lineNum := fn.Decl.Start.Line
+		if lineNum == 0 {
+			lineNum = fn.Loc.Start.Line
+		}
+		if lineNum == 0 {
+			lineNum = fn.Line
+		}
+		file.Functions = append(file.Functions, models.FunctionCoverage{
+			Name:           fn.Name,
+			LineNumber:     lineNum,
+			ExecutionCount: count,
+		})
+	}
+
+	// Branches: each location of a branch is one outcome
+	for _, id := range sortedIDs(fileData.BranchMap) {
+		branch := fileData.BranchMap[id]
+		counts := fileData.B[id]
+		if len(counts) != len(branch.Locations) {
+			p.addWarning("branch", CodeMissingData, fmt.Sprintf("file %s: branch %s has %d locations but %d counts", filename, id, len(branch.Locations), len(counts)))
+		}
+		block, _ := strconv.Atoi(id)
+		lineNum := branch.Loc.Start.Line
+		if lineNum == 0 {
+			lineNum = branch.Line
+		}
+		for i := range branch.Locations {
+			taken := 0
+			if i < len(counts) {
+				taken = counts[i]
+			}
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber:  lineNum,
+				BlockNumber: block,
+				BranchID:    strconv.Itoa(i),
+				TakenCount:  taken,
+			})
+		}
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	report.AddFile(file)
+}
+
+// sortedIDs returns the keys of an Istanbul map in numeric order
+func sortedIDs[V any](m map[string]V) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA != nil || errB != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+	return ids
+}
+
+// Name returns the canonical format name
+func (p *IstanbulJSONParser) Name() string {
+	return "istanbul"
+}
+
+// Description returns a human readable format name
+func (p *IstanbulJSONParser) Description() string {
+	return "Istanbul JSON Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "istanbul"
+func (p *IstanbulJSONParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names Istanbul reports are usually written to
+func (p *IstanbulJSONParser) FilePatterns() []string {
+	return []string{"*coverage-final.json"}
+}
+
+// Detect reports whether the header contains a statementMap, which every file entry carries
+func (p *IstanbulJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// Sniff is confident when the file entries at the top of the document carry
+// a statementMap, fnMap or branchMap
+func (p *IstanbulJSONParser) Sniff(header []byte) Confidence {
+	doc := sniffJSON(header)
+	if doc.has("*", "statementMap") || doc.has("*", "fnMap") || doc.has("*", "branchMap") {
+		return ConfidenceHigh
+	}
+	return NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *IstanbulJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *IstanbulJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *IstanbulJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIstanbulJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/coverage-final.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewIstanbulJSONParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	math := report.Files["/home/user/app/src/math.ts"]
+	if math == nil {
+		t.Fatalf("Expected file '/home/user/app/src/math.ts' not found")
+	}
+
+	if math.TotalLines != 5 {
+		t.Errorf("Expected 5 total lines, got: %d", math.TotalLines)
+	}
+
+	if math.CoveredLines != 4 {
+		t.Errorf("Expected 4 covered lines, got: %d", math.CoveredLines)
+	}
+
+	if math.CoveragePct != 80.0 {
+		t.Errorf("Expected 80%% coverage, got: %.2f%%", math.CoveragePct)
+	}
+
+	if len(math.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got: %d", len(math.Functions))
+	}
+
+	if math.Functions[0].Name != "abs" || math.Functions[0].ExecutionCount != 3 || math.Functions[0].LineNumber != 1 {
+		t.Errorf("Unexpected first function: %+v", math.Functions[0])
+	}
+
+	if math.Functions[1].Name != "sub" || math.Functions[1].ExecutionCount != 0 {
+		t.Errorf("Unexpected second function: %+v", math.Functions[1])
+	}
+
+	if math.TotalBranches != 2 || math.CoveredBranches != 2 {
+		t.Errorf("Expected 2 total and 2 covered branches, got: %d, %d", math.TotalBranches, math.CoveredBranches)
+	}
+
+	if math.Branches[0].LineNumber != 2 || math.Branches[0].TakenCount != 2 {
+		t.Errorf("Unexpected first branch: %+v", math.Branches[0])
+	}
+}
+
+func TestIstanbulJSONParser_Parse_LineTakesHighestStatementCount(t *testing.T) {
+	input := `{"a.js":{"path":"a.js",
+"statementMap":{"0":{"start":{"line":1,"column":0},"end":{"line":1,"column":5}},"1":{"start":{"line":1,"column":6},"end":{"line":1,"column":9}}},
+"fnMap":{},"branchMap":{},"s":{"0":0,"1":4},"f":{},"b":{}}}`
+
+	parser := NewIstanbulJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["a.js"]
+	if file == nil {
+		t.Fatalf("Expected file 'a.js' not found")
+	}
+
+	if file.TotalLines != 1 || file.Lines[1].ExecutionCount != 4 {
+		t.Errorf("Expected a single line hit 4 times, got: %+v", file.Lines)
+	}
+}
+
+func TestIstanbulJSONParser_Parse_MissingPath(t *testing.T) {
+	input := `{"src/b.js":{"statementMap":{"0":{"start":{"line":3,"column":0},"end":{"line":3,"column":5}}},"s":{"0":1}}}`
+
+	parser := NewIstanbulJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.Files["src/b.js"] == nil {
+		t.Error("Expected the map key to be used as filename")
+	}
+}
+
+func TestIstanbulJSONParser_Parse_MismatchedCounts(t *testing.T) {
+	input := `{"c.js":{"path":"c.js",
+"statementMap":{"0":{"start":{"line":1,"column":0},"end":{"line":1,"column":5}}},
+"fnMap":{"0":{"name":"f","decl":{"start":{"line":1,"column":0},"end":{"line":1,"column":1}},"loc":{"start":{"line":1,"column":0},"end":{"line":2,"column":1}},"line":1}},
+"branchMap":{"0":{"loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":5}},"type":"cond-expr","locations":[{"start":{"line":1,"column":0},"end":{"line":1,"column":2}},{"start":{"line":1,"column":3},"end":{"line":1,"column":5}}],"line":1}},
+"s":{},"f":{},"b":{"0":[1]}}}`
+
+	parser := NewIstanbulJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(parser.GetWarnings()) != 3 {
+		t.Errorf("Expected 3 warnings, got: %d (%v)", len(parser.GetWarnings()), parser.GetWarnings())
+	}
+
+	file := report.Files["c.js"]
+	if file.TotalBranches != 2 || file.CoveredBranches != 1 {
+		t.Errorf("Expected 2 total and 1 covered branch, got: %d, %d", file.TotalBranches, file.CoveredBranches)
+	}
+}
+
+func TestIstanbulJSONParser_Parse_InvalidJSON(t *testing.T) {
+	parser := NewIstanbulJSONParser()
+	_, err := parser.Parse(strings.NewReader(`{"a.js": [`))
+
+	if err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestIstanbulJSONParser_Parse_Empty(t *testing.T) {
+	parser := NewIstanbulJSONParser()
+	report, err := parser.Parse(strings.NewReader(`{}`))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 0 {
+		t.Errorf("Expected 0 files, got: %d", len(report.Files))
+	}
+}
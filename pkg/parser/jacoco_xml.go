@@ -0,0 +1,363 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// JaCoCoXMLParser parses JaCoCo XML coverage reports (Java, Kotlin, Scala)
+type JaCoCoXMLParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewJaCoCoXMLParser creates a new JaCoCo XML coverage parser instance
+func NewJaCoCoXMLParser() *JaCoCoXMLParser {
+	return &JaCoCoXMLParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// JaCoCoReport represents the root <report> element
+type JaCoCoReport struct {
+	XMLName  xml.Name        `xml:"report"`
+	Name     string          `xml:"name,attr"`
+	Groups   []JaCoCoGroup   `xml:"group"`
+	Packages []JaCoCoPackage `xml:"package"`
+}
+
+// JaCoCoGroup represents a <group> element, groups may nest
+type JaCoCoGroup struct {
+	Name     string          `xml:"name,attr"`
+	Groups   []JaCoCoGroup   `xml:"group"`
+	Packages []JaCoCoPackage `xml:"package"`
+}
+
+// JaCoCoPackage represents a Java package
+type JaCoCoPackage struct {
+	Name        string             `xml:"name,attr"`
+	Classes     []JaCoCoClass      `xml:"class"`
+	SourceFiles []JaCoCoSourceFile `xml:"sourcefile"`
+}
+
+// JaCoCoClass represents a compiled class and its methods
+type JaCoCoClass struct {
+	Name           string         `xml:"name,attr"`
+	SourceFileName string         `xml:"sourcefilename,attr"`
+	Methods        []JaCoCoMethod `xml:"method"`
+}
+
+// JaCoCoMethod represents a method with its counters
+type JaCoCoMethod struct {
+	Name     string          `xml:"name,attr"`
+	Desc     string          `xml:"desc,attr"`
+	Line     int             `xml:"line,attr"`
+	Counters []JaCoCoCounter `xml:"counter"`
+}
+
+// JaCoCoSourceFile represents a source file with per-line data
+type JaCoCoSourceFile struct {
+	Name     string          `xml:"name,attr"`
+	Lines    []JaCoCoLine    `xml:"line"`
+	Counters []JaCoCoCounter `xml:"counter"`
+}
+
+// JaCoCoLine represents a source line: missed/covered instructions and branches
+type JaCoCoLine struct {
+	Nr int `xml:"nr,attr"`
+	MI int `xml:"mi,attr"`
+	CI int `xml:"ci,attr"`
+	MB int `xml:"mb,attr"`
+	CB int `xml:"cb,attr"`
+}
+
+// JaCoCoCounter represents a <counter> element
+type JaCoCoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+// Parse reads and parses a JaCoCo XML file. Source files and classes are
+// decoded one at a time, only the classes of the current package are held
+// until its source files have been read.
+func (p *JaCoCoXMLParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	pkgName := ""
+	var classes []JaCoCoClass
+	files := newJaCoCoFiles(report)
+
+	decoder := xml.NewDecoder(reader)
+	root, err := walkXML(decoder, "report", func(start *xml.StartElement) (bool, error) {
+		switch start.Name.Local {
+		case "group":
+			files.groups = append(files.groups, xmlAttr(start, "name"))
+		case "package":
+			pkgName = xmlAttr(start, "name")
+			classes = classes[:0]
+		case "class":
+			var class JaCoCoClass
+			if err := decoder.DecodeElement(&class, start); err != nil {
+				return true, err
+			}
+			classes = append(classes, class)
+			return true, nil
+		case "sourcefile":
+			var sourceFile JaCoCoSourceFile
+			if err := decoder.DecodeElement(&sourceFile, start); err != nil {
+				return true, err
+			}
+			files.add(p.parseSourceFile(pkgName, sourceFile))
+			return true, nil
+		}
+		return false, nil
+	}, func(name string) {
+		switch name {
+		case "group":
+			files.groups = files.groups[:len(files.groups)-1]
+		case "package":
+			// Classes precede the source files of their package, attach their
+			// methods once the package is complete
+			for _, class := range classes {
+				p.parseClass(pkgName, class, files)
+			}
+			pkgName = ""
+			classes = classes[:0]
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+	report.TestName = xmlAttr(&root, "name")
+
+	// Calculate coverage for all files
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseSourceFile maps the line and counter data of a <sourcefile> to a file coverage entry
+func (p *JaCoCoXMLParser) parseSourceFile(pkgName string, sourceFile JaCoCoSourceFile) *models.FileCoverage {
+	filename := path.Join(pkgName, sourceFile.Name)
+
+	file := &models.FileCoverage{
+		FileName:  filename,
+		Package:   strings.ReplaceAll(pkgName, "/", "."),
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+
+	for _, line := range sourceFile.Lines {
+		if line.MI < 0 || line.CI < 0 || line.MB < 0 || line.CB < 0 {
+			p.addWarning("line", CodeInvalidValue, fmt.Sprintf("file %s: line %d has negative counters", filename, line.Nr))
+			continue
+		}
+
+		// Lines without instructions are not executable
+		if line.MI+line.CI == 0 {
+			continue
+		}
+
+		// JaCoCo does not record hit counts, a line is covered once any instruction ran
+		execCount := 0
+		if line.CI > 0 {
+			execCount = 1
+		}
+		file.Lines[line.Nr] = models.LineCoverage{
+			LineNumber:     line.Nr,
+			ExecutionCount: execCount,
+		}
+
+		// Covered branches come first, then missed ones
+		for i := 0; i < line.CB+line.MB; i++ {
+			taken := 0
+			if i < line.CB {
+				taken = 1
+			}
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber: line.Nr,
+				BranchID:   strconv.Itoa(i),
+				TakenCount: taken,
+			})
+		}
+
+		file.TotalInstructions += line.MI + line.CI
+		file.CoveredInstructions += line.CI
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	// Prefer the file level counters when present, they are authoritative
+	for _, counter := range sourceFile.Counters {
+		switch counter.Type {
+		case "INSTRUCTION":
+			file.TotalInstructions = counter.Missed + counter.Covered
+			file.CoveredInstructions = counter.Covered
+		case "BRANCH":
+			if counter.Missed+counter.Covered != file.TotalBranches {
+				p.addWarning("counter", CodeSummaryMismatch, fmt.Sprintf("file %s: BRANCH counter (%d) doesn't match line branches (%d)", filename, counter.Missed+counter.Covered, file.TotalBranches))
+			}
+		}
+	}
+	return file
+}
+
+// parseClass adds the methods of a class to the file coverage of its source file
+func (p *JaCoCoXMLParser) parseClass(pkgName string, class JaCoCoClass, files *jacocoFiles) {
+	if class.SourceFileName == "" {
+		p.addWarning("class", CodeMissingData, fmt.Sprintf("class %s has no source file name", class.Name))
+		return
+	}
+
+	filename := path.Join(pkgName, class.SourceFileName)
+	file := files.get(filename)
+	if file == nil {
+		p.addWarning("class", CodeMissingData, fmt.Sprintf("class %s refers to unknown source file %s", class.Name, filename))
+		return
+	}
+
+	// Qualify method names with the simple class name, e.g. Foo$Bar.baz
+	className := class.Name[strings.LastIndex(class.Name, "/")+1:]
+
+	for _, method := range class.Methods {
+		execCount := 0
+		for _, counter := range method.Counters {
+			if counter.Type == "METHOD" && counter.Covered > 0 {
+				execCount = 1
+			}
+		}
+		file.Functions = append(file.Functions, models.FunctionCoverage{
+			Name:           className + "." + method.Name,
+			LineNumber:     method.Line,
+			ExecutionCount: execCount,
+		})
+	}
+}
+
+// jacocoFiles adds the files of a report by <group>. A multi-module report
+// has a group per module, and modules may hold a source file of the same
+// package without sharing its lines or branches. Such files are named after
+// their groups, e.g. api/com/example/Util.java, other files by their package.
+type jacocoFiles struct {
+	report *models.CoverageReport
+	// groups are the names of the enclosing <group> elements
+	groups []string
+	// byGroup maps a group path and a package file name to the file
+	byGroup map[[2]string]*models.FileCoverage
+	// owners maps a package file name to the group of the file named by it
+	owners map[string]string
+	// shared are the package file names of several groups
+	shared map[string]bool
+}
+
+func newJaCoCoFiles(report *models.CoverageReport) *jacocoFiles {
+	return &jacocoFiles{
+		report:  report,
+		byGroup: make(map[[2]string]*models.FileCoverage),
+		owners:  make(map[string]string),
+		shared:  make(map[string]bool),
+	}
+}
+
+// add adds a file of the current group, named by its package file name
+func (f *jacocoFiles) add(file *models.FileCoverage) {
+	group, name := path.Join(f.groups...), file.FileName
+	key := [2]string{group, name}
+	if existing := f.byGroup[key]; existing != nil {
+		existing.Merge(file)
+		return
+	}
+	f.byGroup[key] = file
+
+	if owner, ok := f.owners[name]; ok {
+		// The name is shared from now on, the first file is renamed too
+		first := f.report.Files[name]
+		delete(f.report.Files, name)
+		first.FileName = path.Join(owner, name)
+		f.report.AddFile(first)
+		delete(f.owners, name)
+		f.shared[name] = true
+	}
+	if f.shared[name] {
+		file.FileName = path.Join(group, name)
+	} else {
+		f.owners[name] = group
+	}
+	f.report.AddFile(file)
+}
+
+// get returns the file of the current group with a package file name
+func (f *jacocoFiles) get(name string) *models.FileCoverage {
+	return f.byGroup[[2]string{path.Join(f.groups...), name}]
+}
+
+// Name returns the canonical format name
+func (p *JaCoCoXMLParser) Name() string {
+	return "jacoco"
+}
+
+// Description returns a human readable format name
+func (p *JaCoCoXMLParser) Description() string {
+	return "JaCoCo XML Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "jacoco"
+func (p *JaCoCoXMLParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names JaCoCo reports are usually written to
+func (p *JaCoCoXMLParser) FilePatterns() []string {
+	return []string{"*jacoco*.xml"}
+}
+
+// Sniff is certain when a <report> root comes with the JaCoCo DOCTYPE or
+// session info. A named <report> root alone is a common element name.
+func (p *JaCoCoXMLParser) Sniff(header []byte) Confidence {
+	doc := sniffXML(header)
+	switch {
+	case !doc.rootIs("report"):
+		return NoMatch
+	case strings.Contains(doc.Doctype, "//JACOCO//DTD") || doc.hasChild("sessioninfo"):
+		return ConfidenceCertain
+	case doc.hasAttr("name"):
+		return ConfidenceMedium
+	}
+	return ConfidenceLow
+}
+
+// Detect reports whether the document has a <report> root
+func (p *JaCoCoXMLParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *JaCoCoXMLParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *JaCoCoXMLParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *JaCoCoXMLParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJaCoCoXMLParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/jacoco.xml")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewJaCoCoXMLParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.TestName != "orders-service" {
+		t.Errorf("Expected test name 'orders-service', got: %s", report.TestName)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	orders := report.Files["com/example/orders/OrderService.java"]
+	if orders == nil {
+		t.Fatalf("Expected file 'com/example/orders/OrderService.java' not found")
+	}
+
+	if orders.TotalLines != 5 || orders.CoveredLines != 3 {
+		t.Errorf("Expected 5 total and 3 covered lines, got: %d, %d", orders.TotalLines, orders.CoveredLines)
+	}
+
+	if orders.TotalBranches != 2 || orders.CoveredBranches != 1 {
+		t.Errorf("Expected 2 total and 1 covered branch, got: %d, %d", orders.TotalBranches, orders.CoveredBranches)
+	}
+
+	if orders.TotalInstructions != 17 || orders.CoveredInstructions != 11 {
+		t.Errorf("Expected 17 total and 11 covered instructions, got: %d, %d", orders.TotalInstructions, orders.CoveredInstructions)
+	}
+
+	if len(orders.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got: %d", len(orders.Functions))
+	}
+
+	if orders.Functions[0].Name != "OrderService.<init>" || orders.Functions[0].ExecutionCount != 1 {
+		t.Errorf("Unexpected first function: %+v", orders.Functions[0])
+	}
+
+	if orders.Functions[2].Name != "OrderService.cancel" || orders.Functions[2].ExecutionCount != 0 || orders.Functions[2].LineNumber != 14 {
+		t.Errorf("Unexpected third function: %+v", orders.Functions[2])
+	}
+
+	// Packages nested in groups are parsed as well
+	invoice := report.Files["com/example/billing/Invoice.kt"]
+	if invoice == nil {
+		t.Fatalf("Expected file 'com/example/billing/Invoice.kt' not found")
+	}
+
+	// Line 4 has no instructions and is not executable
+	if invoice.TotalLines != 1 || invoice.CoveragePct != 100.0 {
+		t.Errorf("Expected 1 fully covered line, got: %d lines at %.2f%%", invoice.TotalLines, invoice.CoveragePct)
+	}
+}
+
+func TestJaCoCoXMLParser_Parse_Warnings(t *testing.T) {
+	input := `<report name="r">
+  <package name="p">
+    <class name="p/Orphan" sourcefilename="Missing.java"/>
+    <class name="p/NoSource"/>
+    <sourcefile name="A.java">
+      <line nr="1" mi="-1" ci="0" mb="0" cb="0"/>
+      <line nr="2" mi="0" ci="1" mb="0" cb="2"/>
+      <counter type="BRANCH" missed="1" covered="2"/>
+    </sourcefile>
+  </package>
+</report>`
+
+	parser := NewJaCoCoXMLParser()
+	report, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(parser.GetWarnings()) != 4 {
+		t.Errorf("Expected 4 warnings, got: %d (%v)", len(parser.GetWarnings()), parser.GetWarnings())
+	}
+
+	file := report.Files["p/A.java"]
+	if file == nil || file.TotalLines != 1 || file.CoveredBranches != 2 {
+		t.Errorf("Unexpected file coverage: %+v", file)
+	}
+}
+
+func TestJaCoCoXMLParser_Parse_SharedSourceFile(t *testing.T) {
+	// Two modules hold a source file of the same package and name
+	input := `<report name="r">
+  <group name="api">
+    <package name="com/example/shared">
+      <class name="com/example/shared/Util" sourcefilename="Util.java">
+        <method name="a" line="1"><counter type="METHOD" missed="0" covered="1"/></method>
+        <method name="b" line="3"><counter type="METHOD" missed="1" covered="0"/></method>
+      </class>
+      <sourcefile name="Util.java">
+        <line nr="1" mi="0" ci="2" mb="1" cb="1"/>
+        <line nr="3" mi="2" ci="0" mb="0" cb="0"/>
+        <counter type="INSTRUCTION" missed="2" covered="2"/>
+      </sourcefile>
+    </package>
+  </group>
+  <group name="worker">
+    <package name="com/example/shared">
+      <class name="com/example/shared/Util" sourcefilename="Util.java">
+        <method name="a" line="1"><counter type="METHOD" missed="1" covered="0"/></method>
+        <method name="b" line="3"><counter type="METHOD" missed="0" covered="1"/></method>
+      </class>
+      <sourcefile name="Util.java">
+        <line nr="1" mi="2" ci="0" mb="2" cb="0"/>
+        <line nr="3" mi="0" ci="2" mb="0" cb="0"/>
+        <counter type="INSTRUCTION" missed="2" covered="2"/>
+      </sourcefile>
+    </package>
+    <package name="com/example/worker">
+      <sourcefile name="Main.java">
+        <line nr="1" mi="0" ci="1" mb="0" cb="0"/>
+      </sourcefile>
+    </package>
+  </group>
+</report>`
+
+	parser := NewJaCoCoXMLParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 3 || report.Files["com/example/worker/Main.java"] == nil {
+		t.Fatalf("Expected the shared file once per group and Main.java by its package, got: %v", report.Files)
+	}
+	tests := []struct {
+		name            string
+		coveredLine     int
+		coveredBranches int
+		coveredMethod   string
+	}{
+		{"api/com/example/shared/Util.java", 1, 1, "Util.a"},
+		{"worker/com/example/shared/Util.java", 3, 0, "Util.b"},
+	}
+	for _, tt := range tests {
+		file := report.Files[tt.name]
+		if file == nil {
+			t.Fatalf("Expected %s, got: %v", tt.name, report.Files)
+		}
+		if file.FileName != tt.name || file.TotalLines != 2 || file.CoveredLines != 1 || file.Lines[tt.coveredLine].ExecutionCount != 1 {
+			t.Errorf("%s: expected line %d alone covered, got: %+v", tt.name, tt.coveredLine, file.Lines)
+		}
+		if file.TotalBranches != 2 || file.CoveredBranches != tt.coveredBranches {
+			t.Errorf("%s: expected %d of 2 branches covered, got: %d of %d", tt.name, tt.coveredBranches, file.CoveredBranches, file.TotalBranches)
+		}
+		for _, fn := range file.Functions {
+			if (fn.ExecutionCount == 1) != (fn.Name == tt.coveredMethod) {
+				t.Errorf("%s: expected %s alone covered, got: %+v", tt.name, tt.coveredMethod, file.Functions)
+			}
+		}
+		if len(file.Functions) != 2 {
+			t.Errorf("%s: expected 2 methods, got: %+v", tt.name, file.Functions)
+		}
+	}
+}
+
+func TestJaCoCoXMLParser_Parse_InvalidXML(t *testing.T) {
+	parser := NewJaCoCoXMLParser()
+	_, err := parser.Parse(strings.NewReader(`<report><package`))
+
+	if err == nil {
+		t.Error("Expected error for invalid XML")
+	}
+}
+
+func TestJaCoCoXMLParser_Parse_WrongRoot(t *testing.T) {
+	parser := NewJaCoCoXMLParser()
+	_, err := parser.Parse(strings.NewReader(`<coverage></coverage>`))
+
+	if err == nil {
+		t.Error("Expected error for non-JaCoCo root element")
+	}
+}
@@ -2,9 +2,10 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
-	"log"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,24 +14,33 @@ import (
 
 // LCOVParser parses LCOV format coverage files
 type LCOVParser struct {
-	warnings []string
+	diagnostics []Diagnostic
+	// functions maps the function names of the current source file to their
+	// index in Functions, so FNDA records don't search the whole list
+	functions map[string]int
 }
 
 // NewLCOVParser creates a new LCOV parser instance
 func NewLCOVParser() *LCOVParser {
 	return &LCOVParser{
-		warnings: make([]string, 0),
+		diagnostics: make([]Diagnostic, 0),
 	}
 }
 
 // Parse reads and parses an LCOV format coverage file
 func (p *LCOVParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
 	report := models.NewCoverageReport()
 	scanner := bufio.NewScanner(reader)
 
 	var currentFile *models.FileCoverage
 	lineNumber := 0
 
+	// testName is the TN: of the current section, testNames the distinct
+	// names of all sections in order
+	testName := ""
+	var testNames []string
+
 	for scanner.Scan() {
 		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
@@ -43,8 +53,11 @@ func (p *LCOVParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
 		// Parse different LCOV record types
 		switch {
 		case strings.HasPrefix(line, "TN:"):
-			// Test name
-			report.TestName = strings.TrimPrefix(line, "TN:")
+			// Test name, naming the test that ran the source files up to the next TN: record
+			testName = strings.TrimPrefix(line, "TN:")
+			if testName != "" && !slices.Contains(testNames, testName) {
+				testNames = append(testNames, testName)
+			}
 
 		case strings.HasPrefix(line, "SF:"):
 			// Source file
@@ -53,26 +66,28 @@ func (p *LCOVParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
 				FileName:  filename,
 				Functions: make([]models.FunctionCoverage, 0),
 				Lines:     make(map[int]models.LineCoverage),
+				Branches:  make([]models.BranchCoverage, 0),
 			}
+			p.functions = make(map[string]int)
 
 		case strings.HasPrefix(line, "FN:"):
 			// Function definition: FN:<line>,<function name>
 			if currentFile == nil {
-				p.addWarning(lineNumber, "FN record without active source file")
+				p.addWarning(lineNumber, "FN", CodeOrphanRecord, "FN record without active source file")
 				continue
 			}
 			if err := p.parseFN(line, currentFile, lineNumber); err != nil {
-				p.addWarning(lineNumber, err.Error())
+				p.addWarning(lineNumber, "FN", CodeInvalidRecord, err.Error())
 			}
 
 		case strings.HasPrefix(line, "FNDA:"):
 			// Function data: FNDA:<execution count>,<function name>
 			if currentFile == nil {
-				p.addWarning(lineNumber, "FNDA record without active source file")
+				p.addWarning(lineNumber, "FNDA", CodeOrphanRecord, "FNDA record without active source file")
 				continue
 			}
 			if err := p.parseFNDA(line, currentFile, lineNumber); err != nil {
-				p.addWarning(lineNumber, err.Error())
+				p.addWarning(lineNumber, "FNDA", CodeInvalidRecord, err.Error())
 			}
 
 		case strings.HasPrefix(line, "FNF:"):
@@ -88,56 +103,86 @@ func (p *LCOVParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
 		case strings.HasPrefix(line, "DA:"):
 			// Line data: DA:<line number>,<execution count>[,<checksum>]
 			if currentFile == nil {
-				p.addWarning(lineNumber, "DA record without active source file")
+				p.addWarning(lineNumber, "DA", CodeOrphanRecord, "DA record without active source file")
 				continue
 			}
 			if err := p.parseDA(line, currentFile, lineNumber); err != nil {
-				p.addWarning(lineNumber, err.Error())
+				p.addWarning(lineNumber, "DA", CodeInvalidRecord, err.Error())
 			}
 
 		case strings.HasPrefix(line, "LH:"):
 			// Lines hit: LH:<number of lines with non-zero execution count>
 			if currentFile == nil {
-				p.addWarning(lineNumber, "LH record without active source file")
+				p.addWarning(lineNumber, "LH", CodeOrphanRecord, "LH record without active source file")
 				continue
 			}
 			if err := p.parseLH(line, currentFile, lineNumber); err != nil {
-				p.addWarning(lineNumber, err.Error())
+				p.addWarning(lineNumber, "LH", CodeInvalidRecord, err.Error())
 			}
 
 		case strings.HasPrefix(line, "LF:"):
 			// Lines found: LF:<number of instrumented lines>
 			if currentFile == nil {
-				p.addWarning(lineNumber, "LF record without active source file")
+				p.addWarning(lineNumber, "LF", CodeOrphanRecord, "LF record without active source file")
 				continue
 			}
 			if err := p.parseLF(line, currentFile, lineNumber); err != nil {
-				p.addWarning(lineNumber, err.Error())
+				p.addWarning(lineNumber, "LF", CodeInvalidRecord, err.Error())
 			}
 
 		case strings.HasPrefix(line, "BRF:"):
-			// Branches found - branch coverage (optional)
-			continue
+			// Branches found: BRF:<number of branches found>
+			if currentFile == nil {
+				p.addWarning(lineNumber, "BRF", CodeOrphanRecord, "BRF record without active source file")
+				continue
+			}
+			if err := p.parseBRF(line, currentFile, lineNumber); err != nil {
+				p.addWarning(lineNumber, "BRF", CodeInvalidRecord, err.Error())
+			}
 
 		case strings.HasPrefix(line, "BRH:"):
-			// Branches hit - branch coverage (optional)
-			continue
+			// Branches hit: BRH:<number of branches taken>
+			if currentFile == nil {
+				p.addWarning(lineNumber, "BRH", CodeOrphanRecord, "BRH record without active source file")
+				continue
+			}
+			if err := p.parseBRH(line, currentFile, lineNumber); err != nil {
+				p.addWarning(lineNumber, "BRH", CodeInvalidRecord, err.Error())
+			}
 
 		case strings.HasPrefix(line, "BRDA:"):
-			// Branch data - branch coverage (optional)
-			continue
+			// Branch data: BRDA:<line number>,<block number>,<branch number>,<taken>
+			if currentFile == nil {
+				p.addWarning(lineNumber, "BRDA", CodeOrphanRecord, "BRDA record without active source file")
+				continue
+			}
+			if err := p.parseBRDA(line, currentFile, lineNumber); err != nil {
+				p.addWarning(lineNumber, "BRDA", CodeInvalidRecord, err.Error())
+			}
 
 		case line == "end_of_record":
 			// End of current file record
 			if currentFile != nil {
+				// Derive branch totals from BRDA records when BRF/BRH are absent
+				if currentFile.TotalBranches == 0 && len(currentFile.Branches) > 0 {
+					currentFile.CountBranches()
+				}
 				currentFile.CalculateCoverage()
-				report.AddFile(currentFile)
+				if testName != "" {
+					currentFile.AttributeTo(testName)
+				}
+				// A file run by several tests has a section per test
+				if existing := report.GetFile(currentFile.FileName); existing != nil {
+					existing.Merge(currentFile)
+				} else {
+					report.AddFile(currentFile)
+				}
 				currentFile = nil
 			}
 
 		default:
 			// Unknown record type - log warning but continue
-			p.addWarning(lineNumber, fmt.Sprintf("unknown record type: %s", line))
+			p.addWarning(lineNumber, "", CodeUnknownRecord, fmt.Sprintf("unknown record type: %s", line))
 		}
 	}
 
@@ -145,11 +190,7 @@ func (p *LCOVParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
 		return nil, fmt.Errorf("error reading coverage file: %w", err)
 	}
 
-	// Log all warnings
-	for _, warning := range p.warnings {
-		log.Println(warning)
-	}
-
+	report.TestName = strings.Join(testNames, ", ")
 	return report, nil
 }
 
@@ -167,6 +208,9 @@ func (p *LCOVParser) parseFN(line string, file *models.FileCoverage, lineNum int
 	}
 
 	functionName := parts[1]
+	if _, exists := p.functions[functionName]; !exists {
+		p.functions[functionName] = len(file.Functions)
+	}
 	file.Functions = append(file.Functions, models.FunctionCoverage{
 		Name:       functionName,
 		LineNumber: lineNumber,
@@ -191,11 +235,8 @@ func (p *LCOVParser) parseFNDA(line string, file *models.FileCoverage, lineNum i
 	functionName := parts[1]
 
 	// Find the matching function and update execution count
-	for i := range file.Functions {
-		if file.Functions[i].Name == functionName {
-			file.Functions[i].ExecutionCount = execCount
-			break
-		}
+	if i, exists := p.functions[functionName]; exists {
+		file.Functions[i].ExecutionCount = execCount
 	}
 
 	return nil
@@ -257,12 +298,168 @@ func (p *LCOVParser) parseLF(line string, file *models.FileCoverage, lineNum int
 	return nil
 }
 
-// addWarning adds a warning message to the parser
-func (p *LCOVParser) addWarning(lineNum int, message string) {
-	p.warnings = append(p.warnings, fmt.Sprintf("line %d: %s", lineNum, message))
+// parseBRDA parses branch data: BRDA:<line number>,<block number>,<branch number>,<taken>
+// The taken count is "-" when the block containing the branch was never executed.
+func (p *LCOVParser) parseBRDA(line string, file *models.FileCoverage, lineNum int) error {
+	data := strings.TrimPrefix(line, "BRDA:")
+	parts := strings.Split(data, ",")
+	if len(parts) < 4 {
+		return fmt.Errorf("invalid BRDA format: %s", line)
+	}
+
+	lineNumber, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid line number in BRDA: %s", parts[0])
+	}
+
+	// lcov 2.x marks exception branches with an "e" prefix on the block number
+	blockNumber, err := strconv.Atoi(strings.TrimPrefix(parts[1], "e"))
+	if err != nil {
+		return fmt.Errorf("invalid block number in BRDA: %s", parts[1])
+	}
+
+	// The branch identifier may itself contain commas (lcov 2.x expressions)
+	branchID := strings.Join(parts[2:len(parts)-1], ",")
+	taken := parts[len(parts)-1]
+
+	branch := models.BranchCoverage{
+		LineNumber:  lineNumber,
+		BlockNumber: blockNumber,
+		BranchID:    branchID,
+	}
+	if taken == "-" {
+		branch.NotExecuted = true
+	} else {
+		count, err := strconv.Atoi(taken)
+		if err != nil {
+			return fmt.Errorf("invalid taken count in BRDA: %s", taken)
+		}
+		branch.TakenCount = count
+	}
+
+	file.Branches = append(file.Branches, branch)
+	return nil
+}
+
+// parseBRF parses branches found: BRF:<number>
+func (p *LCOVParser) parseBRF(line string, file *models.FileCoverage, lineNum int) error {
+	data := strings.TrimPrefix(line, "BRF:")
+	count, err := strconv.Atoi(data)
+	if err != nil {
+		return fmt.Errorf("invalid BRF value: %s", data)
+	}
+
+	file.TotalBranches = count
+	return nil
+}
+
+// parseBRH parses branches hit: BRH:<number>
+func (p *LCOVParser) parseBRH(line string, file *models.FileCoverage, lineNum int) error {
+	data := strings.TrimPrefix(line, "BRH:")
+	count, err := strconv.Atoi(data)
+	if err != nil {
+		return fmt.Errorf("invalid BRH value: %s", data)
+	}
+
+	file.CoveredBranches = count
+	return nil
+}
+
+// Name returns the canonical format name
+func (p *LCOVParser) Name() string {
+	return "lcov"
+}
+
+// Description returns a human readable format name
+func (p *LCOVParser) Description() string {
+	return "LCOV"
+}
+
+// Aliases returns the language names that map to LCOV
+func (p *LCOVParser) Aliases() []string {
+	return []string{"rust", "ts"}
+}
+
+// FilePatterns returns the file names LCOV reports are usually written to
+func (p *LCOVParser) FilePatterns() []string {
+	return []string{"*.lcov", "*.info", "*lcov.info*"}
+}
+
+// lcovRecordPrefixes start the records of an LCOV tracefile
+var lcovRecordPrefixes = []string{
+	"TN:", "SF:", "VER:", "FN:", "FNDA:", "FNF:", "FNH:", "FNL:", "FNA:",
+	"BRDA:", "BRF:", "BRH:", "DA:", "LF:", "LH:",
+}
+
+// isLCOVRecord reports whether a trimmed line is an LCOV record
+func isLCOVRecord(line string) bool {
+	if line == "end_of_record" {
+		return true
+	}
+	for _, prefix := range lcovRecordPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sniff is certain when every line of the header is an LCOV record and a
+// source file is named. Other lines in between lower the confidence.
+func (p *LCOVParser) Sniff(header []byte) Confidence {
+	lines := headerLines(header)
+	// The last line may be cut off
+	if len(lines) > 1 && !bytes.HasSuffix(header, []byte("\n")) {
+		lines = lines[:len(lines)-1]
+	}
+
+	records, others, sourceFiles := 0, 0, 0
+	for _, line := range lines {
+		switch {
+		case line == "":
+		case isLCOVRecord(line):
+			records++
+			if strings.HasPrefix(line, "SF:") {
+				sourceFiles++
+			}
+		default:
+			others++
+		}
+	}
+
+	switch {
+	case records == 0:
+		return NoMatch
+	case others > 0:
+		return ConfidenceLow
+	case sourceFiles == 0:
+		return ConfidenceHigh
+	}
+	return ConfidenceCertain
+}
+
+// Detect reports whether the header contains LCOV records
+func (p *LCOVParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about a record to the parser
+func (p *LCOVParser) addWarning(lineNum int, record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Line:     lineNum,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *LCOVParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
 }
 
 // GetWarnings returns all warnings collected during parsing
 func (p *LCOVParser) GetWarnings() []string {
-	return p.warnings
+	return diagnosticStrings(p.diagnostics)
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -112,6 +113,74 @@ end_of_record
 	}
 }
 
+func TestLCOVParser_Parse_TestSections(t *testing.T) {
+	// geninfo writes a section per test when several tests ran a file
+	input := `TN:unit
+SF:src/lib.rs
+FN:1,run
+FNDA:1,run
+DA:1,1
+DA:2,1
+DA:3,0
+LF:3
+LH:2
+end_of_record
+TN:e2e
+SF:src/lib.rs
+FN:1,run
+FNDA:2,run
+DA:1,2
+DA:2,0
+DA:3,1
+LF:3
+LH:2
+end_of_record
+SF:src/main.rs
+DA:1,0
+LF:1
+LH:0
+end_of_record
+TN:
+SF:src/util.rs
+DA:1,1
+LF:1
+LH:1
+end_of_record
+`
+
+	report, err := NewLCOVParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.TestName != "unit, e2e" {
+		t.Errorf("Expected test name 'unit, e2e', got: %s", report.TestName)
+	}
+
+	// The sections of a file are merged, not overwritten
+	lib := report.Files["src/lib.rs"]
+	if lib.TotalLines != 3 || lib.CoveredLines != 3 {
+		t.Errorf("Expected 3 of 3 lines covered, got %d of %d", lib.CoveredLines, lib.TotalLines)
+	}
+	expected := map[int][]string{1: {"unit", "e2e"}, 2: {"unit"}, 3: {"e2e"}}
+	for lineNum, tests := range expected {
+		if !reflect.DeepEqual(lib.Lines[lineNum].Tests, tests) {
+			t.Errorf("Expected line %d to be run by %v, got: %v", lineNum, tests, lib.Lines[lineNum].Tests)
+		}
+	}
+	if fn := lib.Functions[0]; len(lib.Functions) != 1 || fn.ExecutionCount != 3 || !reflect.DeepEqual(fn.Tests, []string{"unit", "e2e"}) {
+		t.Errorf("Expected run to be called 3 times by unit and e2e, got: %+v", lib.Functions)
+	}
+
+	if tests := report.Files["src/main.rs"].Lines[1].Tests; len(tests) != 0 {
+		t.Errorf("Expected a missed line to have no tests, got: %v", tests)
+	}
+	// An empty TN: ends the previous test's section
+	if tests := report.Files["src/util.rs"].Lines[1].Tests; len(tests) != 0 {
+		t.Errorf("Expected a line outside of a named test to have no tests, got: %v", tests)
+	}
+}
+
 func TestLCOVParser_Parse_MalformedLines(t *testing.T) {
 	input := `TN:test
 SF:file.rs
@@ -232,11 +301,97 @@ end_of_record
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	// Branch coverage records should be gracefully skipped
 	file := report.Files["file.rs"]
 	if file == nil {
 		t.Fatalf("Expected file 'file.rs' not found")
 	}
+
+	// BRF/BRH take precedence over the BRDA records
+	if file.TotalBranches != 10 {
+		t.Errorf("Expected 10 total branches, got: %d", file.TotalBranches)
+	}
+
+	if file.CoveredBranches != 7 {
+		t.Errorf("Expected 7 covered branches, got: %d", file.CoveredBranches)
+	}
+
+	if file.BranchPct != 70.0 {
+		t.Errorf("Expected branch coverage 70%%, got: %.2f%%", file.BranchPct)
+	}
+
+	if len(file.Branches) != 2 {
+		t.Fatalf("Expected 2 branches, got: %d", len(file.Branches))
+	}
+
+	if file.Branches[0].TakenCount != 5 || file.Branches[1].BranchID != "1" {
+		t.Errorf("Unexpected branch data: %+v", file.Branches)
+	}
+}
+
+func TestLCOVParser_Parse_BranchesWithoutSummary(t *testing.T) {
+	input := `SF:file.ts
+BRDA:3,0,0,2
+BRDA:3,0,1,0
+BRDA:7,1,0,-
+BRDA:7,1,1,-
+DA:3,2
+LH:1
+LF:1
+end_of_record
+`
+
+	parser := NewLCOVParser()
+	report, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["file.ts"]
+	if file == nil {
+		t.Fatalf("Expected file 'file.ts' not found")
+	}
+
+	if file.TotalBranches != 4 {
+		t.Errorf("Expected 4 total branches, got: %d", file.TotalBranches)
+	}
+
+	if file.CoveredBranches != 1 {
+		t.Errorf("Expected 1 covered branch, got: %d", file.CoveredBranches)
+	}
+
+	if file.BranchPct != 25.0 {
+		t.Errorf("Expected branch coverage 25%%, got: %.2f%%", file.BranchPct)
+	}
+
+	if !file.Branches[2].NotExecuted || file.Branches[2].BlockNumber != 1 {
+		t.Errorf("Expected branch on line 7 to be not executed in block 1, got: %+v", file.Branches[2])
+	}
+}
+
+func TestLCOVParser_Parse_InvalidBranchRecords(t *testing.T) {
+	input := `SF:file.rs
+BRDA:1,0
+BRDA:x,0,0,1
+BRDA:1,0,0,many
+BRF:abc
+BRH:abc
+DA:1,1
+end_of_record
+BRDA:1,0,0,1
+`
+
+	parser := NewLCOVParser()
+	_, err := parser.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	warnings := parser.GetWarnings()
+	if len(warnings) != 6 {
+		t.Errorf("Expected 6 warnings for invalid branch records, got: %d (%v)", len(warnings), warnings)
+	}
 }
 
 func TestLCOVParser_Parse_UnknownRecordType(t *testing.T) {
@@ -420,8 +575,17 @@ end_of_record
 		t.Fatalf("Expected file 'src/lib.rs' not found")
 	}
 
-	// Branch coverage is parsed but not used in coverage calculation for now
-	// Just check that parsing doesn't fail
+	if file.TotalBranches != 2 || file.CoveredBranches != 1 {
+		t.Errorf("Expected 2 total and 1 covered branch, got: %d, %d", file.TotalBranches, file.CoveredBranches)
+	}
+
+	if !file.Branches[0].NotExecuted {
+		t.Error("Expected first branch to be marked as not executed")
+	}
+
+	if file.Branches[1].TakenCount != 3 {
+		t.Errorf("Expected second branch taken 3 times, got: %d", file.Branches[1].TakenCount)
+	}
 }
 
 func TestLCOVParser_Parse_EmptyInput(t *testing.T) {
@@ -477,3 +641,62 @@ end_of_record
 		t.Error("Expected warning for malformed line")
 	}
 }
+
+func TestLCOVParser_Diagnostics(t *testing.T) {
+	input := `DA:1,1
+SF:src/lib.rs
+DA:5,10
+DA:invalid_line_here
+XX:unknown
+end_of_record
+`
+
+	parser := NewLCOVParser()
+	if _, err := parser.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	diagnostics := parser.Diagnostics()
+	if len(diagnostics) != 3 {
+		t.Fatalf("Expected 3 diagnostics, got: %d (%v)", len(diagnostics), diagnostics)
+	}
+
+	expected := []struct {
+		line   int
+		record string
+		code   string
+	}{
+		{1, "DA", CodeOrphanRecord},
+		{4, "DA", CodeInvalidRecord},
+		{5, "", CodeUnknownRecord},
+	}
+	for i, want := range expected {
+		d := diagnostics[i]
+		if d.Severity != SeverityWarning || d.Line != want.line || d.Record != want.record || d.Code != want.code {
+			t.Errorf("Diagnostic %d: expected line %d, record %q, code %s, got: %+v", i, want.line, want.record, want.code, d)
+		}
+	}
+
+	// GetWarnings keeps the old line prefixed messages
+	if warnings := parser.GetWarnings(); !strings.HasPrefix(warnings[1], "line 4: ") {
+		t.Errorf("Expected line prefix in warning, got: %s", warnings[1])
+	}
+}
+
+func TestLCOVParser_Diagnostics_ResetByParse(t *testing.T) {
+	parser := NewLCOVParser()
+	if _, err := parser.Parse(strings.NewReader("SF:src/lib.rs\nDA:invalid\nend_of_record\n")); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.Diagnostics()) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got: %v", parser.Diagnostics())
+	}
+
+	// A clean file parsed next reports none of the first file's problems
+	if _, err := parser.Parse(strings.NewReader("SF:src/lib.rs\nDA:1,1\nend_of_record\n")); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if diagnostics := parser.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got: %v", diagnostics)
+	}
+}
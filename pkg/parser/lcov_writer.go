@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// LCOVWriter writes coverage reports in LCOV tracefile format
+type LCOVWriter struct{}
+
+// NewLCOVWriter creates a new LCOV writer instance
+func NewLCOVWriter() *LCOVWriter {
+	return &LCOVWriter{}
+}
+
+// Name returns the canonical format name
+func (w *LCOVWriter) Name() string {
+	return "lcov"
+}
+
+// Write writes one record per file, sorted by file name, in the order geninfo uses:
+// functions, branches, then lines
+func (w *LCOVWriter) Write(writer io.Writer, report *models.CoverageReport) error {
+	out := bufio.NewWriter(writer)
+
+	for _, name := range sortedFileNames(report) {
+		file := report.Files[name]
+
+		fmt.Fprintf(out, "TN:%s\n", report.TestName)
+		fmt.Fprintf(out, "SF:%s\n", file.FileName)
+
+		// Functions
+		functionsHit := 0
+		for _, fn := range file.Functions {
+			fmt.Fprintf(out, "FN:%d,%s\n", fn.LineNumber, fn.Name)
+		}
+		for _, fn := range file.Functions {
+			fmt.Fprintf(out, "FNDA:%d,%s\n", fn.ExecutionCount, fn.Name)
+			if fn.ExecutionCount > 0 {
+				functionsHit++
+			}
+		}
+		if len(file.Functions) > 0 {
+			fmt.Fprintf(out, "FNF:%d\n", len(file.Functions))
+			fmt.Fprintf(out, "FNH:%d\n", functionsHit)
+		}
+
+		// Branches
+		for _, b := range file.Branches {
+			taken := "-"
+			if !b.NotExecuted {
+				taken = strconv.Itoa(b.TakenCount)
+			}
+			fmt.Fprintf(out, "BRDA:%d,%d,%s,%s\n", b.LineNumber, b.BlockNumber, b.BranchID, taken)
+		}
+		if file.TotalBranches > 0 {
+			fmt.Fprintf(out, "BRF:%d\n", file.TotalBranches)
+			fmt.Fprintf(out, "BRH:%d\n", file.CoveredBranches)
+		}
+
+		// Lines
+		for _, lineNum := range sortedLineNumbers(file) {
+			line := file.Lines[lineNum]
+			if line.Checksum != "" {
+				fmt.Fprintf(out, "DA:%d,%d,%s\n", lineNum, line.ExecutionCount, line.Checksum)
+			} else {
+				fmt.Fprintf(out, "DA:%d,%d\n", lineNum, line.ExecutionCount)
+			}
+		}
+		fmt.Fprintf(out, "LF:%d\n", file.TotalLines)
+		fmt.Fprintf(out, "LH:%d\n", file.CoveredLines)
+		fmt.Fprintln(out, "end_of_record")
+	}
+
+	return out.Flush()
+}
+
+// sortedFileNames returns the file names of a report in lexical order
+func sortedFileNames(report *models.CoverageReport) []string {
+	names := make([]string, 0, len(report.Files))
+	for name := range report.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedLineNumbers returns the line numbers of a file in ascending order
+func sortedLineNumbers(file *models.FileCoverage) []int {
+	lineNums := make([]int, 0, len(file.Lines))
+	for lineNum := range file.Lines {
+		lineNums = append(lineNums, lineNum)
+	}
+	sort.Ints(lineNums)
+	return lineNums
+}
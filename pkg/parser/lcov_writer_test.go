@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+func TestLCOVWriter_Write(t *testing.T) {
+	report := models.NewCoverageReport()
+	report.TestName = "unit"
+	file := &models.FileCoverage{
+		FileName: "src/lib.rs",
+		Functions: []models.FunctionCoverage{
+			{Name: "add", LineNumber: 1, ExecutionCount: 2},
+			{Name: "sub", LineNumber: 5, ExecutionCount: 0},
+		},
+		Lines: map[int]models.LineCoverage{
+			6: {LineNumber: 6, ExecutionCount: 0},
+			2: {LineNumber: 2, ExecutionCount: 2},
+		},
+		Branches: []models.BranchCoverage{
+			{LineNumber: 2, BlockNumber: 0, BranchID: "0", TakenCount: 1},
+			{LineNumber: 2, BlockNumber: 0, BranchID: "1", NotExecuted: true},
+		},
+	}
+	file.CountLines()
+	file.CountBranches()
+	report.AddFile(file)
+
+	var buf bytes.Buffer
+	if err := NewLCOVWriter().Write(&buf, report); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := `TN:unit
+SF:src/lib.rs
+FN:1,add
+FN:5,sub
+FNDA:2,add
+FNDA:0,sub
+FNF:2
+FNH:1
+BRDA:2,0,0,1
+BRDA:2,0,1,-
+BRF:2
+BRH:1
+DA:2,2
+DA:6,0
+LF:2
+LH:1
+end_of_record
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestLCOVWriter_RoundTrip(t *testing.T) {
+	input := `TN:
+SF:b.ts
+DA:1,1
+DA:2,0
+LF:2
+LH:1
+end_of_record
+SF:a.ts
+FN:3,run
+FNDA:4,run
+FNF:1
+FNH:1
+DA:3,4
+LF:1
+LH:1
+end_of_record
+`
+	report, err := NewLCOVParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewLCOVWriter().Write(&buf, report); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Records come out sorted by file name
+	if strings.Index(buf.String(), "SF:a.ts") > strings.Index(buf.String(), "SF:b.ts") {
+		t.Errorf("Expected a.ts before b.ts, got:\n%s", buf.String())
+	}
+
+	reparsed, err := NewLCOVParser().Parse(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for name, file := range report.Files {
+		again := reparsed.GetFile(name)
+		if again == nil {
+			t.Fatalf("Expected file %s after round trip", name)
+		}
+		if again.TotalLines != file.TotalLines || again.CoveredLines != file.CoveredLines || len(again.Functions) != len(file.Functions) {
+			t.Errorf("File %s changed in round trip: %+v vs %+v", name, again, file)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// llvmExportType is the value of the "type" key of every llvm-cov export
+const llvmExportType = "llvm.coverage.json.export"
+
+// llvmCodeRegion is the kind of regions holding code, see CounterMappingRegion
+// in LLVM. Expansion, skipped and gap regions are not counted.
+const llvmCodeRegion = 0
+
+// llvmExportMarker matches the type of an export. llvm-cov sorts object keys,
+// so the type is written after all of the data.
+var llvmExportMarker = regexp.MustCompile(`"type"\s*:\s*"` + regexp.QuoteMeta(llvmExportType) + `"`)
+
+// LLVMJSONParser parses the JSON written by llvm-cov export -format=text for
+// programs built with LLVM source-based coverage, such as Rust with
+// -C instrument-coverage and C/C++ with clang -fprofile-instr-generate
+// -fcoverage-mapping
+type LLVMJSONParser struct {
+	diagnostics []Diagnostic
+	// functions and regions index the entries of the files, so that the
+	// instantiations of generic functions are merged
+	functions map[llvmFunctionKey]int
+	regions   map[llvmRegionKey]int
+}
+
+// NewLLVMJSONParser creates a new llvm-cov export JSON parser instance
+func NewLLVMJSONParser() *LLVMJSONParser {
+	return &LLVMJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+		functions:   make(map[llvmFunctionKey]int),
+		regions:     make(map[llvmRegionKey]int),
+	}
+}
+
+// llvmFunctionKey identifies a function of a file
+type llvmFunctionKey struct {
+	file, name string
+	line       int
+}
+
+// llvmRegionKey identifies a region of a file
+type llvmRegionKey struct {
+	file                                       string
+	startLine, startColumn, endLine, endColumn int
+}
+
+// LLVMFile represents the coverage of a single source file in an export
+type LLVMFile struct {
+	Filename   string          `json:"filename"`
+	Segments   []LLVMSegment   `json:"segments"`
+	Branches   []LLVMBranch    `json:"branches"`
+	Expansions []LLVMExpansion `json:"expansions"`
+	Summary    LLVMSummary     `json:"summary"`
+}
+
+// LLVMFunction represents a function, or one instantiation of a generic
+// function, in an export. Regions refer to Filenames by index.
+type LLVMFunction struct {
+	Name      string       `json:"name"`
+	Count     int          `json:"count"`
+	Regions   []LLVMRegion `json:"regions"`
+	Filenames []string     `json:"filenames"`
+}
+
+// LLVMExpansion represents a macro expansion inside a file. Its branches are
+// reported at the line the macro is used on.
+type LLVMExpansion struct {
+	SourceRegion LLVMRegion   `json:"source_region"`
+	Branches     []LLVMBranch `json:"branches"`
+}
+
+// LLVMSummary holds the totals llvm-cov computed for a file
+type LLVMSummary struct {
+	Lines LLVMSummaryCounts `json:"lines"`
+}
+
+// LLVMSummaryCounts holds one total of a summary
+type LLVMSummaryCounts struct {
+	Count   int `json:"count"`
+	Covered int `json:"covered"`
+}
+
+// LLVMSegment marks where the count of a file changes, written as
+// [line, column, count, hasCount, isRegionEntry, isGapRegion]
+type LLVMSegment struct {
+	Line          int
+	Column        int
+	Count         int
+	HasCount      bool
+	IsRegionEntry bool
+	IsGapRegion   bool
+}
+
+// UnmarshalJSON decodes a segment array. isGapRegion is missing in exports
+// older than version 2.0.1.
+func (s *LLVMSegment) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONTuple(data, 5, &s.Line, &s.Column, &s.Count, &s.HasCount, &s.IsRegionEntry, &s.IsGapRegion)
+}
+
+// LLVMRegion is a region of a function, written as
+// [lineStart, columnStart, lineEnd, columnEnd, count, fileID, expandedFileID, kind]
+type LLVMRegion struct {
+	LineStart      int
+	ColumnStart    int
+	LineEnd        int
+	ColumnEnd      int
+	Count          int
+	FileID         int
+	ExpandedFileID int
+	Kind           int
+}
+
+// UnmarshalJSON decodes a region array
+func (r *LLVMRegion) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONTuple(data, 8, &r.LineStart, &r.ColumnStart, &r.LineEnd, &r.ColumnEnd, &r.Count, &r.FileID, &r.ExpandedFileID, &r.Kind)
+}
+
+// LLVMBranch is a condition with the counts of both of its outcomes, written as
+// [lineStart, columnStart, lineEnd, columnEnd, trueCount, falseCount, fileID, expandedFileID, kind]
+type LLVMBranch struct {
+	LineStart   int
+	ColumnStart int
+	LineEnd     int
+	ColumnEnd   int
+	TrueCount   int
+	FalseCount  int
+}
+
+// UnmarshalJSON decodes a branch array, ignoring the file ids and kind
+func (b *LLVMBranch) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONTuple(data, 6, &b.LineStart, &b.ColumnStart, &b.LineEnd, &b.ColumnEnd, &b.TrueCount, &b.FalseCount)
+}
+
+// unmarshalJSONTuple decodes a JSON array into fields by position. The array
+// must have at least required elements, fields beyond its end are left alone
+// and elements beyond the fields are ignored.
+func unmarshalJSONTuple(data []byte, required int, fields ...any) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	if len(elements) < required {
+		return fmt.Errorf("expected at least %d values, got %d", required, len(elements))
+	}
+	for i, field := range fields {
+		if i >= len(elements) {
+			break
+		}
+		if err := json.Unmarshal(elements[i], field); err != nil {
+			return fmt.Errorf("value %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Parse reads and parses an llvm-cov export. Files and functions are decoded
+// one at a time, so large exports are never held in memory as a whole.
+func (p *LLVMJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	var exportType string
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(key string) error {
+		switch key {
+		case "data":
+			return forEachJSONElement(decoder, func() error {
+				return p.parseExport(decoder, report)
+			})
+		case "type":
+			return decoder.Decode(&exportType)
+		default:
+			return skipJSONValue(decoder)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if exportType != llvmExportType {
+		return nil, fmt.Errorf("not an llvm-cov export, type is %q", exportType)
+	}
+
+	// Regions and branches are counted once all functions are known
+	for _, file := range report.Files {
+		file.CountRegions()
+		file.CountBranches()
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseExport reads the files and functions of one element of "data"
+func (p *LLVMJSONParser) parseExport(decoder *json.Decoder, report *models.CoverageReport) error {
+	return forEachJSONKey(decoder, func(key string) error {
+		switch key {
+		case "files":
+			return forEachJSONElement(decoder, func() error {
+				var fileData LLVMFile
+				if err := decoder.Decode(&fileData); err != nil {
+					return err
+				}
+				p.parseFile(fileData, report)
+				return nil
+			})
+		case "functions":
+			return forEachJSONElement(decoder, func() error {
+				var fn LLVMFunction
+				if err := decoder.Decode(&fn); err != nil {
+					return err
+				}
+				p.parseFunction(fn, report)
+				return nil
+			})
+		default:
+			return skipJSONValue(decoder)
+		}
+	})
+}
+
+// parseFile converts the segments and branches of a file
+func (p *LLVMJSONParser) parseFile(fileData LLVMFile, report *models.CoverageReport) {
+	if fileData.Filename == "" {
+		p.addWarning("file", CodeMissingData, "file entry without a filename")
+		return
+	}
+
+	file := &models.FileCoverage{
+		FileName:  fileData.Filename,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     llvmLines(fileData.Segments),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+	file.CountLines()
+
+	if fileData.Summary.Lines.Count != 0 && fileData.Summary.Lines.Count != file.TotalLines {
+		p.addWarning("summary", CodeSummaryMismatch, fmt.Sprintf("file %s: summary line count (%d) doesn't match segments (%d)", fileData.Filename, fileData.Summary.Lines.Count, file.TotalLines))
+	}
+
+	// Each branch has a true and a false outcome. Branches inside macro
+	// expansions are reported at the line of the expansion, like llvm-cov's
+	// LCOV export does.
+	blocks := make(map[int]int)
+	addBranch := func(lineNum int, branch LLVMBranch) {
+		block := blocks[lineNum]
+		blocks[lineNum]++
+		notExecuted := branch.TrueCount == 0 && branch.FalseCount == 0
+		for i, taken := range []int{branch.TrueCount, branch.FalseCount} {
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber:  lineNum,
+				BlockNumber: block,
+				BranchID:    strconv.Itoa(i),
+				TakenCount:  taken,
+				NotExecuted: notExecuted,
+			})
+		}
+	}
+	for _, branch := range fileData.Branches {
+		addBranch(branch.LineStart, branch)
+	}
+	for _, expansion := range fileData.Expansions {
+		for _, branch := range expansion.Branches {
+			addBranch(expansion.SourceRegion.LineStart, branch)
+		}
+	}
+
+	if existing := report.GetFile(file.FileName); existing != nil {
+		existing.Merge(file)
+		return
+	}
+	report.AddFile(file)
+}
+
+// parseFunction adds a function and its code regions to the files they are in.
+// Instantiations of the same generic function are merged.
+func (p *LLVMJSONParser) parseFunction(fn LLVMFunction, report *models.CoverageReport) {
+	if len(fn.Regions) == 0 {
+		p.addWarning("function", CodeMissingData, fmt.Sprintf("function %s has no regions", fn.Name))
+		return
+	}
+
+	for _, region := range fn.Regions {
+		if region.FileID < 0 || region.FileID >= len(fn.Filenames) {
+			p.addWarning("function", CodeInvalidRecord, fmt.Sprintf("function %s: region refers to unknown file %d", fn.Name, region.FileID))
+			continue
+		}
+		// Like llvm-cov's region totals, only code regions are counted
+		if region.Kind != llvmCodeRegion {
+			continue
+		}
+
+		file := p.getFile(fn.Filenames[region.FileID], report)
+		key := llvmRegionKey{file.FileName, region.LineStart, region.ColumnStart, region.LineEnd, region.ColumnEnd}
+		if i, exists := p.regions[key]; exists {
+			file.Regions[i].Count += region.Count
+			continue
+		}
+		p.regions[key] = len(file.Regions)
+		file.Regions = append(file.Regions, models.RegionCoverage{
+			StartLine:   region.LineStart,
+			StartColumn: region.ColumnStart,
+			EndLine:     region.LineEnd,
+			EndColumn:   region.ColumnEnd,
+			Count:       region.Count,
+		})
+	}
+
+	// The function starts where its first region does
+	first := fn.Regions[0]
+	if first.FileID < 0 || first.FileID >= len(fn.Filenames) {
+		return
+	}
+	file := p.getFile(fn.Filenames[first.FileID], report)
+	key := llvmFunctionKey{file.FileName, fn.Name, first.LineStart}
+	if i, exists := p.functions[key]; exists {
+		file.Functions[i].ExecutionCount += fn.Count
+		return
+	}
+	p.functions[key] = len(file.Functions)
+	file.Functions = append(file.Functions, models.FunctionCoverage{
+		Name:           fn.Name,
+		LineNumber:     first.LineStart,
+		ExecutionCount: fn.Count,
+	})
+}
+
+// getFile returns the coverage of a file, creating it for functions in files
+// the export has no file entry for
+func (p *LLVMJSONParser) getFile(filename string, report *models.CoverageReport) *models.FileCoverage {
+	file := report.GetFile(filename)
+	if file == nil {
+		file = &models.FileCoverage{
+			FileName:  filename,
+			Functions: make([]models.FunctionCoverage, 0),
+			Lines:     make(map[int]models.LineCoverage),
+			Branches:  make([]models.BranchCoverage, 0),
+		}
+		report.AddFile(file)
+	}
+	return file
+}
+
+// llvmLines derives line coverage from the segments of a file the way llvm-cov
+// does: a line is executable when a region starts on it or a region with a
+// count continues onto it, and its count is the highest of those regions.
+func llvmLines(segments []LLVMSegment) map[int]models.LineCoverage {
+	lines := make(map[int]models.LineCoverage)
+	if len(segments) == 0 {
+		return lines
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		if segments[i].Line != segments[j].Line {
+			return segments[i].Line < segments[j].Line
+		}
+		return segments[i].Column < segments[j].Column
+	})
+
+	// wrapped is the last segment of an earlier line, still in effect
+	var wrapped *LLVMSegment
+	next := 0
+	for lineNum := segments[0].Line; lineNum <= segments[len(segments)-1].Line; lineNum++ {
+		start := next
+		for next < len(segments) && segments[next].Line == lineNum {
+			next++
+		}
+		lineSegments := segments[start:next]
+
+		// A line starting a skipped region, e.g. code disabled by #ifdef, is
+		// only executable if another region starts on it
+		startsSkipped := len(lineSegments) > 0 && !lineSegments[0].HasCount && lineSegments[0].IsRegionEntry
+		mapped := !startsSkipped && wrapped != nil && wrapped.HasCount
+		regionStarts := 0
+		for _, segment := range lineSegments {
+			if segment.IsRegionEntry && segment.HasCount {
+				mapped = true
+				if !segment.IsGapRegion {
+					regionStarts++
+				}
+			}
+		}
+
+		if mapped {
+			count := 0
+			if wrapped != nil {
+				count = wrapped.Count
+			}
+			if regionStarts > 0 {
+				for _, segment := range lineSegments {
+					if segment.IsRegionEntry && segment.HasCount && !segment.IsGapRegion {
+						count = max(count, segment.Count)
+					}
+				}
+			}
+			lines[lineNum] = models.LineCoverage{
+				LineNumber:     lineNum,
+				ExecutionCount: count,
+			}
+		}
+
+		if len(lineSegments) > 0 {
+			wrapped = &lineSegments[len(lineSegments)-1]
+		}
+	}
+	return lines
+}
+
+// Name returns the canonical format name
+func (p *LLVMJSONParser) Name() string {
+	return "llvm"
+}
+
+// Description returns a human readable format name
+func (p *LLVMJSONParser) Description() string {
+	return "LLVM Coverage JSON"
+}
+
+// Aliases returns "llvm-cov", the name of the tool writing the format
+func (p *LLVMJSONParser) Aliases() []string {
+	return []string{"llvm-cov"}
+}
+
+// FilePatterns returns no patterns, llvm-cov export writes to stdout and the
+// format is detected by content
+func (p *LLVMJSONParser) FilePatterns() []string {
+	return nil
+}
+
+// Sniff is certain when the header carries the export type marker, which
+// llvm-cov writes last, and confident when it starts with the files or
+// functions of an export
+func (p *LLVMJSONParser) Sniff(header []byte) Confidence {
+	if llvmExportMarker.Match(header) {
+		return ConfidenceCertain
+	}
+	doc := sniffJSON(header)
+	if doc.has("data", "[]", "files") || doc.has("data", "[]", "functions") {
+		return ConfidenceHigh
+	}
+	return NoMatch
+}
+
+// Detect reports whether the header looks like an export
+func (p *LLVMJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the export to the parser
+func (p *LLVMJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *LLVMJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *LLVMJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLLVMJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/llvm-cov.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewLLVMJSONParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(parser.GetWarnings()) != 0 {
+		t.Errorf("Expected no warnings, got: %v", parser.GetWarnings())
+	}
+
+	main := report.Files["/home/dev/demo/src/main.rs"]
+	if main == nil {
+		t.Fatalf("Expected file '/home/dev/demo/src/main.rs' not found")
+	}
+
+	// The totals match the summary llvm-cov wrote
+	if main.TotalLines != 17 || main.CoveredLines != 13 {
+		t.Errorf("Expected 13 of 17 lines covered, got: %d of %d", main.CoveredLines, main.TotalLines)
+	}
+
+	// The else block ends on line 8 with count 2, the gap region after it does not count
+	if main.Lines[3].ExecutionCount != 0 || main.Lines[8].ExecutionCount != 2 || main.Lines[16].ExecutionCount != 3 {
+		t.Errorf("Unexpected line counts: %+v", main.Lines)
+	}
+	if _, exists := main.Lines[10]; exists {
+		t.Error("Expected line 10 between functions to be non-executable")
+	}
+
+	if main.TotalRegions != 10 || main.CoveredRegions != 8 {
+		t.Errorf("Expected 8 of 10 regions covered, got: %d of %d", main.CoveredRegions, main.TotalRegions)
+	}
+
+	if main.TotalBranches != 4 || main.CoveredBranches != 3 {
+		t.Errorf("Expected 3 of 4 branches covered, got: %d of %d", main.CoveredBranches, main.TotalBranches)
+	}
+	if main.Branches[0].LineNumber != 2 || main.Branches[0].TakenCount != 0 || main.Branches[1].TakenCount != 3 {
+		t.Errorf("Unexpected first branch: %+v, %+v", main.Branches[0], main.Branches[1])
+	}
+
+	if len(main.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got: %d", len(main.Functions))
+	}
+	if fn := main.Functions[0]; fn.Name != "_ZN4main8classify17h6a2c9f1e0b8d7c54E" || fn.LineNumber != 1 || fn.ExecutionCount != 3 {
+		t.Errorf("Unexpected first function: %+v", fn)
+	}
+}
+
+func TestLLVMJSONParser_Parse_MergesInstantiations(t *testing.T) {
+	input := `{"data":[{"files":[],"functions":[
+		{"name":"_ZN3lib2idIiE","count":2,"filenames":["lib.rs"],"regions":[[1,1,3,2,2,0,0,0]]},
+		{"name":"_ZN3lib2idIiE","count":0,"filenames":["lib.rs"],"regions":[[1,1,3,2,0,0,0,0],[5,1,5,2,0,0,0,2]]}
+	]}],"type":"llvm.coverage.json.export","version":"2.0.1"}`
+
+	report, err := NewLLVMJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["lib.rs"]
+	if file == nil {
+		t.Fatalf("Expected file 'lib.rs' not found")
+	}
+	if len(file.Functions) != 1 || file.Functions[0].ExecutionCount != 2 {
+		t.Errorf("Expected one function run twice, got: %+v", file.Functions)
+	}
+	// The skipped region is not counted
+	if file.TotalRegions != 1 || file.CoveredRegions != 1 {
+		t.Errorf("Expected 1 covered region, got: %d of %d", file.CoveredRegions, file.TotalRegions)
+	}
+}
+
+func TestLLVMJSONParser_Parse_ExpansionBranches(t *testing.T) {
+	input := `{"data":[{"files":[{"filename":"a.c","segments":[[1,1,1,true,true,false],[3,2,0,false,false,false]],
+		"branches":[],
+		"expansions":[{"source_region":[2,5,2,20,1,0,1,1],"branches":[[7,9,7,15,1,0,1,0,4]]}]}],
+		"functions":[]}],"type":"llvm.coverage.json.export","version":"2.0.1"}`
+
+	report, err := NewLLVMJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["a.c"]
+	if file == nil || len(file.Branches) != 2 {
+		t.Fatalf("Expected 2 branch outcomes, got: %+v", file)
+	}
+	if file.Branches[0].LineNumber != 2 {
+		t.Errorf("Expected branch at the line of the macro use, got: %d", file.Branches[0].LineNumber)
+	}
+	if file.TotalBranches != 2 || file.CoveredBranches != 1 {
+		t.Errorf("Expected 1 of 2 branches covered, got: %d of %d", file.CoveredBranches, file.TotalBranches)
+	}
+}
+
+func TestLLVMJSONParser_Parse_SummaryMismatch(t *testing.T) {
+	input := `{"data":[{"files":[{"filename":"a.c","segments":[[1,1,1,true,true,false],[2,2,0,false,false,false]],
+		"summary":{"lines":{"count":5,"covered":2}}}]}],"type":"llvm.coverage.json.export"}`
+
+	parser := NewLLVMJSONParser()
+	if _, err := parser.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	diagnostics := parser.Diagnostics()
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeSummaryMismatch {
+		t.Errorf("Expected a summary-mismatch warning, got: %v", diagnostics)
+	}
+}
+
+func TestLLVMJSONParser_Parse_UnknownFileID(t *testing.T) {
+	input := `{"data":[{"functions":[{"name":"f","count":1,"filenames":["a.c"],"regions":[[1,1,2,2,1,0,0,0],[3,1,3,5,1,4,0,0]]}]}],"type":"llvm.coverage.json.export"}`
+
+	parser := NewLLVMJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeInvalidRecord {
+		t.Errorf("Expected an invalid-record warning, got: %v", parser.Diagnostics())
+	}
+	if file := report.Files["a.c"]; file == nil || file.TotalRegions != 1 {
+		t.Errorf("Expected the valid region to be kept, got: %+v", file)
+	}
+}
+
+func TestLLVMJSONParser_Parse_WrongType(t *testing.T) {
+	_, err := NewLLVMJSONParser().Parse(strings.NewReader(`{"data":[],"type":"something.else"}`))
+	if err == nil {
+		t.Error("Expected error for an export of another type")
+	}
+}
+
+func TestLLVMJSONParser_Parse_InvalidSegment(t *testing.T) {
+	input := `{"data":[{"files":[{"filename":"a.c","segments":[[1,1]]}]}],"type":"llvm.coverage.json.export"}`
+	if _, err := NewLLVMJSONParser().Parse(strings.NewReader(input)); err == nil {
+		t.Error("Expected error for a truncated segment")
+	}
+}
+
+func TestLLVMJSONParser_Detect(t *testing.T) {
+	parser := NewLLVMJSONParser()
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{`{"data":[{"files":[{"branches":[]`, true},
+		{"{\n  \"data\": [\n    {\n      \"files\": [", true},
+		{`{"version":"2.0.1","type": "llvm.coverage.json.export","data":[]}`, true},
+		{`{"meta": {}, "files": {}}`, false},
+	}
+
+	for _, test := range tests {
+		if got := parser.Detect([]byte(test.header)); got != test.expected {
+			t.Errorf("Detect(%q): expected %v, got: %v", test.header, test.expected, got)
+		}
+	}
+}
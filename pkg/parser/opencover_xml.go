@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// OpenCoverXMLParser parses OpenCover XML reports (.NET), as written by
+// OpenCover, coverlet and dotnet-coverage
+type OpenCoverXMLParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewOpenCoverXMLParser creates a new OpenCover XML coverage parser instance
+func NewOpenCoverXMLParser() *OpenCoverXMLParser {
+	return &OpenCoverXMLParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// OpenCoverFile represents a <File> element, sequence and branch points refer
+// to it by uid
+type OpenCoverFile struct {
+	UID      int    `xml:"uid,attr"`
+	FullPath string `xml:"fullPath,attr"`
+}
+
+// OpenCoverClass represents a <Class> element with its methods
+type OpenCoverClass struct {
+	FullName string            `xml:"FullName"`
+	Methods  []OpenCoverMethod `xml:"Methods>Method"`
+}
+
+// OpenCoverMethod represents a <Method> element. Name is the full signature,
+// e.g. "System.Int32 Shop.Cart::Total(System.Int32)".
+type OpenCoverMethod struct {
+	Name           string                   `xml:"Name"`
+	FileRef        OpenCoverFileRef         `xml:"FileRef"`
+	SequencePoints []OpenCoverSequencePoint `xml:"SequencePoints>SequencePoint"`
+	BranchPoints   []OpenCoverBranchPoint   `xml:"BranchPoints>BranchPoint"`
+}
+
+// OpenCoverFileRef refers to the file a method is declared in
+type OpenCoverFileRef struct {
+	UID int `xml:"uid,attr"`
+}
+
+// OpenCoverSequencePoint represents a statement and how often it ran
+type OpenCoverSequencePoint struct {
+	VisitCount int `xml:"vc,attr"`
+	StartLine  int `xml:"sl,attr"`
+	EndLine    int `xml:"el,attr"`
+	FileID     int `xml:"fileid,attr"`
+}
+
+// OpenCoverBranchPoint represents one path of a conditional jump at an IL offset
+type OpenCoverBranchPoint struct {
+	VisitCount int `xml:"vc,attr"`
+	StartLine  int `xml:"sl,attr"`
+	Offset     int `xml:"offset,attr"`
+	Path       int `xml:"path,attr"`
+	FileID     int `xml:"fileid,attr"`
+}
+
+// Parse reads and parses an OpenCover XML file. Classes are decoded one at a
+// time, only the files of the current module are held.
+func (p *OpenCoverXMLParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	moduleName := ""
+	files := make(map[int]string)
+
+	decoder := xml.NewDecoder(reader)
+	_, err := walkXML(decoder, "CoverageSession", func(start *xml.StartElement) (bool, error) {
+		switch start.Name.Local {
+		case "ModuleName":
+			if err := decoder.DecodeElement(&moduleName, start); err != nil {
+				return true, err
+			}
+			moduleName = strings.TrimSpace(moduleName)
+			return true, nil
+		case "File":
+			// <Files> precede the <Classes> of their module
+			var file OpenCoverFile
+			if err := decoder.DecodeElement(&file, start); err != nil {
+				return true, err
+			}
+			files[file.UID] = file.FullPath
+			return true, nil
+		case "Class":
+			var class OpenCoverClass
+			if err := decoder.DecodeElement(&class, start); err != nil {
+				return true, err
+			}
+			p.parseClass(moduleName, files, class, report)
+			return true, nil
+		}
+		return false, nil
+	}, func(name string) {
+		if name == "Module" {
+			moduleName = ""
+			files = make(map[int]string)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	// Count lines once per file, several classes may share one file
+	for _, file := range report.Files {
+		file.CountLines()
+		file.CountBranches()
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseClass adds the sequence points, branch points and methods of a class
+// to the files they belong to
+func (p *OpenCoverXMLParser) parseClass(moduleName string, files map[int]string, class OpenCoverClass, report *models.CoverageReport) {
+	fileFor := func(fileID int) *models.FileCoverage {
+		filename, ok := files[fileID]
+		if !ok {
+			p.addWarning("SequencePoint", CodeOrphanRecord, fmt.Sprintf("class %s refers to unknown file %d", class.FullName, fileID))
+			return nil
+		}
+		return dotNetFile(report, moduleName, filename)
+	}
+
+	for _, method := range class.Methods {
+		// Compiler generated methods have no source
+		if len(method.SequencePoints) == 0 {
+			continue
+		}
+
+		fn := models.FunctionCoverage{Name: dotNetMethodName(method.Name)}
+		var fnFile *models.FileCoverage
+		for _, point := range method.SequencePoints {
+			if point.StartLine <= 0 {
+				continue
+			}
+			fileID := point.FileID
+			if fileID == 0 {
+				fileID = method.FileRef.UID
+			}
+			file := fileFor(fileID)
+			if file == nil {
+				continue
+			}
+			if point.VisitCount < 0 {
+				p.addWarning("SequencePoint", CodeInvalidValue, fmt.Sprintf("file %s: line %d has negative visit count %d", file.FileName, point.StartLine, point.VisitCount))
+				continue
+			}
+			addDotNetLines(file, point.StartLine, point.EndLine, point.VisitCount)
+
+			if fnFile == nil || point.StartLine < fn.LineNumber && file == fnFile {
+				fnFile = file
+				fn.LineNumber = point.StartLine
+				fn.ExecutionCount = point.VisitCount
+			}
+		}
+
+		for _, point := range method.BranchPoints {
+			fileID := point.FileID
+			if fileID == 0 {
+				fileID = method.FileRef.UID
+			}
+			file := fileFor(fileID)
+			if file == nil || point.StartLine <= 0 {
+				continue
+			}
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber:  point.StartLine,
+				BlockNumber: point.Offset,
+				BranchID:    strconv.Itoa(point.Path),
+				TakenCount:  point.VisitCount,
+			})
+		}
+
+		if fnFile != nil {
+			fnFile.Functions = append(fnFile.Functions, fn)
+		}
+	}
+}
+
+// dotNetFile returns the coverage entry of a source file, adding it to the
+// report on first use. The package is the name of the module (assembly).
+func dotNetFile(report *models.CoverageReport, moduleName, filename string) *models.FileCoverage {
+	if file := report.GetFile(filename); file != nil {
+		return file
+	}
+	file := &models.FileCoverage{
+		FileName:  filename,
+		Package:   moduleName,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+	report.AddFile(file)
+	return file
+}
+
+// addDotNetLines records the hits of a statement on every line it spans. A
+// line holding several statements, or one shared by a method and the lambdas
+// or state machines generated from it, keeps its highest hit count.
+func addDotNetLines(file *models.FileCoverage, startLine, endLine, hits int) {
+	if endLine < startLine {
+		endLine = startLine
+	}
+	for line := startLine; line <= endLine; line++ {
+		if existing, ok := file.Lines[line]; ok && existing.ExecutionCount >= hits {
+			continue
+		}
+		file.Lines[line] = models.LineCoverage{
+			LineNumber:     line,
+			ExecutionCount: hits,
+		}
+	}
+}
+
+// dotNetMethodName shortens a method signature such as
+// "System.Int32 Shop.Cart::Total(System.Int32)" to the simple class and
+// method name, "Cart.Total". Nested classes are separated by "/".
+func dotNetMethodName(signature string) string {
+	name := signature
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	name = name[strings.LastIndex(name, " ")+1:]
+
+	class, method, ok := strings.Cut(name, "::")
+	if !ok {
+		return name
+	}
+	class = class[strings.LastIndexAny(class, "./")+1:]
+	return class + "." + method
+}
+
+// Name returns the canonical format name
+func (p *OpenCoverXMLParser) Name() string {
+	return "opencover"
+}
+
+// Description returns a human readable format name
+func (p *OpenCoverXMLParser) Description() string {
+	return "OpenCover XML Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "opencover"
+func (p *OpenCoverXMLParser) Aliases() []string {
+	return nil
+}
+
+// FilePatterns returns the file names OpenCover reports are usually written
+// to, such as coverlet's coverage.opencover.xml
+func (p *OpenCoverXMLParser) FilePatterns() []string {
+	return []string{"*opencover*.xml"}
+}
+
+// Sniff is certain when the document has a <CoverageSession> root
+func (p *OpenCoverXMLParser) Sniff(header []byte) Confidence {
+	if sniffXML(header).rootIs("CoverageSession") {
+		return ConfidenceCertain
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document has a <CoverageSession> root
+func (p *OpenCoverXMLParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *OpenCoverXMLParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *OpenCoverXMLParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *OpenCoverXMLParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOpenCoverXMLParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/opencover.xml")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewOpenCoverXMLParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	cart := report.Files["/src/Shop/Cart.cs"]
+	if cart == nil {
+		t.Fatalf("Expected file '/src/Shop/Cart.cs' not found")
+	}
+	if cart.Package != "Shop" {
+		t.Errorf("Expected package 'Shop', got: %s", cart.Package)
+	}
+	// Line 10 is spanned by the statement starting on line 9, line 11 ran in the lambda
+	if cart.TotalLines != 6 || cart.CoveredLines != 6 || cart.Lines[10].ExecutionCount != 2 || cart.Lines[11].ExecutionCount != 4 {
+		t.Errorf("Expected 6 of 6 lines covered, got: %d of %d", cart.CoveredLines, cart.TotalLines)
+	}
+	if cart.TotalBranches != 2 || cart.CoveredBranches != 1 {
+		t.Errorf("Expected 1 of 2 branches covered, got: %d of %d", cart.CoveredBranches, cart.TotalBranches)
+	}
+	if len(cart.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got: %+v", cart.Functions)
+	}
+	if fn := cart.Functions[1]; fn.Name != "Cart.Total" || fn.LineNumber != 8 || fn.ExecutionCount != 2 {
+		t.Errorf("Unexpected second function: %+v", fn)
+	}
+
+	// Methods without sequence points are left out
+	discount := report.Files["/src/Shop/Discount.cs"]
+	if discount == nil {
+		t.Fatalf("Expected file '/src/Shop/Discount.cs' not found")
+	}
+	if discount.TotalLines != 2 || discount.CoveredLines != 0 || len(discount.Functions) != 1 {
+		t.Errorf("Unexpected file: %+v", discount)
+	}
+}
+
+func TestOpenCoverXMLParser_Parse_UnknownFile(t *testing.T) {
+	input := `<CoverageSession><Modules><Module><ModuleName>Shop</ModuleName><Files><File uid="1" fullPath="a.cs" /></Files><Classes><Class><FullName>Shop.A</FullName><Methods><Method>
+		<Name>System.Void Shop.A::Run()</Name><FileRef uid="1" />
+		<SequencePoints><SequencePoint vc="1" sl="3" el="3" fileid="1" /><SequencePoint vc="1" sl="4" el="4" fileid="7" /></SequencePoints>
+	</Method></Methods></Class></Classes></Module></Modules></CoverageSession>`
+
+	parser := NewOpenCoverXMLParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.Diagnostics()) != 1 || parser.Diagnostics()[0].Code != CodeOrphanRecord {
+		t.Errorf("Expected one orphan-record warning, got: %v", parser.Diagnostics())
+	}
+	if file := report.Files["a.cs"]; file == nil || file.TotalLines != 1 {
+		t.Errorf("Expected the known file to be kept, got: %+v", file)
+	}
+}
+
+func TestOpenCoverXMLParser_Parse_InvalidXML(t *testing.T) {
+	if _, err := NewOpenCoverXMLParser().Parse(strings.NewReader(`<coverage></coverage>`)); err == nil {
+		t.Error("Expected error for a non-OpenCover root element")
+	}
+}
+
+func TestDotNetMethodName(t *testing.T) {
+	tests := []struct {
+		signature string
+		expected  string
+	}{
+		{"System.Int32 Shop.Cart::Total(System.Int32)", "Cart.Total"},
+		{"System.Void Shop.Cart::.ctor()", "Cart..ctor"},
+		{"System.Boolean Shop.Cart/<>c::<Total>b__1_0(Shop.Item)", "<>c.<Total>b__1_0"},
+		{"Main", "Main"},
+	}
+
+	for _, test := range tests {
+		if result := dotNetMethodName(test.signature); result != test.expected {
+			t.Errorf("dotNetMethodName(%q): expected %s, got: %s", test.signature, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// Parser is implemented by every coverage format. Besides parsing, a parser
+// describes how its format is named on the command line and how it is detected.
+type Parser interface {
+	// Name returns the canonical format name accepted by --format, e.g. "lcov"
+	Name() string
+	// Description returns a human readable format name, e.g. "LCOV"
+	Description() string
+	// Aliases returns additional names accepted by --format
+	Aliases() []string
+	// FilePatterns returns glob patterns matched against the lower-cased base
+	// name of a coverage file, e.g. "*.lcov" or "coverage-final.json"
+	FilePatterns() []string
+	// Detect reports whether the beginning of a coverage file looks like this
+	// format. Formats implementing Sniffer rate their match instead.
+	Detect(header []byte) bool
+	// Parse reads and parses a coverage file
+	Parse(reader io.Reader) (*models.CoverageReport, error)
+	// Diagnostics returns the problems found by the last call to Parse, or
+	// ParseDir for a DirParser
+	Diagnostics() []Diagnostic
+}
+
+// DirParser is implemented by formats written as a directory of files rather
+// than a single file, such as Go's GOCOVERDIR. Their Parse method cannot read
+// coverage data and returns an error.
+type DirParser interface {
+	Parser
+	// DetectDir reports whether the files of a directory look like this format
+	DetectDir(fsys fs.FS) bool
+	// ParseDir reads and parses the coverage files of a directory
+	ParseDir(fsys fs.FS) (*models.CoverageReport, error)
+}
+
+// registration holds a registered format and the factory creating fresh parsers for it
+type registration struct {
+	proto   Parser
+	factory func() Parser
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registration
+)
+
+func init() {
+	// Built-in formats, in detection priority order
+	Register(func() Parser { return NewGoCoverParser() })
+	Register(func() Parser { return NewJaCoCoXMLParser() })
+	Register(func() Parser { return NewOpenCoverXMLParser() })
+	Register(func() Parser { return NewCloverXMLParser() })
+	Register(func() Parser { return NewCoberturaXMLParser() })
+	Register(func() Parser { return NewLLVMJSONParser() })
+	Register(func() Parser { return NewGcovJSONParser() })
+	Register(func() Parser { return NewGcovrJSONParser() })
+	Register(func() Parser { return NewCoverletJSONParser() })
+	Register(func() Parser { return NewIstanbulJSONParser() })
+	Register(func() Parser { return NewSimpleCovJSONParser() })
+	Register(func() Parser { return NewPyCoverJSONParser() })
+	Register(func() Parser { return NewLCOVParser() })
+	Register(func() Parser { return NewGoCoverDirParser() })
+}
+
+// Register adds a coverage format to the registry. The factory is called for
+// every file that is parsed, so parsers may keep per-file state such as warnings.
+// Formats registered later are detected with lower priority. Register panics if
+// the name or one of the aliases of the format is already taken.
+func Register(factory func() Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	proto := factory()
+	for _, name := range formatNames(proto) {
+		for _, reg := range registry {
+			for _, existing := range formatNames(reg.proto) {
+				if strings.EqualFold(name, existing) {
+					panic(fmt.Sprintf("parser: format name %q registered twice", name))
+				}
+			}
+		}
+	}
+
+	registry = append(registry, registration{proto: proto, factory: factory})
+}
+
+// Lookup returns a new parser for the format with the given name or alias,
+// or nil if no such format is registered. Names are case-insensitive.
+func Lookup(name string) Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, reg := range registry {
+		for _, candidate := range formatNames(reg.proto) {
+			if strings.EqualFold(name, candidate) {
+				return reg.factory()
+			}
+		}
+	}
+	return nil
+}
+
+// ForFilename returns a new parser for the first format whose file patterns
+// match the given file name, or nil if none does
+func ForFilename(filename string) Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	base := path.Base(strings.ToLower(strings.ReplaceAll(filename, `\`, "/")))
+	for _, reg := range registry {
+		for _, pattern := range reg.proto.FilePatterns() {
+			if matched, _ := path.Match(pattern, base); matched {
+				return reg.factory()
+			}
+		}
+	}
+	return nil
+}
+
+// ForContent returns a new parser for the format that most confidently
+// recognizes the beginning of a coverage file, or nil if none does
+func ForContent(header []byte) Parser {
+	if matches := MatchContent(header); len(matches) > 0 {
+		return matches[0].Parser
+	}
+	return nil
+}
+
+// Match is a format recognizing the beginning of a coverage file
+type Match struct {
+	Parser     Parser
+	Confidence Confidence
+}
+
+// MatchContent returns new parsers for all formats recognizing the beginning
+// of a coverage file, the most confident first. Formats with the same
+// confidence keep their registry order.
+func MatchContent(header []byte) []Match {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var matches []Match
+	for _, reg := range registry {
+		if confidence := sniff(reg.proto, header); confidence > NoMatch {
+			matches = append(matches, Match{Parser: reg.factory(), Confidence: confidence})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return matches
+}
+
+// ForDirectory returns a new parser for the first directory format that
+// recognizes the files of a directory, or nil if none does
+func ForDirectory(fsys fs.FS) DirParser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, reg := range registry {
+		if dp, ok := reg.proto.(DirParser); ok && dp.DetectDir(fsys) {
+			return reg.factory().(DirParser)
+		}
+	}
+	return nil
+}
+
+// Names returns the canonical names and aliases of all registered formats, in registry order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var names []string
+	for _, reg := range registry {
+		names = append(names, formatNames(reg.proto)...)
+	}
+	return names
+}
+
+// formatNames returns the canonical name of a format followed by its aliases
+func formatNames(p Parser) []string {
+	return append([]string{p.Name()}, p.Aliases()...)
+}
+
+// headerLines splits a detection header into trimmed lines
+func headerLines(header []byte) []string {
+	lines := strings.Split(string(header), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,234 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// fakeParser is a minimal external format used to exercise Register
+type fakeParser struct {
+	name    string
+	aliases []string
+}
+
+func (p *fakeParser) Name() string           { return p.name }
+func (p *fakeParser) Description() string    { return "Fake Coverage" }
+func (p *fakeParser) Aliases() []string      { return p.aliases }
+func (p *fakeParser) FilePatterns() []string { return []string{"*.fakecov"} }
+func (p *fakeParser) Detect(header []byte) bool {
+	return strings.HasPrefix(string(header), "FAKECOV")
+}
+func (p *fakeParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	return models.NewCoverageReport(), nil
+}
+func (p *fakeParser) Diagnostics() []Diagnostic { return nil }
+
+// withRegistry restores the registry after a test registered extra formats
+func withRegistry(t *testing.T) {
+	registryMu.RLock()
+	saved := append([]registration(nil), registry...)
+	registryMu.RUnlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"lcov", "lcov"},
+		{"rust", "lcov"},
+		{"ts", "lcov"},
+		{"go", "go"},
+		{"GO", "go"},
+		{"cobertura", "cobertura"},
+		{"python", "cobertura"},
+		{"pyxml", "cobertura"},
+		{"pyjson", "pyjson"},
+		{"istanbul", "istanbul"},
+		{"jacoco", "jacoco"},
+	}
+
+	for _, test := range tests {
+		p := Lookup(test.name)
+		if p == nil {
+			t.Fatalf("Expected parser for %q, got nil", test.name)
+		}
+		if p.Name() != test.expected {
+			t.Errorf("Lookup(%q): expected %s, got: %s", test.name, test.expected, p.Name())
+		}
+	}
+
+	if p := Lookup("java"); p != nil {
+		t.Errorf("Expected nil for unknown format, got: %s", p.Name())
+	}
+}
+
+func TestLookup_ReturnsFreshParsers(t *testing.T) {
+	a := Lookup("lcov")
+	b := Lookup("lcov")
+	if a == b {
+		t.Errorf("Expected a new parser instance per lookup")
+	}
+}
+
+func TestForFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		{"coverage.lcov", "lcov"},
+		{"coverage/lcov.info", "lcov"},
+		{"coverage.out", "go"},
+		{"target/site/jacoco/jacoco.xml", "jacoco"},
+		{"coverage.xml", "cobertura"},
+		{`build\Cobertura.xml`, "cobertura"},
+		{"coverage/coverage-final.json", "istanbul"},
+		{"coverage.json", "pyjson"},
+		{"build/main.gcov.json.gz", "gcov"},
+		{"coverage/.resultset.json", "simplecov"},
+		{"TestResults/coverage.opencover.xml", "opencover"},
+		{"build/logs/clover.xml", "clover"},
+	}
+
+	for _, test := range tests {
+		p := ForFilename(test.filename)
+		if p == nil {
+			t.Fatalf("Expected parser for %q, got nil", test.filename)
+		}
+		if p.Name() != test.expected {
+			t.Errorf("ForFilename(%q): expected %s, got: %s", test.filename, test.expected, p.Name())
+		}
+	}
+
+	if p := ForFilename("report.txt"); p != nil {
+		t.Errorf("Expected nil for unknown file, got: %s", p.Name())
+	}
+}
+
+func TestForContent(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"mode: set\nfoo.go:1.1,2.2 1 1\n", "go"},
+		{"TN:\nSF:src/lib.rs\n", "lcov"},
+		{`<?xml version="1.0"?>` + "\n<coverage version=\"7.0\">\n", "cobertura"},
+		{`<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">` + "\n<report name=\"x\">\n", "jacoco"},
+		{`{"/src/a.ts": {"path": "/src/a.ts", "statementMap": {}}}`, "istanbul"},
+		{`{"meta": {}, "files": {}}`, "pyjson"},
+		{`{"data":[{"files":[{"branches":[],"expansions":[],"filename":"src/main.rs"`, "llvm"},
+		{`{"gcc_version": "12.2.0", "files": [{"lines": []`, "gcov"},
+		{`{"gcovr/format_version": "0.6", "files": [`, "gcovr"},
+		{`<?xml version="1.0" encoding="UTF-8"?>` + "\n<coverage generated=\"1760601600\">\n", "clover"},
+		{`<?xml version="1.0" encoding="utf-8"?>` + "\n<CoverageSession xmlns:xsd=\"http://www.w3.org/2001/XMLSchema\">\n", "opencover"},
+		{"{\n  \"Shop.dll\": {\n    \"/src/Shop/Cart.cs\": {\n", "coverlet"},
+		{"{\n  \"RSpec\": {\n    \"coverage\": {\n      \"/app/lib/a.rb\": {\n        \"lines\": [\n", "simplecov"},
+	}
+
+	for _, test := range tests {
+		p := ForContent([]byte(test.header))
+		if p == nil {
+			t.Fatalf("Expected parser for %q, got nil", test.header)
+		}
+		if p.Name() != test.expected {
+			t.Errorf("ForContent(%q): expected %s, got: %s", test.header, test.expected, p.Name())
+		}
+	}
+
+	if p := ForContent([]byte("hello world")); p != nil {
+		t.Errorf("Expected nil for unknown content, got: %s", p.Name())
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	for _, expected := range []string{"go", "jacoco", "cobertura", "python", "pyxml", "istanbul", "pyjson", "lcov", "rust", "ts"} {
+		found := false
+		for _, name := range names {
+			if name == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected %q in names, got: %v", expected, names)
+		}
+	}
+}
+
+func TestRegister_ExternalFormat(t *testing.T) {
+	withRegistry(t)
+
+	Register(func() Parser { return &fakeParser{name: "fake", aliases: []string{"fk"}} })
+
+	if p := Lookup("fk"); p == nil || p.Name() != "fake" {
+		t.Fatalf("Expected registered format to be found by alias")
+	}
+	if p := ForFilename("out/report.fakecov"); p == nil || p.Name() != "fake" {
+		t.Errorf("Expected registered format to be detected by file name")
+	}
+	if p := ForContent([]byte("FAKECOV 1\n")); p == nil || p.Name() != "fake" {
+		t.Errorf("Expected registered format to be detected by content")
+	}
+
+	// Built-in formats keep their priority over later registrations
+	if p := ForContent([]byte("mode: set\n")); p == nil || p.Name() != "go" {
+		t.Errorf("Expected built-in Go format to win detection")
+	}
+}
+
+func TestRegister_DuplicateNamePanics(t *testing.T) {
+	withRegistry(t)
+
+	tests := []*fakeParser{
+		{name: "LCOV"},
+		{name: "other", aliases: []string{"python"}},
+	}
+
+	for _, fake := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic registering %s %v", fake.name, fake.aliases)
+				}
+			}()
+			Register(func() Parser { return fake })
+		}()
+	}
+}
+
+func TestMatchContent(t *testing.T) {
+	withRegistry(t)
+
+	Register(func() Parser { return &fakeParser{name: "fake"} })
+
+	// Formats that only implement Detect match with medium confidence
+	matches := MatchContent([]byte("FAKECOV 1\n"))
+	if len(matches) != 1 || matches[0].Parser.Name() != "fake" || matches[0].Confidence != ConfidenceMedium {
+		t.Errorf("Expected a medium match for the fake format, got: %+v", matches)
+	}
+
+	// Matches are ordered by confidence
+	matches = MatchContent([]byte("<report>\n<sessioninfo id=\"a\"/>\n"))
+	if len(matches) == 0 || matches[0].Parser.Name() != "jacoco" || matches[0].Confidence != ConfidenceCertain {
+		t.Fatalf("Expected JaCoCo to match with certainty, got: %+v", matches)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Confidence > matches[i-1].Confidence {
+			t.Errorf("Expected matches in descending confidence, got: %+v", matches)
+		}
+	}
+
+	// A files object alone is too vague to call coverage.py
+	if matches := MatchContent([]byte(`{"files": {"a.py": {}}}`)); len(matches) != 0 {
+		t.Errorf("Expected no match, got: %+v", matches)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Chapati-Systems/covpeek/pkg/models"
@@ -12,66 +14,101 @@ import (
 
 // PyCoverJSONParser parses Python coverage JSON format
 type PyCoverJSONParser struct {
-	warnings []string
+	diagnostics []Diagnostic
 }
 
 // NewPyCoverJSONParser creates a new Python JSON coverage parser instance
 func NewPyCoverJSONParser() *PyCoverJSONParser {
 	return &PyCoverJSONParser{
-		warnings: make([]string, 0),
+		diagnostics: make([]Diagnostic, 0),
 	}
 }
 
 // CoverageJSON represents the structure of Python coverage JSON
 type CoverageJSON struct {
-	Files map[string]FileCoverageJSON `json:"files"`
+	Meta   *MetaJSON                   `json:"meta,omitempty"`
+	Files  map[string]FileCoverageJSON `json:"files"`
+	Totals *SummaryJSON                `json:"totals,omitempty"`
 }
 
-// FileCoverageJSON represents coverage data for a single file in JSON
+// MetaJSON represents the meta data describing the report
+type MetaJSON struct {
+	Format         int    `json:"format"`
+	Version        string `json:"version"`
+	Timestamp      string `json:"timestamp"`
+	BranchCoverage bool   `json:"branch_coverage"`
+	ShowContexts   bool   `json:"show_contexts"`
+}
+
+// FileCoverageJSON represents coverage data for a single file in JSON.
+// Branches are arcs from a line to the line run next, a negative target
+// leaves the function defined on that line. Contexts maps line numbers to the
+// contexts that ran them, the tests when measured with pytest-cov --cov-context=test
+// or coverage.py's dynamic_context = test_function. Functions and Classes are written by
+// coverage.py 7 and hold the same data for the regions of a file, keyed by
+// their qualified name; the "" region holds the lines outside of them.
 type FileCoverageJSON struct {
+	ExecutedLines    []int                         `json:"executed_lines"`
+	Summary          SummaryJSON                   `json:"summary"`
+	MissingLines     []int                         `json:"missing_lines"`
+	ExcludedLines    []int                         `json:"excluded_lines"`
+	ExecutedBranches [][2]int                      `json:"executed_branches,omitempty"`
+	MissingBranches  [][2]int                      `json:"missing_branches,omitempty"`
+	Contexts         map[string][]string           `json:"contexts,omitempty"`
+	Functions        map[string]RegionCoverageJSON `json:"functions,omitempty"`
+	Classes          map[string]RegionCoverageJSON `json:"classes,omitempty"`
+}
+
+// RegionCoverageJSON represents the coverage of a function or class
+type RegionCoverageJSON struct {
 	ExecutedLines []int       `json:"executed_lines"`
-	MissingLines  []int       `json:"missing_lines"`
 	Summary       SummaryJSON `json:"summary"`
+	MissingLines  []int       `json:"missing_lines"`
+	StartLine     int         `json:"start_line,omitempty"`
 }
 
-// SummaryJSON represents the summary data
+// SummaryJSON represents the summary data. The branch counts are only
+// written when branch coverage was measured.
 type SummaryJSON struct {
-	CoveredLines  int `json:"covered_lines"`
-	NumStatements int `json:"num_statements"`
+	CoveredLines          int     `json:"covered_lines"`
+	NumStatements         int     `json:"num_statements"`
+	PercentCovered        float64 `json:"percent_covered"`
+	PercentCoveredDisplay string  `json:"percent_covered_display"`
+	NumBranches           int     `json:"num_branches,omitempty"`
+	CoveredBranches       int     `json:"covered_branches,omitempty"`
 }
 
-// Parse reads and parses a Python coverage JSON file
+// Parse reads and parses a Python coverage JSON file. File entries are
+// decoded one at a time, so large reports are never held in memory as a whole.
 func (p *PyCoverJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
 	report := models.NewCoverageReport()
 
-	data, err := io.ReadAll(reader)
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(key string) error {
+		if key != "files" {
+			return skipJSONValue(decoder)
+		}
+		return forEachJSONKey(decoder, func(filename string) error {
+			var fileData FileCoverageJSON
+			if err := decoder.Decode(&fileData); err != nil {
+				return err
+			}
+			if err := p.parseFile(filename, fileData, report); err != nil {
+				p.addWarning("file", CodeInvalidRecord, fmt.Sprintf("failed to parse file %s: %v", filename, err))
+			}
+			return nil
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read JSON data: %w", err)
-	}
-
-	var coverageJSON CoverageJSON
-	if err := json.Unmarshal(data, &coverageJSON); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	// Process each file
-	for filename, fileData := range coverageJSON.Files {
-		if err := p.parseFile(filename, fileData, report); err != nil {
-			p.addWarning(fmt.Sprintf("failed to parse file %s: %v", filename, err))
-			continue
-		}
-	}
-
 	// Calculate coverage for all files
 	for _, file := range report.Files {
 		file.CalculateCoverage()
 	}
 
-	// Log all warnings
-	for _, warning := range p.warnings {
-		log.Println(warning)
-	}
-
 	return report, nil
 }
 
@@ -84,6 +121,7 @@ func (p *PyCoverJSONParser) parseFile(filename string, fileData FileCoverageJSON
 		FileName:  filename,
 		Functions: make([]models.FunctionCoverage, 0),
 		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
 	}
 
 	// Process executed lines
@@ -104,6 +142,26 @@ func (p *PyCoverJSONParser) parseFile(filename string, fileData FileCoverageJSON
 		}
 	}
 
+	// Contexts are only written for executed lines
+	for key, contexts := range fileData.Contexts {
+		lineNum, err := strconv.Atoi(key)
+		line, exists := file.Lines[lineNum]
+		if err != nil || !exists || line.ExecutionCount == 0 {
+			p.addWarning("contexts", CodeOrphanRecord, fmt.Sprintf("file %s: contexts for line %q, which is not an executed line", filename, key))
+			continue
+		}
+		line.Tests = pyCoverTests(contexts)
+		file.Lines[lineNum] = line
+	}
+
+	if len(fileData.ExcludedLines) > 0 {
+		file.ExcludedLines = append([]int(nil), fileData.ExcludedLines...)
+		sort.Ints(file.ExcludedLines)
+	}
+
+	p.parseBranches(filename, fileData, file)
+	p.parseRegions(filename, fileData, file)
+
 	// Use summary data if available
 	if fileData.Summary.NumStatements > 0 {
 		file.TotalLines = fileData.Summary.NumStatements
@@ -119,33 +177,207 @@ func (p *PyCoverJSONParser) parseFile(filename string, fileData FileCoverageJSON
 		}
 
 		if fileData.Summary.NumStatements != lineTotal {
-			p.addWarning(fmt.Sprintf("file %s: summary num_statements (%d) doesn't match line count (%d)", filename, fileData.Summary.NumStatements, lineTotal))
+			p.addWarning("summary", CodeSummaryMismatch, fmt.Sprintf("file %s: summary num_statements (%d) doesn't match line count (%d)", filename, fileData.Summary.NumStatements, lineTotal))
 		}
 		if fileData.Summary.CoveredLines != lineCovered {
-			p.addWarning(fmt.Sprintf("file %s: summary covered_lines (%d) doesn't match calculated covered lines (%d)", filename, fileData.Summary.CoveredLines, lineCovered))
+			p.addWarning("summary", CodeSummaryMismatch, fmt.Sprintf("file %s: summary covered_lines (%d) doesn't match calculated covered lines (%d)", filename, fileData.Summary.CoveredLines, lineCovered))
 		}
 	} else {
 		// Fallback: calculate from line data
-		file.TotalLines = len(file.Lines)
-		coveredCount := 0
-		for _, lineCov := range file.Lines {
-			if lineCov.ExecutionCount > 0 {
-				coveredCount++
+		file.CountLines()
+	}
+
+	report.AddFile(file)
+	return nil
+}
+
+// parseBranches adds the executed and missing arcs of a file as branches of
+// the line they start on. The branch ID is the target line.
+func (p *PyCoverJSONParser) parseBranches(filename string, fileData FileCoverageJSON, file *models.FileCoverage) {
+	for i, arcs := range [][][2]int{fileData.ExecutedBranches, fileData.MissingBranches} {
+		taken := 1 - i
+		for _, arc := range arcs {
+			if arc[0] <= 0 {
+				p.addWarning("branches", CodeInvalidValue, fmt.Sprintf("file %s: branch from invalid line %d", filename, arc[0]))
+				continue
 			}
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber: arc[0],
+				BranchID:   strconv.Itoa(arc[1]),
+				TakenCount: taken,
+			})
 		}
-		file.CoveredLines = coveredCount
 	}
+	sort.SliceStable(file.Branches, func(i, j int) bool {
+		return file.Branches[i].LineNumber < file.Branches[j].LineNumber
+	})
 
-	report.AddFile(file)
+	file.CountBranches()
+	if fileData.Summary.NumBranches > 0 && fileData.Summary.NumBranches != file.TotalBranches {
+		p.addWarning("summary", CodeSummaryMismatch, fmt.Sprintf("file %s: summary num_branches (%d) doesn't match branch count (%d)", filename, fileData.Summary.NumBranches, file.TotalBranches))
+	}
+}
+
+// parseRegions adds the functions and classes of a file, in line order. A
+// region starts at its start_line, or at its first statement in reports
+// written before coverage.py recorded it.
+func (p *PyCoverJSONParser) parseRegions(filename string, fileData FileCoverageJSON, file *models.FileCoverage) {
+	for _, name := range sortedKeys(fileData.Functions) {
+		region := fileData.Functions[name]
+		line, ok := pyCoverRegionLine(region)
+		if name == "" || !ok {
+			continue
+		}
+		executionCount := 0
+		if len(region.ExecutedLines) > 0 {
+			executionCount = 1
+		}
+		// A function was run by the tests that ran any of its lines
+		var tests []string
+		for _, lineNum := range region.ExecutedLines {
+			tests = appendMissing(tests, file.Lines[lineNum].Tests...)
+		}
+		file.Functions = append(file.Functions, models.FunctionCoverage{
+			Name:              name,
+			LineNumber:        line,
+			ExecutionCount:    executionCount,
+			TotalStatements:   region.Summary.NumStatements,
+			CoveredStatements: region.Summary.CoveredLines,
+			CoveragePct:       pyCoverPercent(region.Summary),
+			Tests:             tests,
+		})
+	}
+	sort.SliceStable(file.Functions, func(i, j int) bool {
+		return file.Functions[i].LineNumber < file.Functions[j].LineNumber
+	})
+
+	for _, name := range sortedKeys(fileData.Classes) {
+		region := fileData.Classes[name]
+		line, ok := pyCoverRegionLine(region)
+		if name == "" || !ok {
+			continue
+		}
+		file.Classes = append(file.Classes, models.ClassCoverage{
+			Name:              name,
+			LineNumber:        line,
+			TotalStatements:   region.Summary.NumStatements,
+			CoveredStatements: region.Summary.CoveredLines,
+			CoveragePct:       pyCoverPercent(region.Summary),
+		})
+	}
+	sort.SliceStable(file.Classes, func(i, j int) bool {
+		return file.Classes[i].LineNumber < file.Classes[j].LineNumber
+	})
+}
+
+// pyCoverRegionLine returns the line a function or class starts on, false if
+// it has no statements, e.g. because all of them are excluded
+func pyCoverRegionLine(region RegionCoverageJSON) (int, bool) {
+	if region.StartLine > 0 {
+		return region.StartLine, true
+	}
+	line := 0
+	for _, lines := range [][]int{region.ExecutedLines, region.MissingLines} {
+		for _, lineNum := range lines {
+			if line == 0 || lineNum < line {
+				line = lineNum
+			}
+		}
+	}
+	return line, line > 0
+}
+
+// pyCoverTests returns the tests named by the contexts of a line. The empty
+// context is code run outside of any test, such as imports at collection
+// time. pytest-cov appends the phase of the test, "|setup", "|run" or
+// "|teardown", which is dropped so every test is listed once.
+func pyCoverTests(contexts []string) []string {
+	var tests []string
+	for _, context := range contexts {
+		for _, phase := range []string{"|setup", "|run", "|teardown"} {
+			context = strings.TrimSuffix(context, phase)
+		}
+		if context != "" {
+			tests = appendMissing(tests, context)
+		}
+	}
+	return tests
+}
+
+// appendMissing appends the values not yet in list, keeping their order
+func appendMissing(list []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// pyCoverPercent returns the statement coverage of a summary. coverage.py's
+// percent_covered counts branches as well when they were measured.
+func pyCoverPercent(summary SummaryJSON) float64 {
+	if summary.NumStatements == 0 {
+		return 0
+	}
+	return float64(summary.CoveredLines) / float64(summary.NumStatements) * 100
+}
+
+// Name returns the canonical format name
+func (p *PyCoverJSONParser) Name() string {
+	return "pyjson"
+}
+
+// Description returns a human readable format name
+func (p *PyCoverJSONParser) Description() string {
+	return "Python JSON Coverage"
+}
+
+// Aliases returns no aliases, the format is only known as "pyjson"
+func (p *PyCoverJSONParser) Aliases() []string {
 	return nil
 }
 
-// addWarning adds a warning message to the parser
-func (p *PyCoverJSONParser) addWarning(message string) {
-	p.warnings = append(p.warnings, message)
+// FilePatterns returns the file names coverage.py JSON reports are usually written to
+func (p *PyCoverJSONParser) FilePatterns() []string {
+	return []string{"*coverage*.json"}
+}
+
+// Detect reports whether the header contains the "meta" or "executed_lines"
+// keys. A "files" key alone is no sign, gcovr reports have one as well.
+func (p *PyCoverJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// Sniff is confident when the document has coverage.py's meta object, or
+// file entries listing their executed lines
+func (p *PyCoverJSONParser) Sniff(header []byte) Confidence {
+	doc := sniffJSON(header)
+	if doc.has("meta", "format") || doc.has("meta", "version") || doc.has("files", "*", "executed_lines") {
+		return ConfidenceHigh
+	}
+	if doc.has("meta") {
+		return ConfidenceLow
+	}
+	return NoMatch
+}
+
+// addWarning adds a warning about an element of the report to the parser
+func (p *PyCoverJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *PyCoverJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
 }
 
 // GetWarnings returns all warnings collected during parsing
 func (p *PyCoverJSONParser) GetWarnings() []string {
-	return p.warnings
+	return diagnosticStrings(p.diagnostics)
 }
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"os"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
 )
 
 func TestPyCoverJSONParser_Parse_ValidFile(t *testing.T) {
@@ -207,3 +211,115 @@ func TestPyCoverJSONParser_GetWarnings(t *testing.T) {
 		t.Logf("Got expected warnings: %v", warnings)
 	}
 }
+
+func TestPyCoverJSONParser_Parse_CoveragePy7(t *testing.T) {
+	file, err := os.Open("../../testdata/coverage-py7.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewPyCoverJSONParser()
+	report, err := parser.Parse(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 0 {
+		t.Errorf("Expected no warnings, got: %v", parser.GetWarnings())
+	}
+
+	cart := report.Files["shop/cart.py"]
+	if cart == nil {
+		t.Fatal("Expected file 'shop/cart.py' to exist")
+	}
+	if cart.TotalLines != 10 || cart.CoveredLines != 9 {
+		t.Errorf("Expected 9 of 10 lines covered, got %d of %d", cart.CoveredLines, cart.TotalLines)
+	}
+
+	// Arcs become branches of the line they start on
+	if cart.TotalBranches != 4 || cart.CoveredBranches != 2 || cart.BranchPct != 50 {
+		t.Errorf("Expected 2 of 4 branches covered, got %d of %d", cart.CoveredBranches, cart.TotalBranches)
+	}
+	if b := cart.Branches[0]; b.LineNumber != 9 || b.BranchID != "10" || b.TakenCount != 1 {
+		t.Errorf("Expected arc 9->10 to be taken, got: %+v", b)
+	}
+
+	if !reflect.DeepEqual(cart.ExcludedLines, []int{14, 15}) {
+		t.Errorf("Expected excluded lines 14 and 15, got: %v", cart.ExcludedLines)
+	}
+	if _, ok := cart.Lines[14]; ok {
+		t.Error("Expected excluded line 14 not to be measured")
+	}
+
+	// The test-to-line map, without pytest-cov's phase suffix and the empty
+	// context of code run at import time
+	byTest := cart.LinesByTest()
+	if !reflect.DeepEqual(byTest["tests/test_cart.py::test_empty"], []int{5}) {
+		t.Errorf("Expected test_empty to run line 5, got: %v", byTest)
+	}
+	if !reflect.DeepEqual(byTest["tests/test_cart.py::test_total"], []int{5, 8, 9, 10, 12}) {
+		t.Errorf("Expected test_total to run lines 5 to 12, got: %v", byTest)
+	}
+	if len(byTest) != 2 {
+		t.Errorf("Expected 2 tests, got: %v", byTest)
+	}
+
+	// Functions without statements and the module level region are left out,
+	// functions were run by the tests that ran their lines
+	expectedFunctions := []models.FunctionCoverage{
+		{Name: "Cart.__init__", LineNumber: 4, ExecutionCount: 1, TotalStatements: 1, CoveredStatements: 1, CoveragePct: 100,
+			Tests: []string{"tests/test_cart.py::test_total", "tests/test_cart.py::test_empty"}},
+		{Name: "Cart.total", LineNumber: 7, ExecutionCount: 1, TotalStatements: 5, CoveredStatements: 4, CoveragePct: 80,
+			Tests: []string{"tests/test_cart.py::test_total"}},
+	}
+	if !reflect.DeepEqual(cart.Functions, expectedFunctions) {
+		t.Errorf("Expected functions %+v, got: %+v", expectedFunctions, cart.Functions)
+	}
+	if len(cart.Classes) != 1 || cart.Classes[0].Name != "Cart" || cart.Classes[0].LineNumber != 3 || cart.Classes[0].CoveredStatements != 7 {
+		t.Errorf("Expected class Cart, got: %+v", cart.Classes)
+	}
+}
+
+func TestPyCoverJSONParser_Parse_RegionWithoutStartLine(t *testing.T) {
+	// coverage.py before 7.6 wrote no start_line, the first statement stands in
+	input := `{"files": {"a.py": {"executed_lines": [1, 3], "missing_lines": [4],
+		"functions": {"f": {"executed_lines": [3], "missing_lines": [4], "summary": {"covered_lines": 1, "num_statements": 2}}}}}}`
+
+	report, err := NewPyCoverJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	functions := report.Files["a.py"].Functions
+	if len(functions) != 1 || functions[0].LineNumber != 3 || functions[0].CoveragePct != 50 {
+		t.Errorf("Expected function f on line 3, got: %+v", functions)
+	}
+}
+
+func TestPyCoverJSONParser_Parse_ContextPhases(t *testing.T) {
+	input := `{"files": {"a.py": {"executed_lines": [1], "missing_lines": [],
+		"contexts": {"1": ["", "test_a|setup", "test_a|run", "test_b|teardown"]}}}}`
+
+	report, err := NewPyCoverJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if tests := report.Files["a.py"].Lines[1].Tests; !reflect.DeepEqual(tests, []string{"test_a", "test_b"}) {
+		t.Errorf("Expected line 1 to be run by test_a and test_b, got: %v", tests)
+	}
+}
+
+func TestPyCoverJSONParser_Parse_ContextsOfMissingLine(t *testing.T) {
+	input := `{"files": {"a.py": {"executed_lines": [1], "missing_lines": [2], "contexts": {"1": ["test_a"], "2": ["test_b"]}}}}`
+
+	parser := NewPyCoverJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 1 {
+		t.Errorf("Expected 1 warning, got: %v", parser.GetWarnings())
+	}
+	if tests := report.Files["a.py"].Lines[1].Tests; !reflect.DeepEqual(tests, []string{"test_a"}) {
+		t.Errorf("Expected line 1 to be run by test_a, got: %v", tests)
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// PyCoverJSONWriter writes coverage reports in coverage.py's JSON format
+type PyCoverJSONWriter struct{}
+
+// NewPyCoverJSONWriter creates a new Python JSON coverage writer instance
+func NewPyCoverJSONWriter() *PyCoverJSONWriter {
+	return &PyCoverJSONWriter{}
+}
+
+// Name returns the canonical format name
+func (w *PyCoverJSONWriter) Name() string {
+	return "pyjson"
+}
+
+// Write writes the report as coverage.py JSON. The format only knows whether a
+// line ran, hit counts are reduced to executed and missing lines.
+func (w *PyCoverJSONWriter) Write(writer io.Writer, report *models.CoverageReport) error {
+	totalLines, coveredLines, overallPct := report.CalculateOverallCoverage()
+
+	coverageJSON := CoverageJSON{
+		Meta: &MetaJSON{
+			Format:    2,
+			Version:   "covpeek",
+			Timestamp: time.Now().Format("2006-01-02T15:04:05.000000"),
+		},
+		Files:  make(map[string]FileCoverageJSON, len(report.Files)),
+		Totals: pyCoverSummary(coveredLines, totalLines, overallPct),
+	}
+
+	for _, name := range sortedFileNames(report) {
+		file := report.Files[name]
+		fileJSON := FileCoverageJSON{
+			ExecutedLines: make([]int, 0),
+			MissingLines:  make([]int, 0),
+			ExcludedLines: append(make([]int, 0, len(file.ExcludedLines)), file.ExcludedLines...),
+		}
+		for _, lineNum := range sortedLineNumbers(file) {
+			if file.Lines[lineNum].ExecutionCount > 0 {
+				fileJSON.ExecutedLines = append(fileJSON.ExecutedLines, lineNum)
+			} else {
+				fileJSON.MissingLines = append(fileJSON.MissingLines, lineNum)
+			}
+		}
+		fileJSON.Summary = *pyCoverSummary(file.CoveredLines, file.TotalLines, file.CoveragePct)
+		coverageJSON.Files[file.FileName] = fileJSON
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(coverageJSON); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// pyCoverSummary builds a summary block, coverage.py displays percentages rounded
+func pyCoverSummary(covered, total int, pct float64) *SummaryJSON {
+	if total == 0 {
+		pct = 100
+	}
+	return &SummaryJSON{
+		CoveredLines:          covered,
+		NumStatements:         total,
+		PercentCovered:        pct,
+		PercentCoveredDisplay: fmt.Sprintf("%.0f", pct),
+	}
+}
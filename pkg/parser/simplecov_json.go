@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// SimpleCovJSONParser parses the .resultset.json file SimpleCov writes for
+// Ruby projects. The file holds one result set per command name, such as
+// "RSpec" or "Minitest"; all of them are merged into one report.
+type SimpleCovJSONParser struct {
+	diagnostics []Diagnostic
+}
+
+// NewSimpleCovJSONParser creates a new SimpleCov result set parser instance
+func NewSimpleCovJSONParser() *SimpleCovJSONParser {
+	return &SimpleCovJSONParser{
+		diagnostics: make([]Diagnostic, 0),
+	}
+}
+
+// SimpleCovFile represents the coverage of a file in a result set. SimpleCov
+// before 0.18 wrote the lines array alone instead of an object.
+type SimpleCovFile struct {
+	Lines SimpleCovLines `json:"lines"`
+	// Branches maps conditions to their outcomes and hit counts, both keyed
+	// by Ruby arrays such as "[:if, 0, 5, 4, 9, 7]"
+	Branches map[string]map[string]int `json:"branches"`
+}
+
+// UnmarshalJSON decodes a file entry in either format
+func (f *SimpleCovFile) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return json.Unmarshal(trimmed, &f.Lines)
+	}
+	type simpleCovFile SimpleCovFile
+	return json.Unmarshal(data, (*simpleCovFile)(f))
+}
+
+// SimpleCovLines holds the hit count of every line of a file, starting at
+// line 1. Lines that are not relevant, such as comments, have no count.
+type SimpleCovLines []*int
+
+// UnmarshalJSON decodes a lines array. Values other than counts are treated
+// like null.
+func (l *SimpleCovLines) UnmarshalJSON(data []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = make(SimpleCovLines, len(values))
+	for i, value := range values {
+		var count int
+		if json.Unmarshal(value, &count) == nil && string(value) != "null" {
+			(*l)[i] = &count
+		}
+	}
+	return nil
+}
+
+// Parse reads and parses a SimpleCov .resultset.json file. Result sets and
+// their files are decoded one at a time.
+func (p *SimpleCovJSONParser) Parse(reader io.Reader) (*models.CoverageReport, error) {
+	p.diagnostics = nil
+	report := models.NewCoverageReport()
+
+	var commandNames []string
+	decoder := json.NewDecoder(reader)
+	err := forEachJSONKey(decoder, func(command string) error {
+		commandNames = append(commandNames, command)
+		return forEachJSONKey(decoder, func(key string) error {
+			if key != "coverage" {
+				return skipJSONValue(decoder)
+			}
+			return forEachJSONKey(decoder, func(filename string) error {
+				var fileData SimpleCovFile
+				if err := decoder.Decode(&fileData); err != nil {
+					return err
+				}
+				p.parseFile(command, filename, fileData, report)
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Result sets of several test suites are merged, the report keeps their names
+	report.TestName = strings.Join(commandNames, ", ")
+
+	for _, file := range report.Files {
+		file.CalculateCoverage()
+	}
+
+	return report, nil
+}
+
+// parseFile converts the lines and branches of a file. A file covered by
+// several result sets is merged, adding up the hit counts.
+func (p *SimpleCovJSONParser) parseFile(command, filename string, fileData SimpleCovFile, report *models.CoverageReport) {
+	file := &models.FileCoverage{
+		FileName:  filename,
+		Functions: make([]models.FunctionCoverage, 0),
+		Lines:     make(map[int]models.LineCoverage),
+		Branches:  make([]models.BranchCoverage, 0),
+	}
+
+	for i, count := range fileData.Lines {
+		if count == nil {
+			continue
+		}
+		if *count < 0 {
+			p.addWarning("lines", CodeInvalidValue, fmt.Sprintf("%s: file %s: negative count %d on line %d", command, filename, *count, i+1))
+			continue
+		}
+		file.Lines[i+1] = models.LineCoverage{
+			LineNumber:     i + 1,
+			ExecutionCount: *count,
+		}
+	}
+
+	// Conditions become blocks, their outcomes branches
+	for _, condition := range sortedSimpleCovKeys(fileData.Branches) {
+		_, block, line, ok := parseSimpleCovBranchKey(condition)
+		if !ok {
+			p.addWarning("branches", CodeInvalidRecord, fmt.Sprintf("%s: file %s: invalid condition %s", command, filename, condition))
+			continue
+		}
+		for _, outcome := range sortedSimpleCovKeys(fileData.Branches[condition]) {
+			outcomeType, id, _, ok := parseSimpleCovBranchKey(outcome)
+			if !ok {
+				p.addWarning("branches", CodeInvalidRecord, fmt.Sprintf("%s: file %s: invalid branch %s", command, filename, outcome))
+				continue
+			}
+			file.Branches = append(file.Branches, models.BranchCoverage{
+				LineNumber:  line,
+				BlockNumber: block,
+				BranchID:    outcomeType + ":" + strconv.Itoa(id),
+				TakenCount:  fileData.Branches[condition][outcome],
+			})
+		}
+	}
+
+	file.CountLines()
+	file.CountBranches()
+
+	if existing := report.GetFile(filename); existing != nil {
+		existing.Merge(file)
+		return
+	}
+	report.AddFile(file)
+}
+
+// parseSimpleCovBranchKey parses a condition or branch key such as
+// "[:if, 0, 5, 4, 9, 7]": the type, id, start line, start column, end line
+// and end column
+func parseSimpleCovBranchKey(key string) (kind string, id int, line int, ok bool) {
+	if !strings.HasPrefix(key, "[") || !strings.HasSuffix(key, "]") {
+		return "", 0, 0, false
+	}
+	fields := strings.Split(key[1:len(key)-1], ",")
+	if len(fields) < 3 {
+		return "", 0, 0, false
+	}
+	kind = strings.TrimPrefix(strings.TrimSpace(fields[0]), ":")
+	id, errID := strconv.Atoi(strings.TrimSpace(fields[1]))
+	line, errLine := strconv.Atoi(strings.TrimSpace(fields[2]))
+	if kind == "" || errID != nil || errLine != nil {
+		return "", 0, 0, false
+	}
+	return kind, id, line, true
+}
+
+// sortedSimpleCovKeys returns the keys of a branch map ordered by their ids
+func sortedSimpleCovKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		_, a, _, okA := parseSimpleCovBranchKey(keys[i])
+		_, b, _, okB := parseSimpleCovBranchKey(keys[j])
+		if !okA || !okB || a == b {
+			return keys[i] < keys[j]
+		}
+		return a < b
+	})
+	return keys
+}
+
+// Name returns the canonical format name
+func (p *SimpleCovJSONParser) Name() string {
+	return "simplecov"
+}
+
+// Description returns a human readable format name
+func (p *SimpleCovJSONParser) Description() string {
+	return "SimpleCov JSON Coverage"
+}
+
+// Aliases returns "ruby", the language SimpleCov covers
+func (p *SimpleCovJSONParser) Aliases() []string {
+	return []string{"ruby"}
+}
+
+// FilePatterns returns the file name SimpleCov writes its result sets to
+func (p *SimpleCovJSONParser) FilePatterns() []string {
+	return []string{".resultset.json"}
+}
+
+// Sniff is confident when a command name at the top of the document holds
+// the lines of files in its coverage. The bare line arrays SimpleCov wrote
+// before 0.18 only match next to the timestamp of their result set, a
+// coverage key alone is common in other JSON, e.g. the scripts of a
+// package.json.
+func (p *SimpleCovJSONParser) Sniff(header []byte) Confidence {
+	doc := sniffJSON(header)
+	if doc.has("*", "coverage", "*", "lines") {
+		return ConfidenceHigh
+	}
+	for _, file := range doc.find("*", "coverage", "*") {
+		if doc.has(file[0], "timestamp") {
+			return ConfidenceMedium
+		}
+	}
+	return NoMatch
+}
+
+// Detect reports whether the document holds result sets
+func (p *SimpleCovJSONParser) Detect(header []byte) bool {
+	return p.Sniff(header) > NoMatch
+}
+
+// addWarning adds a warning about an element of the result set to the parser
+func (p *SimpleCovJSONParser) addWarning(record, code, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Record:   record,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns all diagnostics collected during parsing
+func (p *SimpleCovJSONParser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// GetWarnings returns all warnings collected during parsing
+func (p *SimpleCovJSONParser) GetWarnings() []string {
+	return diagnosticStrings(p.diagnostics)
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSimpleCovJSONParser_Parse_ValidFile(t *testing.T) {
+	file, err := os.Open("../../testdata/simplecov/.resultset.json")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := NewSimpleCovJSONParser()
+	report, err := parser.Parse(file)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.TestName != "RSpec, Minitest" {
+		t.Errorf("Expected test name 'RSpec, Minitest', got: %s", report.TestName)
+	}
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(report.Files))
+	}
+
+	// Both result sets cover user.rb, their counts are merged
+	user := report.Files["/app/app/models/user.rb"]
+	if user == nil {
+		t.Fatalf("Expected file '/app/app/models/user.rb' not found")
+	}
+	if user.TotalLines != 5 || user.CoveredLines != 5 || user.Lines[3].ExecutionCount != 5 {
+		t.Errorf("Expected 5 of 5 lines covered, got: %d of %d", user.CoveredLines, user.TotalLines)
+	}
+	if user.TotalBranches != 2 || user.CoveredBranches != 2 {
+		t.Errorf("Expected 2 of 2 branches covered, got: %d of %d", user.CoveredBranches, user.TotalBranches)
+	}
+	for _, branch := range user.Branches {
+		if branch.LineNumber != 3 || branch.BlockNumber != 0 {
+			t.Errorf("Expected branches of the condition on line 3, got: %+v", branch)
+		}
+	}
+
+	// Null lines are not relevant
+	tax := report.Files["/app/lib/tax.rb"]
+	if tax == nil {
+		t.Fatalf("Expected file '/app/lib/tax.rb' not found")
+	}
+	if tax.TotalLines != 3 || tax.CoveredLines != 2 {
+		t.Errorf("Expected 2 of 3 lines covered, got: %d of %d", tax.CoveredLines, tax.TotalLines)
+	}
+	if _, ok := tax.Lines[2]; ok {
+		t.Error("Expected null line 2 to be left out")
+	}
+}
+
+func TestSimpleCovJSONParser_Parse_LegacyLines(t *testing.T) {
+	// SimpleCov before 0.18 wrote the lines array without an object around it
+	input := `{"RSpec": {"coverage": {"lib/a.rb": [1, null, 0, "ignored"]}, "timestamp": 1}}`
+
+	report, err := NewSimpleCovJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file := report.Files["lib/a.rb"]
+	if file == nil {
+		t.Fatalf("Expected file 'lib/a.rb' not found")
+	}
+	if file.TotalLines != 2 || file.CoveredLines != 1 {
+		t.Errorf("Expected 1 of 2 lines covered, got: %d of %d", file.CoveredLines, file.TotalLines)
+	}
+}
+
+func TestSimpleCovJSONParser_Sniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Confidence
+	}{
+		{"result set", `{"RSpec": {"coverage": {"lib/a.rb": {"lines": [1, null]}}, "timestamp": 1}}`, ConfidenceHigh},
+		{"legacy lines", `{"RSpec": {"coverage": {"lib/a.rb": [1, null]}, "timestamp": 1}}`, ConfidenceMedium},
+		{"package.json", `{"name": "app", "scripts": {"test": "jest", "coverage": "jest --coverage"}, "version": "1.0.0"}`, NoMatch},
+		{"nested coverage", `{"jest": {"coverage": {"threshold": 80}}}`, NoMatch},
+		{"timestamp elsewhere", `{"jest": {"coverage": {"threshold": 80}}, "build": {"timestamp": 1}}`, NoMatch},
+	}
+	for _, tt := range tests {
+		if confidence := NewSimpleCovJSONParser().Sniff([]byte(tt.input)); confidence != tt.expected {
+			t.Errorf("%s: expected confidence %d, got: %d", tt.name, tt.expected, confidence)
+		}
+	}
+}
+
+func TestSimpleCovJSONParser_Parse_InvalidBranch(t *testing.T) {
+	input := `{"RSpec": {"coverage": {"lib/a.rb": {"lines": [1, -1], "branches": {"if": {"[:then, 1, 1, 0, 1, 5]": 1}}}}}}`
+
+	parser := NewSimpleCovJSONParser()
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(parser.GetWarnings()) != 2 {
+		t.Errorf("Expected 2 warnings, got: %v", parser.GetWarnings())
+	}
+	if file := report.Files["lib/a.rb"]; file == nil || file.TotalLines != 1 || file.TotalBranches != 0 {
+		t.Errorf("Expected the valid line to be kept, got: %+v", file)
+	}
+}
+
+func TestSimpleCovJSONParser_Parse_InvalidJSON(t *testing.T) {
+	if _, err := NewSimpleCovJSONParser().Parse(strings.NewReader(`{"RSpec": {"coverage": {`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Confidence rates how well the beginning of a file matches a format
+type Confidence int
+
+const (
+	// NoMatch means the header does not look like the format
+	NoMatch Confidence = 0
+	// ConfidenceLow is a loose hint, such as a record other formats may contain as well
+	ConfidenceLow Confidence = 25
+	// ConfidenceMedium is structure typical of the format. Formats that only
+	// implement Detect match with this confidence.
+	ConfidenceMedium Confidence = 50
+	// ConfidenceHigh is structure specific to the format, such as its XML root
+	// element or JSON keys
+	ConfidenceHigh Confidence = 75
+	// ConfidenceCertain is a signature only the format writes, such as a magic
+	// number or type marker
+	ConfidenceCertain Confidence = 100
+)
+
+// Sniffer is implemented by formats that rate how confidently they recognize
+// the beginning of a coverage file. All built-in formats implement it.
+type Sniffer interface {
+	// Sniff returns the confidence that the header belongs to this format
+	Sniff(header []byte) Confidence
+}
+
+// sniff returns the confidence of a format for a header
+func sniff(p Parser, header []byte) Confidence {
+	if s, ok := p.(Sniffer); ok {
+		return s.Sniff(header)
+	}
+	if p.Detect(header) {
+		return ConfidenceMedium
+	}
+	return NoMatch
+}
+
+// utf8BOM is skipped at the start of a header
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+const (
+	// maxJSONSniffDepth bounds how deep jsonHeader records key paths, Coverlet
+	// nests the lines of a method five keys deep
+	maxJSONSniffDepth = 5
+	// maxJSONSniffKeys bounds how many key paths jsonHeader records. The keys
+	// telling formats apart come first, the rest is the bulk of the data.
+	maxJSONSniffKeys = 1024
+)
+
+// errSniffBudget stops walking a JSON document once enough keys were seen
+var errSniffBudget = errors.New("sniff budget exhausted")
+
+// jsonHeader holds the key paths found at the start of a JSON document. Array
+// elements appear as "[]", so an llvm-cov export has the path data/[]/files.
+type jsonHeader [][]string
+
+// sniffJSON collects the key paths of the JSON document at the start of the
+// header. A header cut off inside the document yields the paths read so far,
+// one that is not JSON yields none.
+func sniffJSON(header []byte) jsonHeader {
+	var paths jsonHeader
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(header, utf8BOM)))
+	_ = walkJSONPaths(decoder, nil, func(path []string) error {
+		paths = append(paths, path)
+		if len(paths) == maxJSONSniffKeys {
+			return errSniffBudget
+		}
+		return nil
+	})
+	return paths
+}
+
+// walkJSONPaths walks the value at the decoder's position and calls visit
+// for the path of every key below it, until visit returns an error
+func walkJSONPaths(decoder *json.Decoder, path []string, visit func(path []string) error) error {
+	if len(path) >= maxJSONSniffDepth {
+		return skipJSONValue(decoder)
+	}
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			keyPath := append(path[:len(path):len(path)], key)
+			if err := visit(keyPath); err != nil {
+				return err
+			}
+			if err := walkJSONPaths(decoder, keyPath, visit); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		elementPath := append(path[:len(path):len(path)], "[]")
+		for decoder.More() {
+			if err := walkJSONPaths(decoder, elementPath, visit); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	}
+	return err
+}
+
+// has reports whether a key path matches the pattern, in which "*" stands for any key
+func (h jsonHeader) has(pattern ...string) bool {
+	return len(h.find(pattern...)) > 0
+}
+
+// find returns the key paths matching the pattern, in which "*" stands for any key
+func (h jsonHeader) find(pattern ...string) [][]string {
+	var found [][]string
+	for _, path := range h {
+		if len(path) != len(pattern) {
+			continue
+		}
+		matched := true
+		for i, key := range pattern {
+			if key != "*" && key != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// maxXMLSniffChildren bounds how many child elements of the root xmlHeader records
+const maxXMLSniffChildren = 8
+
+// xmlHeader describes the start of an XML document: its DOCTYPE, root element
+// and the names of the first elements below the root
+type xmlHeader struct {
+	Doctype  string
+	Root     xml.StartElement
+	Children []string
+}
+
+// sniffXML reads the root element of the XML document at the start of the
+// header, skipping the declaration, comments and processing instructions in
+// front of it. It returns nil if the header is not XML.
+func sniffXML(header []byte) *xmlHeader {
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(header, utf8BOM)))
+	// Only the markup matters, not how the text is encoded
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var result *xmlHeader
+	doctype := ""
+	depth := 0
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return result
+		}
+		switch t := token.(type) {
+		case xml.Directive:
+			if bytes.HasPrefix(t, []byte("DOCTYPE")) {
+				doctype = strings.TrimSpace(string(t[len("DOCTYPE"):]))
+			}
+		case xml.CharData:
+			if result == nil && len(bytes.TrimSpace(t)) > 0 {
+				return nil
+			}
+		case xml.StartElement:
+			if result == nil {
+				result = &xmlHeader{Doctype: doctype, Root: t.Copy()}
+			} else if depth == 1 {
+				result.Children = append(result.Children, t.Name.Local)
+				if len(result.Children) == maxXMLSniffChildren {
+					return result
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return result
+			}
+		}
+	}
+}
+
+// rootIs reports whether the document's root element has the given name
+func (h *xmlHeader) rootIs(name string) bool {
+	return h != nil && h.Root.Name.Local == name
+}
+
+// hasAttr reports whether the root element has the given attribute
+func (h *xmlHeader) hasAttr(name string) bool {
+	for _, attr := range h.Root.Attr {
+		if attr.Name.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasChild reports whether one of the recorded children of the root has the given name
+func (h *xmlHeader) hasChild(name string) bool {
+	for _, child := range h.Children {
+		if child == name {
+			return true
+		}
+	}
+	return false
+}
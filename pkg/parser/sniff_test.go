@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSniffJSON_Truncated(t *testing.T) {
+	// The header ends in the middle of the document
+	doc := sniffJSON([]byte(`{"meta": {"version": "7.4"}, "files": {"a.py": {"executed_lines": [1, 2`))
+
+	expected := jsonHeader{
+		{"meta"},
+		{"meta", "version"},
+		{"files"},
+		{"files", "a.py"},
+		{"files", "a.py", "executed_lines"},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected paths %v, got: %v", expected, doc)
+	}
+	if !doc.has("files", "*", "executed_lines") {
+		t.Error("Expected wildcard pattern to match")
+	}
+	if doc.has("*", "executed_lines") {
+		t.Error("Expected pattern of another length not to match")
+	}
+}
+
+func TestSniffJSON_Arrays(t *testing.T) {
+	doc := sniffJSON([]byte("\xef\xbb\xbf" + `{"data": [{"files": []}]}`))
+	if !doc.has("data", "[]", "files") {
+		t.Errorf("Expected path data/[]/files, got: %v", doc)
+	}
+}
+
+func TestSniffJSON_NotJSON(t *testing.T) {
+	for _, header := range []string{"", "TN:\nSF:a.c\n", "<coverage/>"} {
+		if doc := sniffJSON([]byte(header)); len(doc) != 0 {
+			t.Errorf("Expected no paths for %q, got: %v", header, doc)
+		}
+	}
+}
+
+func TestSniffXML_LicenseComment(t *testing.T) {
+	// Generators may put a license of any length in front of the root
+	header := "\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<!--\n" +
+		strings.Repeat("  Licensed under the Apache License, Version 2.0\n", 40) +
+		"-->\n<!DOCTYPE coverage SYSTEM \"coverage-04.dtd\">\n<coverage line-rate=\"0.5\">\n<sources>\n<source>/src</source>\n</sources>\n<packages>\n<package name=\"a\">"
+
+	doc := sniffXML([]byte(header))
+	if !doc.rootIs("coverage") {
+		t.Fatalf("Expected <coverage> root, got: %+v", doc)
+	}
+	if doc.Doctype != `coverage SYSTEM "coverage-04.dtd"` {
+		t.Errorf("Expected DOCTYPE to be recorded, got: %q", doc.Doctype)
+	}
+	if !doc.hasAttr("line-rate") || doc.hasAttr("generated") {
+		t.Errorf("Expected the root's attributes, got: %+v", doc.Root.Attr)
+	}
+	if !reflect.DeepEqual(doc.Children, []string{"sources", "packages"}) {
+		t.Errorf("Expected the root's children, got: %v", doc.Children)
+	}
+}
+
+func TestSniffXML_NotXML(t *testing.T) {
+	for _, header := range []string{"", "mode: set\n", `{"a": "<b>"}`, "SF:<a>\n"} {
+		if doc := sniffXML([]byte(header)); doc != nil {
+			t.Errorf("Expected nil for %q, got: %+v", header, doc)
+		}
+		if sniffXML([]byte(header)).rootIs("a") {
+			t.Errorf("Expected no root for %q", header)
+		}
+	}
+}
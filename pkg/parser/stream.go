@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// The JSON and XML parsers decode one file or class entry at a time instead of
+// unmarshalling the whole report, so memory stays bounded by the largest entry.
+
+// readJSONDelim consumes the next token and checks that it is the given delimiter
+func readJSONDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got: %v", want, token)
+	}
+	return nil
+}
+
+// forEachJSONKey walks the JSON object at the decoder's position and calls fn
+// for each key. fn must consume the key's value from the decoder.
+func forEachJSONKey(decoder *json.Decoder, fn func(key string) error) error {
+	if err := readJSONDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got: %v", token)
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return readJSONDelim(decoder, '}')
+}
+
+// forEachJSONElement walks the JSON array at the decoder's position and calls
+// fn for each element. fn must consume the element from the decoder.
+func forEachJSONElement(decoder *json.Decoder, fn func() error) error {
+	if err := readJSONDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return readJSONDelim(decoder, ']')
+}
+
+// skipJSONValue consumes the next value without keeping it
+func skipJSONValue(decoder *json.Decoder) error {
+	var skipped json.RawMessage
+	return decoder.Decode(&skipped)
+}
+
+// walkXML reads the document's root element, checks its name and then calls
+// fn for every element below it. fn may consume an element with DecodeElement,
+// elements it leaves alone are descended into and end is called when they close.
+func walkXML(decoder *xml.Decoder, root string, fn func(start *xml.StartElement) (bool, error), end func(name string)) (xml.StartElement, error) {
+	var rootElement xml.StartElement
+	for rootElement.Name.Local == "" {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rootElement, fmt.Errorf("no root element")
+		}
+		if err != nil {
+			return rootElement, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			rootElement = start
+		}
+	}
+	if rootElement.Name.Local != root {
+		return rootElement, fmt.Errorf("expected element type <%s> but have <%s>", root, rootElement.Name.Local)
+	}
+
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return rootElement, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			consumed, err := fn(&t)
+			if err != nil {
+				return rootElement, err
+			}
+			if !consumed {
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+			if depth > 0 && end != nil {
+				end(t.Name.Local)
+			}
+		}
+	}
+	return rootElement, nil
+}
+
+// xmlAttr returns the value of an element's attribute, or "" if it is not set
+func xmlAttr(element *xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
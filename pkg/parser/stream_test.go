@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParsers_StreamInSmallReads(t *testing.T) {
+	fixtures := map[string]string{
+		"lcov":      "../../testdata/sample.lcov",
+		"go":        "../../testdata/sample.out",
+		"cobertura": "../../testdata/coverage.xml",
+		"jacoco":    "../../testdata/jacoco.xml",
+		"istanbul":  "../../testdata/coverage-final.json",
+		"pyjson":    "../../testdata/coverage.json",
+	}
+
+	for format, fixture := range fixtures {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", fixture, err)
+		}
+
+		expected, err := Lookup(format).Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", format, err)
+		}
+
+		// Readers handing out one byte at a time must give the same report
+		streamed, err := Lookup(format).Parse(iotest.OneByteReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("%s: expected no error reading byte by byte, got: %v", format, err)
+		}
+
+		if len(streamed.Files) != len(expected.Files) {
+			t.Fatalf("%s: expected %d files, got: %d", format, len(expected.Files), len(streamed.Files))
+		}
+		for name, file := range expected.Files {
+			again := streamed.GetFile(name)
+			if again == nil || again.TotalLines != file.TotalLines || again.CoveredLines != file.CoveredLines {
+				t.Errorf("%s: file %s differs when streamed", format, name)
+			}
+		}
+	}
+}
+
+func TestParsers_StreamErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+		err    string
+	}{
+		{"cobertura", `<report name="x"></report>`, "expected element type <coverage> but have <report>"},
+		{"cobertura", `<coverage><packages><package name="a">`, "unexpected EOF"},
+		{"jacoco", `<coverage></coverage>`, "expected element type <report> but have <coverage>"},
+		{"jacoco", ``, "no root element"},
+		{"istanbul", `[]`, "expected {"},
+		{"istanbul", `{"a.ts": {"path": "a.ts"`, "unexpected EOF"},
+		{"pyjson", `{"files": []}`, "expected {"},
+	}
+
+	for _, test := range tests {
+		_, err := Lookup(test.format).Parse(strings.NewReader(test.input))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s %q: expected error containing %q, got: %v", test.format, test.input, test.err, err)
+		}
+	}
+}
+
+func TestPyCoverJSONParser_SkipsOtherKeys(t *testing.T) {
+	input := `{"meta": {"format": 2, "version": "7.0"}, "files": {"a.py": {"executed_lines": [1], "missing_lines": [2]}}, "totals": {"covered_lines": 1}}`
+
+	report, err := NewPyCoverJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if file := report.GetFile("a.py"); file == nil || file.TotalLines != 2 || file.CoveredLines != 1 {
+		t.Errorf("Expected a.py with 1/2 lines, got: %+v", file)
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// Writer is implemented by every format covpeek can write
+type Writer interface {
+	// Name returns the canonical name of the format, the same name its parser uses
+	Name() string
+	// Write writes a coverage report in the format
+	Write(writer io.Writer, report *models.CoverageReport) error
+}
+
+var (
+	writerRegistryMu sync.RWMutex
+	writerRegistry   []func() Writer
+)
+
+func init() {
+	RegisterWriter(func() Writer { return NewLCOVWriter() })
+	RegisterWriter(func() Writer { return NewCoberturaXMLWriter() })
+	RegisterWriter(func() Writer { return NewGoCoverWriter() })
+	RegisterWriter(func() Writer { return NewPyCoverJSONWriter() })
+}
+
+// RegisterWriter adds an output format. RegisterWriter panics if a writer with
+// the same name is already registered.
+func RegisterWriter(factory func() Writer) {
+	writerRegistryMu.Lock()
+	defer writerRegistryMu.Unlock()
+
+	name := factory().Name()
+	for _, existing := range writerRegistry {
+		if strings.EqualFold(existing().Name(), name) {
+			panic(fmt.Sprintf("parser: writer %q registered twice", name))
+		}
+	}
+	writerRegistry = append(writerRegistry, factory)
+}
+
+// LookupWriter returns a new writer for the format with the given name, or nil
+// if the format cannot be written. Aliases of registered parsers are accepted.
+func LookupWriter(name string) Writer {
+	if p := Lookup(name); p != nil {
+		name = p.Name()
+	}
+
+	writerRegistryMu.RLock()
+	defer writerRegistryMu.RUnlock()
+
+	for _, factory := range writerRegistry {
+		if w := factory(); strings.EqualFold(w.Name(), name) {
+			return w
+		}
+	}
+	return nil
+}
+
+// WriterNames returns the canonical names of all formats that can be written
+func WriterNames() []string {
+	writerRegistryMu.RLock()
+	defer writerRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(writerRegistry))
+	for _, factory := range writerRegistry {
+		names = append(names, factory().Name())
+	}
+	return names
+}
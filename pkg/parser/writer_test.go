@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Chapati-Systems/covpeek/pkg/models"
+)
+
+// createWriterTestReport builds a report with lines, functions and branches in two packages
+func createWriterTestReport() *models.CoverageReport {
+	report := models.NewCoverageReport()
+
+	lib := &models.FileCoverage{
+		FileName: "src/app/lib.py",
+		Functions: []models.FunctionCoverage{
+			{Name: "add", LineNumber: 1, ExecutionCount: 3},
+			{Name: "sub", LineNumber: 4, ExecutionCount: 0},
+		},
+		Lines: map[int]models.LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 3},
+			2: {LineNumber: 2, ExecutionCount: 3},
+			4: {LineNumber: 4, ExecutionCount: 0},
+			5: {LineNumber: 5, ExecutionCount: 0},
+		},
+		Branches: []models.BranchCoverage{
+			{LineNumber: 2, BranchID: "0", TakenCount: 2},
+			{LineNumber: 2, BranchID: "1", TakenCount: 0},
+		},
+	}
+	entry := &models.FileCoverage{
+		FileName: "main.py",
+		Lines: map[int]models.LineCoverage{
+			1: {LineNumber: 1, ExecutionCount: 1},
+		},
+	}
+
+	for _, file := range []*models.FileCoverage{lib, entry} {
+		file.CountLines()
+		file.CountBranches()
+		file.CalculateCoverage()
+		report.AddFile(file)
+	}
+	return report
+}
+
+// roundTrip writes the report with the named writer and parses it back with the format's parser
+func roundTrip(t *testing.T, format string, report *models.CoverageReport) (string, *models.CoverageReport) {
+	t.Helper()
+
+	writer := LookupWriter(format)
+	if writer == nil {
+		t.Fatalf("Expected writer for %s", format)
+	}
+
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, report); err != nil {
+		t.Fatalf("Expected no error writing %s, got: %v", format, err)
+	}
+	output := buf.String()
+
+	parsed, err := Lookup(format).Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Expected no error parsing %s, got: %v\n%s", format, err, output)
+	}
+	return output, parsed
+}
+
+func TestWriters_RoundTripLines(t *testing.T) {
+	report := createWriterTestReport()
+
+	for _, format := range WriterNames() {
+		_, parsed := roundTrip(t, format, report)
+
+		if len(parsed.Files) != 2 {
+			t.Fatalf("%s: expected 2 files, got: %d", format, len(parsed.Files))
+		}
+		for name, file := range report.Files {
+			again := parsed.GetFile(name)
+			if again == nil {
+				t.Fatalf("%s: expected file %s", format, name)
+			}
+			if again.TotalLines != file.TotalLines || again.CoveredLines != file.CoveredLines {
+				t.Errorf("%s: %s expected %d/%d lines, got: %d/%d", format, name, file.CoveredLines, file.TotalLines, again.CoveredLines, again.TotalLines)
+			}
+		}
+	}
+}
+
+func TestCoberturaXMLWriter_Write(t *testing.T) {
+	output, parsed := roundTrip(t, "cobertura", createWriterTestReport())
+
+	if !strings.Contains(output, `<package name="src.app"`) {
+		t.Errorf("Expected package named after the directory, got:\n%s", output)
+	}
+	if !strings.Contains(output, `condition-coverage="50% (1/2)"`) {
+		t.Errorf("Expected condition coverage on branch line, got:\n%s", output)
+	}
+	if !strings.Contains(output, `lines-valid="5"`) {
+		t.Errorf("Expected overall line count, got:\n%s", output)
+	}
+
+	lib := parsed.GetFile("src/app/lib.py")
+	if lib.TotalBranches != 2 || lib.CoveredBranches != 1 {
+		t.Errorf("Expected 1/2 branches, got: %d/%d", lib.CoveredBranches, lib.TotalBranches)
+	}
+	if len(lib.Functions) != 2 || lib.Functions[0].ExecutionCount != 3 || lib.Functions[1].LineNumber != 4 {
+		t.Errorf("Expected functions to survive, got: %+v", lib.Functions)
+	}
+	if lib.Package != "src.app" {
+		t.Errorf("Expected package src.app, got: %s", lib.Package)
+	}
+}
+
+func TestCoberturaXMLWriter_StatementReport(t *testing.T) {
+	// One block of 2 statements over 3 lines, Cobertura counts the lines
+	input := `mode: set
+myproject/file.go:3.20,5.3 2 1
+myproject/file.go:6.2,6.10 1 0
+`
+	report, err := NewGoCoverParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	output, _ := roundTrip(t, "cobertura", report)
+	if strings.Count(output, "<line ") != 4 {
+		t.Errorf("Expected 4 lines, got:\n%s", output)
+	}
+	if !strings.Contains(output, `lines-covered="3" lines-valid="4"`) {
+		t.Errorf("Expected the root to count the 4 lines, got:\n%s", output)
+	}
+	if strings.Count(output, `line-rate="0.75"`) != 3 {
+		t.Errorf("Expected the same line rate at root, package and class, got:\n%s", output)
+	}
+}
+
+func TestCoberturaXMLWriter_KeepsPackage(t *testing.T) {
+	report := models.NewCoverageReport()
+	report.AddFile(&models.FileCoverage{FileName: "com/example/Foo.java", Package: "com.example.core"})
+
+	output, _ := roundTrip(t, "cobertura", report)
+	if !strings.Contains(output, `<package name="com.example.core"`) {
+		t.Errorf("Expected reported package name, got:\n%s", output)
+	}
+}
+
+func TestGoCoverWriter_Write(t *testing.T) {
+	output, parsed := roundTrip(t, "go", createWriterTestReport())
+
+	expected := `mode: count
+main.py:1.1,1.1000 1 1
+src/app/lib.py:1.1,1.1000 1 3
+src/app/lib.py:2.1,2.1000 1 3
+src/app/lib.py:4.1,4.1000 1 0
+src/app/lib.py:5.1,5.1000 1 0
+`
+	if output != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+	if parsed.GetFile("src/app/lib.py").Lines[2].ExecutionCount != 3 {
+		t.Errorf("Expected hit counts to survive")
+	}
+}
+
+func TestPyCoverJSONWriter_Write(t *testing.T) {
+	output, parsed := roundTrip(t, "pyjson", createWriterTestReport())
+
+	if !strings.Contains(output, `"executed_lines": [`) || !strings.Contains(output, `"totals"`) {
+		t.Errorf("Expected coverage.py layout, got:\n%s", output)
+	}
+
+	// Hit counts are reduced to executed or not
+	if parsed.GetFile("src/app/lib.py").Lines[1].ExecutionCount != 1 {
+		t.Errorf("Expected executed line count 1")
+	}
+}
+
+func TestLookupWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"lcov", "lcov"},
+		{"rust", "lcov"},
+		{"cobertura", "cobertura"},
+		{"pyxml", "cobertura"},
+		{"go", "go"},
+		{"pyjson", "pyjson"},
+	}
+
+	for _, test := range tests {
+		w := LookupWriter(test.name)
+		if w == nil {
+			t.Fatalf("Expected writer for %q", test.name)
+		}
+		if w.Name() != test.expected {
+			t.Errorf("LookupWriter(%q): expected %s, got: %s", test.name, test.expected, w.Name())
+		}
+	}
+
+	for _, name := range []string{"jacoco", "istanbul", "unknown"} {
+		if w := LookupWriter(name); w != nil {
+			t.Errorf("Expected no writer for %q, got: %s", name, w.Name())
+		}
+	}
+}
+
+// fakeWriter is a minimal external output format used to exercise RegisterWriter
+type fakeWriter struct{}
+
+func (w *fakeWriter) Name() string { return "fake" }
+func (w *fakeWriter) Write(writer io.Writer, report *models.CoverageReport) error {
+	_, err := io.WriteString(writer, "fake")
+	return err
+}
+
+func TestRegisterWriter(t *testing.T) {
+	writerRegistryMu.RLock()
+	saved := append([]func() Writer(nil), writerRegistry...)
+	writerRegistryMu.RUnlock()
+	defer func() {
+		writerRegistryMu.Lock()
+		writerRegistry = saved
+		writerRegistryMu.Unlock()
+	}()
+
+	RegisterWriter(func() Writer { return &fakeWriter{} })
+	if w := LookupWriter("fake"); w == nil {
+		t.Fatal("Expected registered writer to be found")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic registering a writer twice")
+		}
+	}()
+	RegisterWriter(func() Writer { return &fakeWriter{} })
+}
+
+func TestGoCoverWriter_KeepsBlocks(t *testing.T) {
+	input := `mode: count
+example.com/pkg/a.go:3.20,5.3 3 2
+example.com/pkg/a.go:5.3,6.2 1 0
+`
+	report, err := NewGoCoverParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	output, parsed := roundTrip(t, "go", report)
+	if output != input {
+		t.Errorf("Expected the profile to be written unchanged, got:\n%s", output)
+	}
+	if file := parsed.GetFile("example.com/pkg/a.go"); file.TotalStatements != 4 || file.CoveredStatements != 3 {
+		t.Errorf("Expected 3/4 statements, got: %d/%d", file.CoveredStatements, file.TotalStatements)
+	}
+}
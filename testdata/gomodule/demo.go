@@ -0,0 +1,18 @@
+package demo
+
+func Add(a, b int) int {
+	return a + b
+}
+
+type Checker struct{}
+
+func (c *Checker) Check(v int) bool {
+	if v > 0 {
+		return true
+	}
+	return false
+}
+
+func unused() {
+	println("never")
+}